@@ -0,0 +1,186 @@
+package alchemytest
+
+import (
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/massigerardi/alchemy-api/ethereum"
+	"github.com/massigerardi/alchemy-api/utils"
+	"github.com/ybbus/jsonrpc/v3"
+)
+
+// GenesisTime is the timestamp of block 0. Every further block is BlockTime later.
+const (
+	GenesisTime = 1_700_000_000
+	BlockTime   = 12
+)
+
+type logEntry struct {
+	block   uint64
+	index   uint64
+	address utils.Address
+	topics  []utils.Hash
+	data    string
+}
+
+// MineBlocks appends n empty blocks to the chain and returns the new head.
+func (s *Server) MineBlocks(n int) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := 0; i < n; i++ {
+		s.mineBlock()
+	}
+	return s.head()
+}
+
+// Head returns the number of the latest block.
+func (s *Server) Head() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.head()
+}
+
+// BlockHash returns the hash of a mined block.
+func (s *Server) BlockHash(number uint64) (utils.Hash, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if number > s.head() {
+		return utils.Hash{}, false
+	}
+	return utils.MustParseHash(s.blocks[number].Hash), true
+}
+
+func (s *Server) SetBalance(address utils.Address, balance *big.Int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.balances[address] = new(big.Int).Set(balance)
+}
+
+// SetCode sets the code of a contract as 0x prefixed hex.
+func (s *Server) SetCode(address utils.Address, code string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.codes[address] = code
+}
+
+// SetCallResult sets the result of eth_call to a contract with the given call data.
+// Calls without a result return "0x".
+func (s *Server) SetCallResult(to utils.Address, data string, result string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls[callKey{to: to, data: strings.ToLower(data)}] = result
+}
+
+func (s *Server) SetGasPrice(gasPrice *big.Int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.gasPrice = new(big.Int).Set(gasPrice)
+}
+
+// AddLog adds a log emitted by address to a block, mining blocks up to it if needed.
+func (s *Server) AddLog(block uint64, address utils.Address, data string, topics ...utils.Hash) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for s.head() < block {
+		s.mineBlock()
+	}
+	var index uint64
+	for _, l := range s.logs {
+		if l.block == block {
+			index++
+		}
+	}
+	s.logs = append(s.logs, logEntry{block: block, index: index, address: address, topics: append([]utils.Hash(nil), topics...), data: data})
+}
+
+// SetLatency delays every HTTP request by latency.
+func (s *Server) SetLatency(latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = latency
+}
+
+// FailMethod answers every request for method with an RPC error until ClearFailures is called.
+func (s *Server) FailMethod(method string, code int, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.methodErrors[method] = &jsonrpc.RPCError{Code: code, Message: message}
+}
+
+// FailHTTP answers the next n HTTP requests with status.
+func (s *Server) FailHTTP(status int, n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if n > 0 {
+		s.httpFaults = append(s.httpFaults, httpFault{status: status, remaining: n})
+	}
+}
+
+// RateLimit answers the next n HTTP requests with 429 Too Many Requests, as Alchemy does
+// when the compute units per second are exceeded.
+func (s *Server) RateLimit(n int) {
+	s.FailHTTP(429, n)
+}
+
+// ClearFailures removes the failures set with FailMethod, FailHTTP and RateLimit.
+func (s *Server) ClearFailures() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.methodErrors = make(map[string]*jsonrpc.RPCError)
+	s.httpFaults = nil
+}
+
+// head returns the number of the latest block. s.mu must be held.
+func (s *Server) head() uint64 {
+	return uint64(len(s.blocks) - 1)
+}
+
+// mineBlock appends a block with a deterministic hash. s.mu must be held.
+func (s *Server) mineBlock() {
+	number := uint64(len(s.blocks))
+	parentHash := utils.Hash{}.Hex()
+	if number > 0 {
+		parentHash = s.blocks[number-1].Hash
+	}
+	zero := utils.EncodeQuantity(0)
+	s.blocks = append(s.blocks, &ethereum.Block{
+		Number:           utils.EncodeQuantity(number),
+		Hash:             utils.Keccak256Hash([]byte("alchemytest"), []byte(utils.EncodeQuantity(number))).Hex(),
+		ParentHash:       parentHash,
+		Nonce:            "0x0000000000000000",
+		Sha3Uncles:       utils.Hash{}.Hex(),
+		LogsBloom:        "0x" + strings.Repeat("0", 512),
+		TransactionsRoot: utils.Hash{}.Hex(),
+		StateRoot:        utils.Hash{}.Hex(),
+		ReceiptsRoot:     utils.Hash{}.Hex(),
+		Miner:            utils.Address{}.Hex(),
+		Difficulty:       zero,
+		TotalDifficulty:  zero,
+		ExtraData:        "0x",
+		Size:             zero,
+		GasLimit:         utils.EncodeQuantity(30_000_000),
+		GasUsed:          zero,
+		Timestamp:        utils.EncodeQuantity(GenesisTime + BlockTime*number),
+		BaseFeePerGas:    utils.EncodeQuantity(1_000_000_000),
+		Transactions:     []string{},
+		Uncles:           []string{},
+	})
+}
+
+func (l logEntry) response(block *ethereum.Block) ethereum.LogsResponse {
+	topics := make([]string, len(l.topics))
+	for i, topic := range l.topics {
+		topics[i] = topic.Hex()
+	}
+	return ethereum.LogsResponse{
+		Address:          l.address.Hex(),
+		BlockHash:        block.Hash,
+		BlockNumber:      block.Number,
+		Data:             l.data,
+		LogIndex:         utils.EncodeQuantity(l.index),
+		Topics:           topics,
+		TransactionHash:  utils.Keccak256Hash([]byte(block.Hash), []byte(utils.EncodeQuantity(l.index))).Hex(),
+		TransactionIndex: utils.EncodeQuantity(0),
+	}
+}
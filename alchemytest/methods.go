@@ -0,0 +1,267 @@
+package alchemytest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/massigerardi/alchemy-api/ethereum"
+	"github.com/massigerardi/alchemy-api/utils"
+	"github.com/ybbus/jsonrpc/v3"
+)
+
+// CodeHeaderNotFound is returned for blocks beyond the head.
+const CodeHeaderNotFound = -32000
+
+// ChainID is the chain id reported by eth_chainId, mainnet.
+const ChainID = 1
+
+// handler answers a request. s.mu is held. An error of type *jsonrpc.RPCError
+// is returned with its code, any other error as invalid params.
+type handler func(s *Server, params []json.RawMessage) (interface{}, error)
+
+var handlers = map[string]handler{
+	ethereum.EthBlockNumber: func(s *Server, _ []json.RawMessage) (interface{}, error) {
+		return utils.EncodeQuantity(s.head()), nil
+	},
+	"eth_chainId": func(s *Server, _ []json.RawMessage) (interface{}, error) {
+		return utils.EncodeQuantity(ChainID), nil
+	},
+	ethereum.EthGasPrice: func(s *Server, _ []json.RawMessage) (interface{}, error) {
+		return "0x" + s.gasPrice.Text(16), nil
+	},
+	ethereum.EthGetBalance: func(s *Server, params []json.RawMessage) (interface{}, error) {
+		address, err := s.accountParams(params)
+		if err != nil {
+			return nil, err
+		}
+		if balance, ok := s.balances[address]; ok {
+			return "0x" + balance.Text(16), nil
+		}
+		return "0x0", nil
+	},
+	ethereum.EthGetCode: func(s *Server, params []json.RawMessage) (interface{}, error) {
+		address, err := s.accountParams(params)
+		if err != nil {
+			return nil, err
+		}
+		if code, ok := s.codes[address]; ok {
+			return code, nil
+		}
+		return "0x", nil
+	},
+	ethereum.EthCall: func(s *Server, params []json.RawMessage) (interface{}, error) {
+		if len(params) == 0 {
+			return nil, errors.New("missing call object")
+		}
+		var call struct {
+			To    utils.Address `json:"to"`
+			Data  string        `json:"data"`
+			Input string        `json:"input"`
+		}
+		if err := json.Unmarshal(params[0], &call); err != nil {
+			return nil, err
+		}
+		if _, err := s.blockParam(params, 1); err != nil {
+			return nil, err
+		}
+		data := call.Data
+		if data == "" {
+			data = call.Input
+		}
+		if result, ok := s.calls[callKey{to: call.To, data: strings.ToLower(data)}]; ok {
+			return result, nil
+		}
+		return "0x", nil
+	},
+	ethereum.EthGetBlockByNumber: func(s *Server, params []json.RawMessage) (interface{}, error) {
+		if len(params) == 0 {
+			return nil, errors.New("missing block number")
+		}
+		number, err := s.resolveBlock(params[0])
+		if err != nil {
+			return nil, err
+		}
+		if number > s.head() {
+			return nil, nil
+		}
+		return s.blocks[number], nil
+	},
+	ethereum.EthGetBlockByHash: func(s *Server, params []json.RawMessage) (interface{}, error) {
+		if len(params) == 0 {
+			return nil, errors.New("missing block hash")
+		}
+		var hash utils.Hash
+		if err := json.Unmarshal(params[0], &hash); err != nil {
+			return nil, err
+		}
+		if number, ok := s.blockByHash(hash); ok {
+			return s.blocks[number], nil
+		}
+		return nil, nil
+	},
+	ethereum.EthGetLogs: func(s *Server, params []json.RawMessage) (interface{}, error) {
+		if len(params) == 0 {
+			return nil, errors.New("missing filter")
+		}
+		return s.getLogs(params[0])
+	},
+}
+
+// accountParams decodes the address and optional block of eth_getBalance and eth_getCode.
+func (s *Server) accountParams(params []json.RawMessage) (utils.Address, error) {
+	if len(params) == 0 {
+		return utils.Address{}, errors.New("missing address")
+	}
+	var address utils.Address
+	if err := json.Unmarshal(params[0], &address); err != nil {
+		return utils.Address{}, err
+	}
+	if _, err := s.blockParam(params, 1); err != nil {
+		return utils.Address{}, err
+	}
+	return address, nil
+}
+
+// blockParam resolves the optional block parameter at index, failing for blocks beyond the head.
+func (s *Server) blockParam(params []json.RawMessage, index int) (uint64, error) {
+	if index >= len(params) {
+		return s.head(), nil
+	}
+	number, err := s.resolveBlock(params[index])
+	if err != nil {
+		return 0, err
+	}
+	if number > s.head() {
+		return 0, &jsonrpc.RPCError{Code: CodeHeaderNotFound, Message: "header not found"}
+	}
+	return number, nil
+}
+
+// resolveBlock decodes a block number or tag.
+func (s *Server) resolveBlock(raw json.RawMessage) (uint64, error) {
+	var block string
+	if err := json.Unmarshal(raw, &block); err != nil {
+		return 0, err
+	}
+	switch block {
+	case "", ethereum.Latest, ethereum.Pending, ethereum.Safe, "finalized":
+		return s.head(), nil
+	case ethereum.Earliest:
+		return 0, nil
+	}
+	return utils.DecodeQuantity(block)
+}
+
+func (s *Server) blockByHash(hash utils.Hash) (uint64, bool) {
+	for number, block := range s.blocks {
+		if block.Hash == hash.Hex() {
+			return uint64(number), true
+		}
+	}
+	return 0, false
+}
+
+func (s *Server) getLogs(raw json.RawMessage) ([]ethereum.LogsResponse, error) {
+	var filter struct {
+		Address   json.RawMessage      `json:"address"`
+		FromBlock string               `json:"fromBlock"`
+		ToBlock   string               `json:"toBlock"`
+		BlockHash *utils.Hash          `json:"blockHash"`
+		Topics    ethereum.TopicFilter `json:"topics"`
+	}
+	if err := json.Unmarshal(raw, &filter); err != nil {
+		return nil, err
+	}
+	addresses, err := decodeAddresses(filter.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	var from, to uint64
+	if filter.BlockHash != nil {
+		if filter.FromBlock != "" || filter.ToBlock != "" {
+			return nil, errors.New("blockHash cannot be combined with fromBlock or toBlock")
+		}
+		number, ok := s.blockByHash(*filter.BlockHash)
+		if !ok {
+			return nil, &jsonrpc.RPCError{Code: CodeHeaderNotFound, Message: "unknown block"}
+		}
+		from, to = number, number
+	} else {
+		if from, err = s.resolveBlock(quote(filter.FromBlock)); err != nil {
+			return nil, err
+		}
+		if to, err = s.resolveBlock(quote(filter.ToBlock)); err != nil {
+			return nil, err
+		}
+		if from > to {
+			return nil, fmt.Errorf("fromBlock %v is after toBlock %v", from, to)
+		}
+	}
+
+	logs := []ethereum.LogsResponse{}
+	for _, l := range s.logs {
+		if l.block < from || l.block > to || !matchAddress(addresses, l.address) || !matchTopics(filter.Topics, l.topics) {
+			continue
+		}
+		logs = append(logs, l.response(s.blocks[l.block]))
+	}
+	return logs, nil
+}
+
+func quote(s string) json.RawMessage {
+	encoded, _ := json.Marshal(s)
+	return encoded
+}
+
+// decodeAddresses accepts a single address, a list of addresses or nothing.
+func decodeAddresses(raw json.RawMessage) ([]utils.Address, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var addresses []utils.Address
+	if err := json.Unmarshal(raw, &addresses); err == nil {
+		return addresses, nil
+	}
+	var address utils.Address
+	if err := json.Unmarshal(raw, &address); err != nil {
+		return nil, err
+	}
+	return []utils.Address{address}, nil
+}
+
+func matchAddress(addresses []utils.Address, address utils.Address) bool {
+	if len(addresses) == 0 {
+		return true
+	}
+	for _, a := range addresses {
+		if a == address {
+			return true
+		}
+	}
+	return false
+}
+
+func matchTopics(filter ethereum.TopicFilter, topics []utils.Hash) bool {
+	for i, position := range filter {
+		if len(position) == 0 {
+			continue
+		}
+		if i >= len(topics) {
+			return false
+		}
+		matched := false
+		for _, topic := range position {
+			if topic == topics[i] {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
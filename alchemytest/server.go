@@ -0,0 +1,240 @@
+// Package alchemytest provides a fake Alchemy JSON-RPC server for tests.
+// Unlike the mocks package it speaks HTTP, so status codes, batching,
+// latency and timeouts are exercised the way they are against Alchemy:
+//
+//	server := alchemytest.NewServer()
+//	defer server.Close()
+//	server.MineBlocks(10)
+//	server.SetBalance(address, big.NewInt(1e18))
+//	server.RateLimit(1)
+//	client := server.Client(ethereum.WithRetry(middleware.RetryConfig{}))
+//
+// The chain state is kept in memory and configured from Go. Balances and
+// code are not versioned: every block sees the current state.
+package alchemytest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/massigerardi/alchemy-api/ethereum"
+	"github.com/massigerardi/alchemy-api/utils"
+	"github.com/ybbus/jsonrpc/v3"
+)
+
+// JSON-RPC error codes returned by the server.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeRateLimited    = 429
+)
+
+// DefaultGasPrice is the gas price of a new server, 20 gwei.
+var DefaultGasPrice = big.NewInt(20_000_000_000)
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+type response struct {
+	JSONRPC string            `json:"jsonrpc"`
+	ID      json.RawMessage   `json:"id"`
+	Result  json.RawMessage   `json:"result,omitempty"`
+	Error   *jsonrpc.RPCError `json:"error,omitempty"`
+}
+
+type callKey struct {
+	to   utils.Address
+	data string
+}
+
+type httpFault struct {
+	status    int
+	remaining int
+}
+
+// Server is a fake Alchemy endpoint listening on a local port. All its
+// methods are safe for concurrent use, including while serving requests.
+type Server struct {
+	// URL is the endpoint of the server, to be used in place of an Alchemy URL.
+	URL string
+
+	server *httptest.Server
+
+	mu       sync.Mutex
+	blocks   []*ethereum.Block
+	balances map[utils.Address]*big.Int
+	codes    map[utils.Address]string
+	calls    map[callKey]string
+	logs     []logEntry
+	gasPrice *big.Int
+
+	latency      time.Duration
+	methodErrors map[string]*jsonrpc.RPCError
+	httpFaults   []httpFault
+	requests     []string
+	httpRequests int
+}
+
+// NewServer starts a server whose chain holds the genesis block only.
+func NewServer() *Server {
+	s := &Server{
+		balances:     make(map[utils.Address]*big.Int),
+		codes:        make(map[utils.Address]string),
+		calls:        make(map[callKey]string),
+		gasPrice:     new(big.Int).Set(DefaultGasPrice),
+		methodErrors: make(map[string]*jsonrpc.RPCError),
+	}
+	s.mineBlock()
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.server.URL
+	return s
+}
+
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// RPCClient returns a jsonrpc.RPCClient sending requests to the server.
+func (s *Server) RPCClient() jsonrpc.RPCClient {
+	return jsonrpc.NewClient(s.URL)
+}
+
+// Client returns an EthClient sending requests to the server.
+func (s *Server) Client(opts ...ethereum.Option) *ethereum.EthClient {
+	return ethereum.NewFromRPCClient(s.RPCClient(), opts...)
+}
+
+// Requests returns the methods received so far in order, including those
+// of batches and of requests answered with an error.
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// HTTPRequests returns the number of HTTP requests received, a batch being a single one.
+func (s *Server) HTTPRequests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.httpRequests
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	s.httpRequests++
+	latency := s.latency
+	status := http.StatusOK
+	if len(s.httpFaults) > 0 {
+		status = s.httpFaults[0].status
+		s.httpFaults[0].remaining--
+		if s.httpFaults[0].remaining == 0 {
+			s.httpFaults = s.httpFaults[1:]
+		}
+	}
+	s.mu.Unlock()
+
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if status != http.StatusOK {
+		w.WriteHeader(status)
+		message := http.StatusText(status)
+		code := status
+		if status == http.StatusTooManyRequests {
+			code = CodeRateLimited
+			message = "Your app has exceeded its compute units per second capacity."
+		}
+		_ = json.NewEncoder(w).Encode(response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &jsonrpc.RPCError{Code: code, Message: message}})
+		return
+	}
+
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		var requests []request
+		if err := json.Unmarshal(body, &requests); err != nil {
+			_ = json.NewEncoder(w).Encode(errorResponse(nil, CodeParseError, err.Error()))
+			return
+		}
+		if len(requests) == 0 {
+			_ = json.NewEncoder(w).Encode(errorResponse(nil, CodeInvalidRequest, "empty batch"))
+			return
+		}
+		responses := make([]response, len(requests))
+		for i, request := range requests {
+			responses[i] = s.handle(request)
+		}
+		_ = json.NewEncoder(w).Encode(responses)
+		return
+	}
+	var request request
+	if err := json.Unmarshal(body, &request); err != nil {
+		_ = json.NewEncoder(w).Encode(errorResponse(nil, CodeParseError, err.Error()))
+		return
+	}
+	_ = json.NewEncoder(w).Encode(s.handle(request))
+}
+
+func errorResponse(id json.RawMessage, code int, message string) response {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	return response{JSONRPC: "2.0", ID: id, Error: &jsonrpc.RPCError{Code: code, Message: message}}
+}
+
+func (s *Server) handle(request request) response {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, request.Method)
+	if rpcError, ok := s.methodErrors[request.Method]; ok {
+		return errorResponse(request.ID, rpcError.Code, rpcError.Message)
+	}
+	handler, ok := handlers[request.Method]
+	if !ok {
+		return errorResponse(request.ID, CodeMethodNotFound, fmt.Sprintf("the method %v does not exist/is not available", request.Method))
+	}
+	var params []json.RawMessage
+	if len(request.Params) > 0 && string(request.Params) != "null" {
+		if err := json.Unmarshal(request.Params, &params); err != nil {
+			return errorResponse(request.ID, CodeInvalidParams, err.Error())
+		}
+	}
+	result, err := handler(s, params)
+	var rpcError *jsonrpc.RPCError
+	if errors.As(err, &rpcError) {
+		return errorResponse(request.ID, rpcError.Code, rpcError.Message)
+	}
+	if err != nil {
+		return errorResponse(request.ID, CodeInvalidParams, err.Error())
+	}
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return errorResponse(request.ID, CodeInvalidParams, err.Error())
+	}
+	return response{JSONRPC: "2.0", ID: request.ID, Result: encoded}
+}
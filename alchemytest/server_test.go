@@ -0,0 +1,210 @@
+package alchemytest
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/massigerardi/alchemy-api/ethereum"
+	"github.com/massigerardi/alchemy-api/middleware"
+	"github.com/massigerardi/alchemy-api/utils"
+	"github.com/ybbus/jsonrpc/v3"
+)
+
+var (
+	alice    = utils.MustParseAddress("0x549c660ce2b988f588769d6ad87be801695b2be3")
+	usdc     = utils.MustParseAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+	transfer = utils.EventTopic("Transfer(address,address,uint256)")
+	approval = utils.EventTopic("Approval(address,address,uint256)")
+)
+
+func TestServer_Chain(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	c := server.Client()
+
+	if got := server.MineBlocks(5); got != 5 {
+		t.Errorf("MineBlocks() got = %v, want 5", got)
+	}
+	if got, err := c.GetBlockNumber(); err != nil || got != "0x5" {
+		t.Errorf("GetBlockNumber() = %v, %v, want 0x5", got, err)
+	}
+
+	block, err := c.GetBlockByNumber("0x3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent, _ := server.BlockHash(2)
+	if block.Number != "0x3" || block.ParentHash != parent.Hex() {
+		t.Errorf("GetBlockByNumber() got = %+v", block)
+	}
+	byHash, err := c.GetBlockByHash(utils.MustParseHash(block.Hash))
+	if err != nil || !reflect.DeepEqual(byHash, block) {
+		t.Errorf("GetBlockByHash() = %+v, %v, want %+v", byHash, err, block)
+	}
+	if _, err := c.GetBlockByNumber("0x6"); err == nil {
+		t.Errorf("GetBlockByNumber() expected error for a block beyond the head")
+	}
+}
+
+func TestServer_State(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	c := server.Client()
+
+	server.SetBalance(alice, big.NewInt(1e18))
+	server.SetCode(usdc, "0x6080")
+	server.SetGasPrice(big.NewInt(30_000_000_000))
+	data := utils.SelectorHex("decimals()")
+	decimals := "0x0000000000000000000000000000000000000000000000000000000000000006"
+	server.SetCallResult(usdc, data, decimals)
+
+	if got, err := c.GetBalance(alice); err != nil || got.Cmp(big.NewInt(1e18)) != 0 {
+		t.Errorf("GetBalance() = %v, %v", got, err)
+	}
+	if got, err := c.GetBalance(usdc); err != nil || got.Sign() != 0 {
+		t.Errorf("GetBalance() = %v, %v, want 0", got, err)
+	}
+	if got, err := c.GetGasPrice(); err != nil || got.Int64() != 30_000_000_000 {
+		t.Errorf("GetGasPrice() = %v, %v", got, err)
+	}
+	if got, err := c.Call(ethereum.NewCallRequest(usdc, data)); err != nil || got != decimals {
+		t.Errorf("Call() = %v, %v", got, err)
+	}
+	if _, err := c.GetBalance(alice, "0x10"); err == nil {
+		t.Errorf("GetBalance() expected header not found")
+	}
+
+	codes, err := c.GetContractCodeBatch([]utils.Address{alice, usdc})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if codes[0].Code != "0x" || codes[1].Code != "0x6080" {
+		t.Errorf("GetContractCodeBatch() got = %v, %v", codes[0], codes[1])
+	}
+	if got := server.HTTPRequests(); got != 6 {
+		t.Errorf("HTTPRequests() got = %v, want 6", got)
+	}
+	want := []string{"eth_getBalance", "eth_getBalance", "eth_gasPrice", "eth_call", "eth_getBalance", "eth_getCode", "eth_getCode"}
+	if got := server.Requests(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Requests() got = %v, want %v", got, want)
+	}
+}
+
+func TestServer_Logs(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	c := server.Client()
+
+	from := utils.BytesToHash(alice.Bytes())
+	server.AddLog(2, usdc, "0x01", transfer, from)
+	server.AddLog(2, usdc, "0x02", approval, from)
+	server.AddLog(4, alice, "0x03", transfer)
+	server.MineBlocks(2)
+
+	tests := []struct {
+		name     string
+		request  ethereum.LogRequest
+		wantData []string
+	}{
+		{name: "All", request: ethereum.NewLogRequest(nil, "0x0", "latest"), wantData: []string{"0x01", "0x02", "0x03"}},
+		{name: "Address", request: ethereum.NewLogRequest([]utils.Address{usdc}, "0x0", "latest"), wantData: []string{"0x01", "0x02"}},
+		{name: "Range", request: ethereum.NewLogRequest(nil, "0x3", "0x6"), wantData: []string{"0x03"}},
+		{name: "Topic", request: ethereum.NewLogRequest(nil, "0x0", "latest", transfer), wantData: []string{"0x01", "0x03"}},
+		{name: "Topic OR", request: ethereum.NewLogRequest(nil, "0x0", "latest").WithTopics(ethereum.Topics().OneOf(transfer, approval).Exact(from)), wantData: []string{"0x01", "0x02"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs, err := c.FilterLogs(tt.request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var data []string
+			for _, log := range logs {
+				data = append(data, log.Data)
+			}
+			if !reflect.DeepEqual(data, tt.wantData) {
+				t.Errorf("FilterLogs() got = %v, want %v", data, tt.wantData)
+			}
+		})
+	}
+
+	hash, _ := server.BlockHash(2)
+	logs, err := c.FilterLogs(ethereum.NewBlockHashLogRequest(nil, hash, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 2 || logs[1].LogIndex != "0x1" || logs[1].BlockHash != hash.Hex() {
+		t.Errorf("FilterLogs() by block hash got = %+v", logs)
+	}
+}
+
+func TestServer_RateLimit(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	server.RateLimit(2)
+
+	var httpError *jsonrpc.HTTPError
+	if _, err := server.RPCClient().Call(context.Background(), ethereum.EthBlockNumber); !errors.As(err, &httpError) || httpError.Code != http.StatusTooManyRequests {
+		t.Errorf("Call() error = %v, want 429", err)
+	}
+	c := server.Client(ethereum.WithMiddleware(middleware.Retry(middleware.RetryConfig{Backoff: time.Millisecond})))
+	if got, err := c.GetBlockNumber(); err != nil || got != "0x0" {
+		t.Errorf("GetBlockNumber() = %v, %v", got, err)
+	}
+	if got := server.HTTPRequests(); got != 3 {
+		t.Errorf("HTTPRequests() got = %v, want 3", got)
+	}
+}
+
+func TestServer_Failures(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	c := server.Client()
+
+	server.FailMethod(ethereum.EthGetCode, -32005, "limit exceeded")
+	if _, err := c.GetContractCode(usdc); err == nil {
+		t.Errorf("GetContractCode() expected error")
+	}
+	codes, err := c.GetContractCodeBatch([]utils.Address{usdc})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if codes[0].Error == nil {
+		t.Errorf("GetContractCodeBatch() expected error in response")
+	}
+	server.ClearFailures()
+	if _, err := c.GetContractCode(usdc); err != nil {
+		t.Errorf("GetContractCode() error = %v", err)
+	}
+
+	server.FailHTTP(http.StatusBadGateway, 1)
+	if _, err := c.GetBlockNumber(); err == nil {
+		t.Errorf("GetBlockNumber() expected error")
+	}
+
+	response, err := server.RPCClient().Call(context.Background(), "eth_unknown")
+	if err != nil || response.Error == nil || response.Error.Code != CodeMethodNotFound {
+		t.Errorf("Call() = %v, %v, want method not found", response, err)
+	}
+}
+
+func TestServer_Latency(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	server.SetLatency(100 * time.Millisecond)
+
+	client := jsonrpc.NewClientWithOpts(server.URL, &jsonrpc.RPCClientOpts{HTTPClient: &http.Client{Timeout: 10 * time.Millisecond}})
+	if _, err := client.Call(context.Background(), ethereum.EthBlockNumber); err == nil {
+		t.Errorf("Call() expected timeout")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := server.RPCClient().Call(ctx, ethereum.EthBlockNumber); err != nil {
+		t.Errorf("Call() error = %v", err)
+	}
+}
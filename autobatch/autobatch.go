@@ -0,0 +1,203 @@
+// Package autobatch provides a jsonrpc.RPCClient that collects single calls
+// made concurrently, e.g. GetBalance from many goroutines, and sends them as
+// one batch once MaxSize calls are queued or Wait has passed since the first.
+//
+//	client := ethereum.NewFromRPCClient(autobatch.New(ethereum.NewRPCClient(apiKey), autobatch.Config{}))
+package autobatch
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/massigerardi/alchemy-api/batch"
+	"github.com/massigerardi/alchemy-api/middleware"
+	"github.com/ybbus/jsonrpc/v3"
+)
+
+const (
+	DefaultMaxSize = 100
+	DefaultWait    = 2 * time.Millisecond
+	DefaultTimeout = 30 * time.Second
+)
+
+type Config struct {
+	// MaxSize is the number of queued calls that triggers a batch, DefaultMaxSize if zero.
+	MaxSize int
+	// Wait is how long the first queued call waits for others, DefaultWait if zero.
+	Wait time.Duration
+	// Timeout bounds a batch, which is also cancelled once every caller in
+	// it has given up, DefaultTimeout if zero.
+	Timeout time.Duration
+}
+
+type result struct {
+	response *jsonrpc.RPCResponse
+	err      error
+}
+
+type call struct {
+	ctx    context.Context
+	method string
+	params interface{}
+	done   chan result
+}
+
+type Client struct {
+	next    jsonrpc.RPCClient
+	maxSize int
+	wait    time.Duration
+	timeout time.Duration
+
+	mu    sync.Mutex
+	queue []*call
+	// generation identifies the current queue, so that a timer started for
+	// a queue already flushed because it was full does not flush the next one.
+	generation uint64
+}
+
+func New(next jsonrpc.RPCClient, config Config) *Client {
+	if config.MaxSize <= 0 {
+		config.MaxSize = DefaultMaxSize
+	}
+	if config.Wait <= 0 {
+		config.Wait = DefaultWait
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	return &Client{next: next, maxSize: config.MaxSize, wait: config.Wait, timeout: config.Timeout}
+}
+
+// Middleware sends concurrent single calls as batches like New.
+func Middleware(config Config) middleware.Middleware {
+	return func(next middleware.Invoker) middleware.Invoker {
+		return New(middleware.Client(next), config)
+	}
+}
+
+func (c *Client) Call(ctx context.Context, method string, params ...interface{}) (*jsonrpc.RPCResponse, error) {
+	return c.enqueue(ctx, method, jsonrpc.Params(params...))
+}
+
+func (c *Client) CallRaw(ctx context.Context, request *jsonrpc.RPCRequest) (*jsonrpc.RPCResponse, error) {
+	return c.enqueue(ctx, request.Method, request.Params)
+}
+
+func (c *Client) CallFor(ctx context.Context, out interface{}, method string, params ...interface{}) error {
+	response, err := c.Call(ctx, method, params...)
+	if err != nil {
+		return err
+	}
+	if response.Error != nil {
+		return response.Error
+	}
+	return response.GetObject(out)
+}
+
+// CallBatch is passed through unchanged.
+func (c *Client) CallBatch(ctx context.Context, requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error) {
+	return c.next.CallBatch(ctx, requests)
+}
+
+func (c *Client) CallBatchRaw(ctx context.Context, requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error) {
+	return c.next.CallBatchRaw(ctx, requests)
+}
+
+// enqueue queues a call and waits for its result. A caller whose context is
+// done stops waiting, but its call stays in the batch until every caller in
+// it has given up.
+func (c *Client) enqueue(ctx context.Context, method string, params interface{}) (*jsonrpc.RPCResponse, error) {
+	pending := &call{ctx: ctx, method: method, params: params, done: make(chan result, 1)}
+
+	c.mu.Lock()
+	c.queue = append(c.queue, pending)
+	if len(c.queue) >= c.maxSize {
+		queue := c.take()
+		c.mu.Unlock()
+		go c.flush(queue)
+	} else {
+		if len(c.queue) == 1 {
+			generation := c.generation
+			time.AfterFunc(c.wait, func() {
+				c.flushGeneration(generation)
+			})
+		}
+		c.mu.Unlock()
+	}
+
+	select {
+	case r := <-pending.done:
+		return r.response, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// take empties the queue and starts a new generation. c.mu must be held.
+func (c *Client) take() []*call {
+	queue := c.queue
+	c.queue = nil
+	c.generation++
+	return queue
+}
+
+func (c *Client) flushGeneration(generation uint64) {
+	c.mu.Lock()
+	if generation != c.generation || len(c.queue) == 0 {
+		c.mu.Unlock()
+		return
+	}
+	queue := c.take()
+	c.mu.Unlock()
+	c.flush(queue)
+}
+
+func (c *Client) flush(queue []*call) {
+	ctx, cancel := c.flushContext(queue)
+	defer cancel()
+	requests := make(jsonrpc.RPCRequests, len(queue))
+	for i, pending := range queue {
+		requests[i] = &jsonrpc.RPCRequest{Method: pending.method, Params: pending.params, ID: i, JSONRPC: "2.0"}
+	}
+	responses, err := batch.DoBatchCallContext(ctx, c.next, requests)
+	byID := responses.AsMap()
+	for i, pending := range queue {
+		if err != nil {
+			pending.done <- result{err: err}
+			continue
+		}
+		// responses may come in any order and are only matched by ID
+		response, ok := byID[i]
+		if !ok {
+			pending.done <- result{err: fmt.Errorf("missing response for %v in batch", pending.method)}
+			continue
+		}
+		pending.done <- result{response: response}
+	}
+}
+
+// flushContext returns the context of the batch of queue: it carries the
+// values of the first caller, and is done after c.timeout or once the
+// context of every caller is done.
+func (c *Client) flushContext(queue []*call) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(queue[0].ctx), c.timeout)
+	var waiting atomic.Int32
+	waiting.Store(int32(len(queue)))
+	stops := make([]func() bool, len(queue))
+	for i, pending := range queue {
+		stops[i] = context.AfterFunc(pending.ctx, func() {
+			if waiting.Add(-1) == 0 {
+				cancel()
+			}
+		})
+	}
+	return ctx, func() {
+		for _, stop := range stops {
+			stop()
+		}
+		cancel()
+	}
+}
@@ -0,0 +1,188 @@
+package autobatch
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/massigerardi/alchemy-api/ethereum"
+	"github.com/massigerardi/alchemy-api/mocks"
+	"github.com/massigerardi/alchemy-api/utils"
+	"github.com/ybbus/jsonrpc/v3"
+)
+
+// recordingClient records the size of every batch reaching the mock client.
+type recordingClient struct {
+	jsonrpc.RPCClient
+	mu      sync.Mutex
+	batches []int
+	err     error
+}
+
+func (r *recordingClient) CallBatch(ctx context.Context, requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error) {
+	r.mu.Lock()
+	r.batches = append(r.batches, len(requests))
+	r.mu.Unlock()
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.RPCClient.CallBatch(ctx, requests)
+}
+
+func (r *recordingClient) total() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	total := 0
+	for _, size := range r.batches {
+		total += size
+	}
+	return total
+}
+
+var (
+	holder  = utils.MustParseAddress("0x549c660ce2b988f588769d6ad87be801695b2be3")
+	failing = utils.MustParseAddress("0x558fa75074cc7cf045c764aed47d37776ea697d1")
+	other   = utils.MustParseAddress("0x558FA75074cc7cF045C764aEd47D37776Ea697d2")
+)
+
+func TestClient_Batches(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      Config
+		callers     int
+		wantBatches []int
+	}{
+		{name: "Time Window", config: Config{Wait: 50 * time.Millisecond}, callers: 10, wantBatches: []int{10}},
+		{name: "Max Size", config: Config{Wait: time.Hour, MaxSize: 5}, callers: 10, wantBatches: []int{5, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upstream := &recordingClient{RPCClient: mocks.GetMockClient()}
+			c := ethereum.NewFromRPCClient(New(upstream, tt.config))
+			var wg sync.WaitGroup
+			for i := 0; i < tt.callers; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					got, err := c.GetBalance(holder)
+					if err != nil || got.Cmp(big.NewInt(20066469208092992)) != 0 {
+						t.Errorf("GetBalance() = %v, %v", got, err)
+					}
+				}()
+			}
+			wg.Wait()
+			if len(upstream.batches) != len(tt.wantBatches) || upstream.total() != tt.callers {
+				t.Errorf("batches = %v, want %v", upstream.batches, tt.wantBatches)
+			}
+		})
+	}
+}
+
+func TestClient_PerCallerResults(t *testing.T) {
+	upstream := &recordingClient{RPCClient: mocks.GetMockClient()}
+	c := ethereum.NewFromRPCClient(New(upstream, Config{Wait: 50 * time.Millisecond}))
+	addresses := []utils.Address{holder, failing, other}
+	want := []*big.Int{big.NewInt(20066469208092992), nil, big.NewInt(452046866901000)}
+
+	var wg sync.WaitGroup
+	got := make([]*big.Int, len(addresses))
+	errs := make([]error, len(addresses))
+	for i, address := range addresses {
+		wg.Add(1)
+		go func(i int, address utils.Address) {
+			defer wg.Done()
+			got[i], errs[i] = c.GetBalance(address)
+		}(i, address)
+	}
+	wg.Wait()
+
+	if len(upstream.batches) != 1 {
+		t.Errorf("batches = %v, want one", upstream.batches)
+	}
+	for i := range addresses {
+		if want[i] == nil {
+			if errs[i] == nil {
+				t.Errorf("GetBalance(%v) expected error", addresses[i])
+			}
+			continue
+		}
+		if errs[i] != nil || got[i].Cmp(want[i]) != 0 {
+			t.Errorf("GetBalance(%v) = %v, %v, want %v", addresses[i], got[i], errs[i], want[i])
+		}
+	}
+}
+
+func TestClient_BatchError(t *testing.T) {
+	failure := errors.New("connection refused")
+	upstream := &recordingClient{RPCClient: mocks.GetMockClient(), err: failure}
+	c := ethereum.NewFromRPCClient(New(upstream, Config{}))
+	if _, err := c.GetBalance(holder); !errors.Is(err, failure) {
+		t.Errorf("GetBalance() error = %v, want %v", err, failure)
+	}
+}
+
+func TestClient_MissingResponse(t *testing.T) {
+	upstream := &recordingClient{RPCClient: mocks.New().OnBatch().Return()}
+	c := ethereum.NewFromRPCClient(New(upstream, Config{}))
+	if _, err := c.GetBlockNumber(); err == nil {
+		t.Errorf("GetBlockNumber() expected error for a batch answered without responses")
+	}
+
+	upstream = &recordingClient{RPCClient: mocks.New().OnBatch().Return(&jsonrpc.RPCResponse{ID: 7, Result: "0x1"})}
+	c = ethereum.NewFromRPCClient(New(upstream, Config{}))
+	if got, err := c.GetBlockNumber(); err == nil {
+		t.Errorf("GetBlockNumber() got = %v, expected error for a response of another ID", got)
+	}
+}
+
+// blockingClient holds batches until their context is done, and reports its error.
+type blockingClient struct {
+	jsonrpc.RPCClient
+	done chan error
+}
+
+func (b *blockingClient) CallBatch(ctx context.Context, _ jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error) {
+	<-ctx.Done()
+	b.done <- ctx.Err()
+	return nil, ctx.Err()
+}
+
+func TestClient_CallersGiveUp(t *testing.T) {
+	upstream := &blockingClient{RPCClient: mocks.GetMockClient(), done: make(chan error, 1)}
+	c := New(upstream, Config{MaxSize: 2})
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(i+1)*10*time.Millisecond)
+		defer cancel()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.Call(ctx, "eth_blockNumber"); !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("Call() error = %v, want %v", err, context.DeadlineExceeded)
+			}
+		}()
+	}
+	wg.Wait()
+	select {
+	case err := <-upstream.done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("batch error = %v, want %v", err, context.Canceled)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("batch still running after every caller gave up")
+	}
+}
+
+func TestClient_Timeout(t *testing.T) {
+	upstream := &blockingClient{RPCClient: mocks.GetMockClient(), done: make(chan error, 1)}
+	c := New(upstream, Config{Timeout: 10 * time.Millisecond})
+	if _, err := c.Call(context.Background(), "eth_blockNumber"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Call() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if err := <-upstream.done; !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("batch error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
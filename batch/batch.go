@@ -7,9 +7,13 @@ import (
 )
 
 func DoBatchCall(client jsonrpc.RPCClient, requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error) {
-	responses, err := client.CallBatch(context.Background(), requests)
+	return DoBatchCallContext(context.Background(), client, requests)
+}
+
+// DoBatchCallContext is DoBatchCall with a context, e.g. to cancel a batch of a stream.
+func DoBatchCallContext(ctx context.Context, client jsonrpc.RPCClient, requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error) {
+	responses, err := client.CallBatch(ctx, requests)
 	if err != nil {
-		println(err)
 		return nil, err
 	}
 	return responses, nil
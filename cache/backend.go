@@ -0,0 +1,119 @@
+package cache
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Backend stores encoded responses by request key.
+type Backend interface {
+	Get(key string) ([]byte, bool, error)
+	Set(key string, value []byte) error
+}
+
+type lruEntry struct {
+	key   string
+	value []byte
+}
+
+// LRU is an in-memory Backend holding at most a fixed number of entries,
+// evicting the least recently used one when full. It is safe for concurrent use.
+type LRU struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+func NewLRU(size int) *LRU {
+	if size <= 0 {
+		size = 1
+	}
+	return &LRU{size: size, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+func (l *LRU) Get(key string) ([]byte, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	element, ok := l.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	l.order.MoveToFront(element)
+	return element.Value.(*lruEntry).value, true, nil
+}
+
+func (l *LRU) Set(key string, value []byte) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if element, ok := l.entries[key]; ok {
+		element.Value.(*lruEntry).value = value
+		l.order.MoveToFront(element)
+		return nil
+	}
+	l.entries[key] = l.order.PushFront(&lruEntry{key: key, value: value})
+	if l.order.Len() > l.size {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.entries, oldest.Value.(*lruEntry).key)
+	}
+	return nil
+}
+
+// Len returns the number of cached entries.
+func (l *LRU) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.order.Len()
+}
+
+// Disk is a Backend storing one file per entry in a directory, named after
+// the SHA-256 of the key. Entries are written atomically and never expire.
+type Disk struct {
+	dir string
+}
+
+func NewDisk(dir string) (*Disk, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &Disk{dir: dir}, nil
+}
+
+func (d *Disk) Get(key string) ([]byte, bool, error) {
+	value, err := os.ReadFile(d.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+func (d *Disk) Set(key string, value []byte) error {
+	tmp, err := os.CreateTemp(d.dir, "entry.*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(value); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), d.path(key))
+}
+
+func (d *Disk) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:])+".json")
+}
@@ -0,0 +1,47 @@
+package cache
+
+import (
+	"testing"
+)
+
+func TestLRU(t *testing.T) {
+	lru := NewLRU(2)
+	_ = lru.Set("a", []byte("1"))
+	_ = lru.Set("b", []byte("2"))
+	if _, ok, _ := lru.Get("a"); !ok {
+		t.Fatalf("Get(a) missing")
+	}
+	// b is now the least recently used entry
+	_ = lru.Set("c", []byte("3"))
+	if _, ok, _ := lru.Get("b"); ok {
+		t.Errorf("Get(b) should have been evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok, _ := lru.Get(key); !ok {
+			t.Errorf("Get(%v) missing", key)
+		}
+	}
+	if lru.Len() != 2 {
+		t.Errorf("Len() = %v, want 2", lru.Len())
+	}
+}
+
+func TestDisk(t *testing.T) {
+	dir := t.TempDir()
+	disk, err := NewDisk(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, err := disk.Get("eth_getBalance [\"0x1\",\"0x10\"]"); ok || err != nil {
+		t.Fatalf("Get() on empty cache = %v, %v", ok, err)
+	}
+	if err := disk.Set("eth_getBalance [\"0x1\",\"0x10\"]", []byte(`"0x474a58f10b7140"`)); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	reopened, _ := NewDisk(dir)
+	got, ok, err := reopened.Get("eth_getBalance [\"0x1\",\"0x10\"]")
+	if err != nil || !ok || string(got) != `"0x474a58f10b7140"` {
+		t.Errorf("Get() = %s, %v, %v", got, ok, err)
+	}
+}
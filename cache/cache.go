@@ -0,0 +1,315 @@
+// Package cache provides a jsonrpc.RPCClient that caches responses of
+// queries about finalized history, which can never change: balances, code,
+// storage, calls, blocks and logs at a concrete block number that is at least
+// FinalityDepth blocks behind the head.
+//
+//	client := ethereum.NewFromRPCClient(cache.New(ethereum.NewRPCClient(apiKey), cache.Config{}))
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/massigerardi/alchemy-api/middleware"
+	"github.com/massigerardi/alchemy-api/utils"
+	"github.com/ybbus/jsonrpc/v3"
+)
+
+const (
+	DefaultFinalityDepth uint64 = 64
+	DefaultHeadTTL              = 12 * time.Second
+	DefaultLRUSize              = 4096
+)
+
+// blockParams maps cacheable methods to the position of their block parameter.
+var blockParams = map[string]int{
+	"eth_getBalance":          1,
+	"eth_getCode":             1,
+	"eth_getTransactionCount": 1,
+	"eth_getStorageAt":        2,
+	"eth_call":                1,
+	"eth_getProof":            2,
+	"eth_getBlockByNumber":    0,
+}
+
+const ethGetLogs = "eth_getLogs"
+
+// headRetry is how long the head is not fetched again after a failure.
+const headRetry = time.Second
+
+type Config struct {
+	// FinalityDepth is the number of blocks behind the head after which a
+	// block is considered final, DefaultFinalityDepth if zero.
+	FinalityDepth uint64
+	// Backend stores the responses, an LRU of DefaultLRUSize entries if nil.
+	Backend Backend
+	// HeadTTL is how long the head block number is reused before being
+	// fetched again, DefaultHeadTTL if zero.
+	HeadTTL time.Duration
+}
+
+// Stats counts cacheable requests served from the cache (Hits) or sent
+// upstream (Misses), and requests that could not be cached (Bypassed).
+type Stats struct {
+	Hits     uint64
+	Misses   uint64
+	Bypassed uint64
+}
+
+type Client struct {
+	next   jsonrpc.RPCClient
+	config Config
+
+	hits     atomic.Uint64
+	misses   atomic.Uint64
+	bypassed atomic.Uint64
+
+	mu         sync.Mutex
+	head       uint64
+	headAt     time.Time
+	failedAt   time.Time
+	refreshing bool
+}
+
+// New returns a caching client sending requests that miss the cache to next.
+func New(next jsonrpc.RPCClient, config Config) *Client {
+	if config.FinalityDepth == 0 {
+		config.FinalityDepth = DefaultFinalityDepth
+	}
+	if config.Backend == nil {
+		config.Backend = NewLRU(DefaultLRUSize)
+	}
+	if config.HeadTTL <= 0 {
+		config.HeadTTL = DefaultHeadTTL
+	}
+	return &Client{next: next, config: config}
+}
+
+// Middleware caches the responses of finalized historical queries like New.
+func Middleware(config Config) middleware.Middleware {
+	return func(next middleware.Invoker) middleware.Invoker {
+		return New(middleware.Client(next), config)
+	}
+}
+
+func (c *Client) Stats() Stats {
+	return Stats{Hits: c.hits.Load(), Misses: c.misses.Load(), Bypassed: c.bypassed.Load()}
+}
+
+func (c *Client) Call(ctx context.Context, method string, params ...interface{}) (*jsonrpc.RPCResponse, error) {
+	return c.call(ctx, method, jsonrpc.Params(params...), func() (*jsonrpc.RPCResponse, error) {
+		return c.next.Call(ctx, method, params...)
+	})
+}
+
+func (c *Client) CallRaw(ctx context.Context, request *jsonrpc.RPCRequest) (*jsonrpc.RPCResponse, error) {
+	return c.call(ctx, request.Method, request.Params, func() (*jsonrpc.RPCResponse, error) {
+		return c.next.CallRaw(ctx, request)
+	})
+}
+
+func (c *Client) CallFor(ctx context.Context, out interface{}, method string, params ...interface{}) error {
+	response, err := c.Call(ctx, method, params...)
+	if err != nil {
+		return err
+	}
+	if response.Error != nil {
+		return response.Error
+	}
+	return response.GetObject(out)
+}
+
+func (c *Client) CallBatch(ctx context.Context, requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error) {
+	return c.callBatch(ctx, requests, c.next.CallBatch)
+}
+
+func (c *Client) CallBatchRaw(ctx context.Context, requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error) {
+	return c.callBatch(ctx, requests, c.next.CallBatchRaw)
+}
+
+func (c *Client) call(ctx context.Context, method string, params interface{}, fetch func() (*jsonrpc.RPCResponse, error)) (*jsonrpc.RPCResponse, error) {
+	key, cacheable := c.key(ctx, method, params)
+	if !cacheable {
+		c.bypassed.Add(1)
+		return fetch()
+	}
+	if response, ok := c.lookup(key); ok {
+		return response, nil
+	}
+	response, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	c.store(key, response)
+	return response, nil
+}
+
+func (c *Client) callBatch(ctx context.Context, requests jsonrpc.RPCRequests, fetch func(context.Context, jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error)) (jsonrpc.RPCResponses, error) {
+	responses := make(jsonrpc.RPCResponses, 0, len(requests))
+	keys := make([]string, len(requests))
+	var missing jsonrpc.RPCRequests
+	var positions []int
+	for i, request := range requests {
+		key, cacheable := c.key(ctx, request.Method, request.Params)
+		if !cacheable {
+			c.bypassed.Add(1)
+		} else if response, ok := c.lookup(key); ok {
+			response.ID = request.ID
+			responses = append(responses, response)
+			continue
+		}
+		keys[i] = key
+		// The missing requests are sent as clones numbered by their position
+		// among them: CallBatch renumbers the requests it is given, and the
+		// caller's must keep their IDs.
+		clone := *request
+		clone.ID = len(missing)
+		missing = append(missing, &clone)
+		positions = append(positions, i)
+	}
+	if len(missing) == 0 {
+		return responses, nil
+	}
+	fetched, err := fetch(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+	byID := fetched.AsMap()
+	for j, i := range positions {
+		response, ok := byID[j]
+		if !ok {
+			continue
+		}
+		if keys[i] != "" {
+			c.store(keys[i], response)
+		}
+		response.ID = requests[i].ID
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+// key returns the cache key of a request, and whether it may be cached.
+func (c *Client) key(ctx context.Context, method string, params interface{}) (string, bool) {
+	index, ok := blockParams[method]
+	if !ok && method != ethGetLogs {
+		return "", false
+	}
+	encoded, err := json.Marshal(params)
+	if err != nil {
+		return "", false
+	}
+	var list []json.RawMessage
+	if err := json.Unmarshal(encoded, &list); err != nil {
+		return "", false
+	}
+
+	var blocks []string
+	if method == ethGetLogs {
+		var filter struct {
+			FromBlock string `json:"fromBlock"`
+			ToBlock   string `json:"toBlock"`
+		}
+		if len(list) != 1 || json.Unmarshal(list[0], &filter) != nil {
+			return "", false
+		}
+		blocks = []string{filter.FromBlock, filter.ToBlock}
+	} else {
+		var block string
+		if index >= len(list) || json.Unmarshal(list[index], &block) != nil {
+			return "", false
+		}
+		blocks = []string{block}
+	}
+
+	for _, block := range blocks {
+		number, err := utils.DecodeQuantity(block)
+		if err != nil {
+			return "", false
+		}
+		finalized, ok := c.finalized(ctx)
+		if !ok || number > finalized {
+			return "", false
+		}
+	}
+	return method + " " + string(encoded), true
+}
+
+// finalized returns the highest block number considered final. One caller
+// at a time refreshes the head, outside c.mu, while the others use the
+// previous head, which is never ahead of the chain. A failed refresh is not
+// retried for headRetry.
+func (c *Client) finalized(ctx context.Context) (uint64, bool) {
+	c.mu.Lock()
+	refresh := !c.refreshing && time.Since(c.headAt) > c.config.HeadTTL && time.Since(c.failedAt) > headRetry
+	if refresh {
+		c.refreshing = true
+	}
+	head, known := c.head, !c.headAt.IsZero()
+	c.mu.Unlock()
+
+	if refresh {
+		fetched, err := c.fetchHead(ctx)
+		c.mu.Lock()
+		c.refreshing = false
+		if err != nil {
+			c.failedAt = time.Now()
+		} else {
+			c.head, c.headAt = fetched, time.Now()
+			head, known = fetched, true
+		}
+		c.mu.Unlock()
+	}
+	if !known || head < c.config.FinalityDepth {
+		return 0, false
+	}
+	return head - c.config.FinalityDepth, true
+}
+
+func (c *Client) fetchHead(ctx context.Context) (uint64, error) {
+	response, err := c.next.Call(ctx, "eth_blockNumber")
+	if err != nil {
+		return 0, err
+	}
+	if response.Error != nil {
+		return 0, response.Error
+	}
+	number, err := response.GetString()
+	if err != nil {
+		return 0, err
+	}
+	return utils.DecodeQuantity(number)
+}
+
+func (c *Client) lookup(key string) (*jsonrpc.RPCResponse, bool) {
+	value, ok, err := c.config.Backend.Get(key)
+	if err != nil || !ok {
+		c.misses.Add(1)
+		return nil, false
+	}
+	var result interface{}
+	decoder := json.NewDecoder(bytes.NewReader(value))
+	decoder.UseNumber()
+	if err := decoder.Decode(&result); err != nil {
+		c.misses.Add(1)
+		return nil, false
+	}
+	c.hits.Add(1)
+	return &jsonrpc.RPCResponse{JSONRPC: "2.0", Result: result}, true
+}
+
+// store caches successful, non empty responses. Backend failures only cost a future miss.
+func (c *Client) store(key string, response *jsonrpc.RPCResponse) {
+	if response == nil || response.Error != nil || response.Result == nil {
+		return
+	}
+	value, err := json.Marshal(response.Result)
+	if err != nil {
+		return
+	}
+	_ = c.config.Backend.Set(key, value)
+}
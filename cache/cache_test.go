@@ -0,0 +1,277 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/massigerardi/alchemy-api/ethereum"
+	"github.com/massigerardi/alchemy-api/mocks"
+	"github.com/massigerardi/alchemy-api/utils"
+	"github.com/ybbus/jsonrpc/v3"
+)
+
+// countingClient counts the requests reaching the mock client, whose head is block 0x1234.
+type countingClient struct {
+	jsonrpc.RPCClient
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func newCountingClient() *countingClient {
+	return &countingClient{RPCClient: mocks.GetMockClient(), calls: make(map[string]int)}
+}
+
+func (c *countingClient) Call(ctx context.Context, method string, params ...interface{}) (*jsonrpc.RPCResponse, error) {
+	c.mu.Lock()
+	c.calls[method]++
+	c.mu.Unlock()
+	return c.RPCClient.Call(ctx, method, params...)
+}
+
+func (c *countingClient) CallBatch(ctx context.Context, requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error) {
+	c.mu.Lock()
+	for _, request := range requests {
+		c.calls[request.Method]++
+	}
+	c.mu.Unlock()
+	return c.RPCClient.CallBatch(ctx, requests)
+}
+
+var holder = utils.MustParseAddress("0x549c660ce2b988f588769d6ad87be801695b2be3")
+
+func TestClient_GetBalance(t *testing.T) {
+	tests := []struct {
+		name      string
+		block     string
+		wantCalls int
+		wantStats Stats
+	}{
+		{name: "Finalized Block", block: "0x10", wantCalls: 1, wantStats: Stats{Hits: 2, Misses: 1}},
+		{name: "Latest", block: ethereum.Latest, wantCalls: 3, wantStats: Stats{Bypassed: 3}},
+		{name: "Recent Block", block: "0x1230", wantCalls: 3, wantStats: Stats{Bypassed: 3}},
+		{name: "Finality Boundary", block: "0x11f4", wantCalls: 1, wantStats: Stats{Hits: 2, Misses: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upstream := newCountingClient()
+			cached := New(upstream, Config{FinalityDepth: 64})
+			c := ethereum.NewFromRPCClient(cached)
+			for i := 0; i < 3; i++ {
+				got, err := c.GetBalance(holder, tt.block)
+				if err != nil {
+					t.Fatalf("GetBalance() error = %v", err)
+				}
+				if got.Cmp(big.NewInt(20066469208092992)) != 0 {
+					t.Errorf("GetBalance() got = %v", got)
+				}
+			}
+			if got := upstream.calls["eth_getBalance"]; got != tt.wantCalls {
+				t.Errorf("upstream eth_getBalance calls = %v, want %v", got, tt.wantCalls)
+			}
+			if got := cached.Stats(); got != tt.wantStats {
+				t.Errorf("Stats() = %+v, want %+v", got, tt.wantStats)
+			}
+			if got := upstream.calls["eth_blockNumber"]; tt.wantStats.Bypassed == 0 && got != 1 {
+				t.Errorf("upstream eth_blockNumber calls = %v, want 1", got)
+			}
+		})
+	}
+}
+
+func TestClient_ErrorsNotCached(t *testing.T) {
+	upstream := newCountingClient()
+	c := ethereum.NewFromRPCClient(New(upstream, Config{}))
+	failing := utils.MustParseAddress("0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb47")
+	for i := 0; i < 2; i++ {
+		if _, err := c.GetBalance(failing, "0x10"); err == nil {
+			t.Errorf("GetBalance() expected remote error")
+		}
+	}
+	if got := upstream.calls["eth_getBalance"]; got != 2 {
+		t.Errorf("upstream eth_getBalance calls = %v, want 2", got)
+	}
+}
+
+func TestClient_CallBatch(t *testing.T) {
+	upstream := newCountingClient()
+	cached := New(upstream, Config{})
+	c := ethereum.NewFromRPCClient(cached)
+	usdc := utils.MustParseAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+
+	if _, err := c.GetContractCode(usdc, "0x10"); err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.GetContractCodeBatch([]utils.Address{holder, usdc}, "0x10")
+	if err != nil {
+		t.Fatalf("GetContractCodeBatch() error = %v", err)
+	}
+	if got[0].Code != mocks.EoaCode || got[1].Code != mocks.UsdcCode {
+		t.Errorf("GetContractCodeBatch() got = %v, %v", got[0], got[1])
+	}
+	if calls := upstream.calls["eth_getCode"]; calls != 2 {
+		t.Errorf("upstream eth_getCode calls = %v, want 2", calls)
+	}
+	if _, err := c.GetContractCodeBatch([]utils.Address{holder, usdc}, "0x10"); err != nil {
+		t.Fatal(err)
+	}
+	if calls := upstream.calls["eth_getCode"]; calls != 2 {
+		t.Errorf("upstream eth_getCode calls after cached batch = %v, want 2", calls)
+	}
+	if stats := cached.Stats(); stats.Hits != 3 || stats.Misses != 2 {
+		t.Errorf("Stats() = %+v", stats)
+	}
+}
+
+// reversedClient returns batch responses in reverse order, as a server may.
+type reversedClient struct {
+	jsonrpc.RPCClient
+}
+
+func (c reversedClient) CallBatch(ctx context.Context, requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error) {
+	responses, err := c.RPCClient.CallBatch(ctx, requests)
+	for i, j := 0, len(responses)-1; i < j; i, j = i+1, j-1 {
+		responses[i], responses[j] = responses[j], responses[i]
+	}
+	return responses, err
+}
+
+func TestClient_CallBatch_HitsAndMisses(t *testing.T) {
+	usdc := utils.MustParseAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+	contract := utils.MustParseAddress("0x558FA75074cc7cF045C764aEd47D37776Ea697d2")
+	upstream := mocks.New().
+		On("eth_blockNumber").Return("0x1234").
+		On(ethereum.EthGetBalance, holder, "0x10").Return("0x1").
+		On(ethereum.EthGetBalance, usdc, "0x10").Return("0x2").
+		On(ethereum.EthGetBalance, contract, "0x10").Return("0x3")
+	c := ethereum.NewFromRPCClient(New(reversedClient{upstream}, Config{}))
+
+	if _, err := c.GetBalance(holder, "0x10"); err != nil {
+		t.Fatal(err)
+	}
+	b := c.NewBatch()
+	balances := []*ethereum.Future[*big.Int]{b.GetBalance(holder, "0x10"), b.GetBalance(usdc, "0x10"), b.GetBalance(contract, "0x10")}
+	if err := b.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	for i, balance := range balances {
+		if got, err := balance.Get(); err != nil || got.Int64() != int64(i+1) {
+			t.Errorf("GetBalance() got[%v] = %v, %v, want %v", i, got, err, i+1)
+		}
+	}
+
+	responses, err := c.GetBalanceBatch([]utils.Address{contract, holder, usdc}, "0x10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []int64{3, 1, 2} {
+		if responses[i].Error != nil || responses[i].Amount.Int64() != want {
+			t.Errorf("GetBalanceBatch() got[%v] = %v, want %v", i, responses[i], want)
+		}
+	}
+}
+
+func TestClient_GetLogs(t *testing.T) {
+	upstream := newCountingClient()
+	cached := New(upstream, Config{})
+	tests := []struct {
+		name      string
+		request   ethereum.LogRequest
+		wantCalls int
+	}{
+		{name: "Open Range", request: ethereum.NewLogRequest(nil, "0x10", ethereum.Latest), wantCalls: 2},
+		{name: "Finalized Range", request: ethereum.NewLogRequest(nil, "0x10", "0x20"), wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := upstream.calls["eth_getLogs"]
+			for i := 0; i < 2; i++ {
+				if _, err := cached.Call(context.Background(), ethereum.EthGetLogs, []interface{}{tt.request}); err != nil {
+					t.Fatalf("Call() error = %v", err)
+				}
+			}
+			if got := upstream.calls["eth_getLogs"] - before; got != tt.wantCalls {
+				t.Errorf("upstream eth_getLogs calls = %v, want %v", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+// headClient answers eth_blockNumber after release is closed, or with err.
+type headClient struct {
+	*countingClient
+	release chan struct{}
+	err     error
+}
+
+func (c *headClient) Call(ctx context.Context, method string, params ...interface{}) (*jsonrpc.RPCResponse, error) {
+	if method == "eth_blockNumber" {
+		c.mu.Lock()
+		c.calls[method]++
+		c.mu.Unlock()
+		if c.err != nil {
+			return nil, c.err
+		}
+		select {
+		case <-c.release:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		return c.RPCClient.Call(ctx, method, params...)
+	}
+	return c.countingClient.Call(ctx, method, params...)
+}
+
+func (c *countingClient) count(method string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.calls[method]
+}
+
+func TestClient_SlowHead(t *testing.T) {
+	upstream := &headClient{countingClient: newCountingClient(), release: make(chan struct{})}
+	cached := New(upstream, Config{})
+	refreshed := make(chan error, 1)
+	go func() {
+		_, err := cached.Call(context.Background(), "eth_getBalance", holder, "0x10")
+		refreshed <- err
+	}()
+	for upstream.count("eth_blockNumber") == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	// the other requests bypass the cache instead of waiting for the head
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := cached.Call(ctx, "eth_getBalance", holder, "0x10"); err != nil {
+		t.Fatal(err)
+	}
+	if got := cached.Stats(); got.Bypassed != 1 {
+		t.Errorf("Stats() = %+v, want 1 bypassed", got)
+	}
+	close(upstream.release)
+	if err := <-refreshed; err != nil {
+		t.Fatal(err)
+	}
+	if got := upstream.count("eth_blockNumber"); got != 1 {
+		t.Errorf("upstream eth_blockNumber calls = %v, want 1", got)
+	}
+}
+
+func TestClient_HeadFailure(t *testing.T) {
+	upstream := &headClient{countingClient: newCountingClient(), err: errors.New("connection reset")}
+	cached := New(upstream, Config{HeadTTL: time.Nanosecond})
+	for i := 0; i < 3; i++ {
+		if _, err := cached.Call(context.Background(), "eth_getBalance", holder, "0x10"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := upstream.count("eth_blockNumber"); got != 1 {
+		t.Errorf("upstream eth_blockNumber calls = %v, want 1 within the retry delay", got)
+	}
+	if got := cached.Stats(); got.Bypassed != 3 {
+		t.Errorf("Stats() = %+v, want 3 bypassed", got)
+	}
+}
@@ -0,0 +1,245 @@
+package cassette
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/massigerardi/alchemy-api/middleware"
+	"github.com/ybbus/jsonrpc/v3"
+)
+
+// ErrNotRecorded is returned in Replay mode for requests missing from the cassette.
+var ErrNotRecorded = errors.New("request not recorded")
+
+type Mode int
+
+const (
+	// Replay answers from the cassette only, without an upstream client.
+	Replay Mode = iota
+	// Record sends every request upstream and appends it to the cassette.
+	Record
+	// ReplayOrRecord answers from the cassette and records the requests it is missing.
+	ReplayOrRecord
+)
+
+// Client is a jsonrpc.RPCClient replaying or recording a cassette. It is safe for concurrent use.
+type Client struct {
+	next jsonrpc.RPCClient
+	mode Mode
+
+	mu       sync.Mutex
+	cassette *Cassette
+	recorded map[string][]int
+	played   map[string]int
+}
+
+// New returns a client replaying or recording cassette. next is only used
+// in Record and ReplayOrRecord modes and may be nil in Replay mode.
+func New(cassette *Cassette, mode Mode, next jsonrpc.RPCClient) *Client {
+	c := &Client{next: next, mode: mode, cassette: cassette, recorded: make(map[string][]int), played: make(map[string]int)}
+	for i, interaction := range cassette.Interactions {
+		k := key(interaction.Method, interaction.Params)
+		c.recorded[k] = append(c.recorded[k], i)
+	}
+	return c
+}
+
+// Middleware returns a middleware.Middleware replaying or recording
+// cassette with the next invoker as upstream client.
+func Middleware(cassette *Cassette, mode Mode) middleware.Middleware {
+	return func(next middleware.Invoker) middleware.Invoker {
+		return New(cassette, mode, middleware.Client(next))
+	}
+}
+
+// Cassette returns the cassette including the interactions recorded so far.
+func (c *Client) Cassette() *Cassette {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return &Cassette{Interactions: append([]Interaction(nil), c.cassette.Interactions...)}
+}
+
+func (c *Client) Call(ctx context.Context, method string, params ...interface{}) (*jsonrpc.RPCResponse, error) {
+	return c.call(method, jsonrpc.Params(params...), func() (*jsonrpc.RPCResponse, error) {
+		return c.next.Call(ctx, method, params...)
+	})
+}
+
+func (c *Client) CallRaw(ctx context.Context, request *jsonrpc.RPCRequest) (*jsonrpc.RPCResponse, error) {
+	response, err := c.call(request.Method, request.Params, func() (*jsonrpc.RPCResponse, error) {
+		return c.next.CallRaw(ctx, request)
+	})
+	if response != nil {
+		response.ID = request.ID
+	}
+	return response, err
+}
+
+func (c *Client) call(method string, params interface{}, fetch func() (*jsonrpc.RPCResponse, error)) (*jsonrpc.RPCResponse, error) {
+	encoded, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	if c.mode != Record {
+		response, ok, err := c.replay(method, encoded)
+		if ok || err != nil {
+			return response, err
+		}
+		if c.mode == Replay {
+			return nil, fmt.Errorf("%w: %v %s", ErrNotRecorded, method, encoded)
+		}
+	}
+	response, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	if err := c.record(method, encoded, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *Client) CallFor(ctx context.Context, out interface{}, method string, params ...interface{}) error {
+	response, err := c.Call(ctx, method, params...)
+	if err != nil {
+		return err
+	}
+	if response.Error != nil {
+		return response.Error
+	}
+	return response.GetObject(out)
+}
+
+func (c *Client) CallBatch(ctx context.Context, requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error) {
+	return c.callBatch(ctx, requests, c.nextCallBatch)
+}
+
+func (c *Client) CallBatchRaw(ctx context.Context, requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error) {
+	return c.callBatch(ctx, requests, c.nextCallBatchRaw)
+}
+
+func (c *Client) nextCallBatch(ctx context.Context, requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error) {
+	return c.next.CallBatch(ctx, requests)
+}
+
+func (c *Client) nextCallBatchRaw(ctx context.Context, requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error) {
+	return c.next.CallBatchRaw(ctx, requests)
+}
+
+// callBatch replays a batch when every request in it was recorded, and
+// otherwise sends the whole batch upstream and records each request with
+// the response of the same ID, responses arriving in any order.
+func (c *Client) callBatch(ctx context.Context, requests jsonrpc.RPCRequests, fetch func(context.Context, jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error)) (jsonrpc.RPCResponses, error) {
+	encoded := make([][]byte, len(requests))
+	for i, request := range requests {
+		params, err := json.Marshal(request.Params)
+		if err != nil {
+			return nil, err
+		}
+		encoded[i] = params
+	}
+	if c.mode != Record {
+		responses, missing, err := c.replayBatch(requests, encoded)
+		if err != nil || missing < 0 {
+			return responses, err
+		}
+		if c.mode == Replay {
+			return nil, fmt.Errorf("%w: %v %s", ErrNotRecorded, requests[missing].Method, encoded[missing])
+		}
+	}
+	responses, err := fetch(ctx, requests)
+	if err != nil {
+		return nil, err
+	}
+	byID := responses.AsMap()
+	for i, request := range requests {
+		response, ok := byID[request.ID]
+		if !ok {
+			continue
+		}
+		if err := c.record(request.Method, encoded[i], response); err != nil {
+			return nil, err
+		}
+	}
+	return responses, nil
+}
+
+// replayBatch returns the recorded responses of requests, or the index of
+// the first request that was not recorded. Nothing is replayed unless every
+// request was recorded.
+func (c *Client) replayBatch(requests jsonrpc.RPCRequests, encoded [][]byte) (jsonrpc.RPCResponses, int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, request := range requests {
+		if len(c.recorded[key(request.Method, encoded[i])]) == 0 {
+			return nil, i, nil
+		}
+	}
+	responses := make(jsonrpc.RPCResponses, len(requests))
+	for i, request := range requests {
+		response, err := c.take(key(request.Method, encoded[i]))
+		if err != nil {
+			return nil, -1, err
+		}
+		response.ID = request.ID
+		responses[i] = response
+	}
+	return responses, -1, nil
+}
+
+func (c *Client) replay(method string, params []byte) (*jsonrpc.RPCResponse, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	k := key(method, params)
+	if len(c.recorded[k]) == 0 {
+		return nil, false, nil
+	}
+	response, err := c.take(k)
+	return response, true, err
+}
+
+// take decodes the next recorded response for k, repeating the last one
+// once all were played. c.mu must be held.
+func (c *Client) take(k string) (*jsonrpc.RPCResponse, error) {
+	indexes := c.recorded[k]
+	played := c.played[k]
+	if played < len(indexes)-1 {
+		c.played[k] = played + 1
+	} else {
+		played = len(indexes) - 1
+	}
+	interaction := c.cassette.Interactions[indexes[played]]
+	response := &jsonrpc.RPCResponse{JSONRPC: "2.0", Error: interaction.Error}
+	if len(interaction.Result) > 0 {
+		decoder := json.NewDecoder(bytes.NewReader(interaction.Result))
+		decoder.UseNumber()
+		if err := decoder.Decode(&response.Result); err != nil {
+			return nil, fmt.Errorf("cassette %v: %w", k, err)
+		}
+	}
+	return response, nil
+}
+
+func (c *Client) record(method string, params []byte, response *jsonrpc.RPCResponse) error {
+	interaction := Interaction{Method: method, Error: response.Error}
+	if string(params) != "null" {
+		interaction.Params = params
+	}
+	if response.Result != nil {
+		result, err := json.Marshal(response.Result)
+		if err != nil {
+			return err
+		}
+		interaction.Result = result
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	k := key(method, params)
+	c.recorded[k] = append(c.recorded[k], len(c.cassette.Interactions))
+	c.cassette.Interactions = append(c.cassette.Interactions, interaction)
+	return nil
+}
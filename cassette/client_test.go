@@ -0,0 +1,177 @@
+package cassette
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/massigerardi/alchemy-api/ethereum"
+	"github.com/massigerardi/alchemy-api/mocks"
+	"github.com/massigerardi/alchemy-api/utils"
+	"github.com/ybbus/jsonrpc/v3"
+)
+
+var (
+	eoa  = utils.MustParseAddress("0x549c660ce2b988f588769d6ad87be801695b2be3")
+	usdc = utils.MustParseAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+)
+
+// countingClient counts the calls and batches reaching the mock client.
+type countingClient struct {
+	jsonrpc.RPCClient
+	calls atomic.Int32
+}
+
+func (c *countingClient) Call(ctx context.Context, method string, params ...interface{}) (*jsonrpc.RPCResponse, error) {
+	c.calls.Add(1)
+	return c.RPCClient.Call(ctx, method, params...)
+}
+
+func (c *countingClient) CallBatch(ctx context.Context, requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error) {
+	c.calls.Add(1)
+	return c.RPCClient.CallBatch(ctx, requests)
+}
+
+func (c *countingClient) CallBatchRaw(ctx context.Context, requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error) {
+	return c.CallBatch(ctx, requests)
+}
+
+type recording struct {
+	blockNumber string
+	gasPrice    string
+	code        string
+	codes       ethereum.ContractCodeResponses
+}
+
+func exercise(t *testing.T, c *ethereum.EthClient) recording {
+	var r recording
+	var err error
+	if r.blockNumber, err = c.GetBlockNumber(); err != nil {
+		t.Fatalf("GetBlockNumber() error = %v", err)
+	}
+	gasPrice, err := c.GetGasPrice()
+	if err != nil {
+		t.Fatalf("GetGasPrice() error = %v", err)
+	}
+	r.gasPrice = gasPrice.String()
+	if r.code, err = c.GetContractCode(usdc); err != nil {
+		t.Fatalf("GetContractCode() error = %v", err)
+	}
+	if r.codes, err = c.GetContractCodeBatch([]utils.Address{eoa, usdc}); err != nil {
+		t.Fatalf("GetContractCodeBatch() error = %v", err)
+	}
+	return r
+}
+
+func TestClient_RecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "testdata", "cassette.json")
+	upstream := &countingClient{RPCClient: mocks.GetMockClient()}
+	recorder := New(&Cassette{}, Record, upstream)
+	recorded := exercise(t, ethereum.NewFromRPCClient(recorder))
+	if err := recorder.Cassette().Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	tape, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(tape.Interactions); got != 5 {
+		t.Errorf("Interactions got = %v, want 5", got)
+	}
+	replayed := exercise(t, ethereum.NewFromRPCClient(New(tape, Replay, nil)))
+	if !reflect.DeepEqual(replayed, recorded) {
+		t.Errorf("replayed = %+v, want %+v", replayed, recorded)
+	}
+	if got := upstream.calls.Load(); got != 4 {
+		t.Errorf("upstream calls got = %v, want 4", got)
+	}
+}
+
+func TestClient_Replay(t *testing.T) {
+	tape, err := Load("testdata/mainnet.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := ethereum.NewFromRPCClient(New(tape, Replay, nil))
+
+	// recorded twice, replayed in order and the last one repeated
+	for _, want := range []string{"0x10d4f", "0x10d50", "0x10d50"} {
+		if got, err := c.GetBlockNumber(); err != nil || got != want {
+			t.Errorf("GetBlockNumber() = %v, %v, want %v", got, err, want)
+		}
+	}
+	balance, err := c.GetBalance(usdc)
+	if err != nil || balance.String() != "1" {
+		t.Errorf("GetBalance() = %v, %v, want 1", balance, err)
+	}
+	if _, err := c.GetBalance(eoa); err == nil || err.Error() != "remote Error: -32000: header not found" {
+		t.Errorf("GetBalance() error = %v, want recorded error", err)
+	}
+	if _, err := c.GetGasPrice(); !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("GetGasPrice() error = %v, want %v", err, ErrNotRecorded)
+	}
+}
+
+func TestClient_ReplayOrRecord(t *testing.T) {
+	upstream := &countingClient{RPCClient: mocks.GetMockClient()}
+	tape := &Cassette{Interactions: []Interaction{{Method: ethereum.EthBlockNumber, Result: []byte(`"0x1"`)}}}
+	recorder := New(tape, ReplayOrRecord, upstream)
+	c := ethereum.NewFromRPCClient(recorder)
+
+	if got, _ := c.GetBlockNumber(); got != "0x1" {
+		t.Errorf("GetBlockNumber() got = %v, want 0x1", got)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := c.GetContractCodeBatch([]utils.Address{eoa, usdc}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := upstream.calls.Load(); got != 1 {
+		t.Errorf("upstream calls got = %v, want 1", got)
+	}
+	if got := len(recorder.Cassette().Interactions); got != 3 {
+		t.Errorf("Interactions got = %v, want 3", got)
+	}
+}
+
+func TestClient_ReplayBatchNotRecorded(t *testing.T) {
+	tape := &Cassette{Interactions: []Interaction{{Method: ethereum.EthGetCode, Params: []byte(`["0x549c660ce2B988F588769d6AD87BE801695b2be3","latest"]`), Result: []byte(`"0x"`)}}}
+	c := ethereum.NewFromRPCClient(New(tape, Replay, nil))
+	if _, err := c.GetContractCodeBatch([]utils.Address{eoa, usdc}); !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("GetContractCodeBatch() error = %v, want %v", err, ErrNotRecorded)
+	}
+}
+
+// reversedClient returns batch responses in reverse order, as a server may.
+type reversedClient struct {
+	jsonrpc.RPCClient
+}
+
+func (c reversedClient) CallBatch(ctx context.Context, requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error) {
+	responses, err := c.RPCClient.CallBatch(ctx, requests)
+	for i, j := 0, len(responses)-1; i < j; i, j = i+1, j-1 {
+		responses[i], responses[j] = responses[j], responses[i]
+	}
+	return responses, err
+}
+
+func TestClient_RecordBatchOutOfOrder(t *testing.T) {
+	upstream := mocks.New().
+		On(ethereum.EthGetBalance, eoa, ethereum.Latest).Return("0x1").
+		On(ethereum.EthGetBalance, usdc, ethereum.Latest).Return("0x2")
+	recorder := New(&Cassette{}, Record, reversedClient{upstream})
+	if _, err := ethereum.NewFromRPCClient(recorder).GetBalanceBatch([]utils.Address{eoa, usdc}); err != nil {
+		t.Fatal(err)
+	}
+
+	c := ethereum.NewFromRPCClient(New(recorder.Cassette(), Replay, nil))
+	for address, want := range map[utils.Address]int64{eoa: 1, usdc: 2} {
+		if got, err := c.GetBalance(address); err != nil || got.Int64() != want {
+			t.Errorf("GetBalance(%v) = %v, %v, want %v", address, got, err, want)
+		}
+	}
+}
@@ -0,0 +1,506 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/massigerardi/alchemy-api/ethereum"
+	"github.com/massigerardi/alchemy-api/utils"
+	"github.com/ybbus/jsonrpc/v3"
+)
+
+func runBlock(e *env, args []string) (*result, error) {
+	if len(args) > 1 {
+		return nil, errUsage
+	}
+	if len(args) == 0 {
+		number, err := e.client.GetBlockNumber()
+		if err != nil {
+			return nil, err
+		}
+		decimal, err := utils.DecodeQuantity(number)
+		if err != nil {
+			return nil, err
+		}
+		n := strconv.FormatUint(decimal, 10)
+		return &result{value: map[string]interface{}{"number": decimal, "hex": number}, columns: []string{"number"}, rows: [][]string{{n}}}, nil
+	}
+
+	var block *ethereum.Block
+	var err error
+	if strings.HasPrefix(args[0], "0x") && len(args[0]) == 2+2*utils.HashLength {
+		hash, parseErr := utils.ParseHash(args[0])
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		block, err = e.client.GetBlockByHash(hash)
+	} else {
+		number, parseErr := blockParam(args[0])
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		block, err = e.client.GetBlockByNumber(number)
+	}
+	if err != nil {
+		return nil, err
+	}
+	number, _ := utils.DecodeQuantity(block.Number)
+	timestamp, _ := utils.DecodeQuantity(block.Timestamp)
+	gasUsed, _ := utils.DecodeQuantity(block.GasUsed)
+	gasLimit, _ := utils.DecodeQuantity(block.GasLimit)
+	return &result{
+		value:   block,
+		columns: []string{"number", "hash", "parentHash", "timestamp", "gasUsed", "gasLimit", "transactions"},
+		rows: [][]string{{
+			strconv.FormatUint(number, 10),
+			block.Hash,
+			block.ParentHash,
+			time.Unix(int64(timestamp), 0).UTC().Format(time.RFC3339),
+			strconv.FormatUint(gasUsed, 10),
+			strconv.FormatUint(gasLimit, 10),
+			strconv.Itoa(len(block.Transactions)),
+		}},
+	}, nil
+}
+
+type balanceRow struct {
+	Address utils.Address `json:"address"`
+	Wei     string        `json:"wei,omitempty"`
+	Ether   string        `json:"ether,omitempty"`
+	Error   string        `json:"error,omitempty"`
+}
+
+func runBalance(e *env, args []string) (*result, error) {
+	flags := e.newFlagSet("balance")
+	block := flags.String("block", ethereum.Latest, "block number or tag")
+	file := flags.String("file", "", "file of addresses, - for standard input")
+	batchSize := flags.Int("batch-size", defaultBatchSize, "addresses per batch")
+	if err := parse(flags, args); err != nil {
+		return nil, err
+	}
+	if *batchSize <= 0 {
+		return nil, fmt.Errorf("invalid batch size %v", *batchSize)
+	}
+	addresses, err := e.addresses(flags.Args(), *file)
+	if err != nil {
+		return nil, err
+	}
+	number, err := blockParam(*block)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []balanceRow
+	if len(addresses) == 1 && *file == "" {
+		balance, err := e.client.GetBalance(addresses[0], number)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, balanceRow{Address: addresses[0], Wei: balance.String(), Ether: utils.FormatEther(balance)})
+	} else {
+		for _, chunk := range chunks(addresses, *batchSize) {
+			responses, err := e.client.GetBalanceBatch(chunk, number)
+			if err != nil {
+				return nil, err
+			}
+			for _, response := range responses {
+				row := balanceRow{Address: response.Address}
+				if response.Error != nil {
+					row.Error = response.Error.Error()
+				} else {
+					row.Wei = response.Amount.String()
+					row.Ether = utils.FormatEther(response.Amount)
+				}
+				rows = append(rows, row)
+			}
+		}
+	}
+
+	r := &result{value: rows, columns: []string{"address", "wei", "ether", "error"}}
+	for _, row := range rows {
+		r.rows = append(r.rows, []string{row.Address.Hex(), row.Wei, row.Ether, row.Error})
+	}
+	if len(rows) == 1 && rows[0].Error == "" {
+		r.value = rows[0]
+		r.columns = r.columns[:3]
+		r.rows[0] = r.rows[0][:3]
+	}
+	return r, nil
+}
+
+type codeRow struct {
+	Address  utils.Address `json:"address"`
+	Contract bool          `json:"contract"`
+	Code     string        `json:"code,omitempty"`
+	Error    string        `json:"error,omitempty"`
+}
+
+func runCode(e *env, args []string) (*result, error) {
+	flags := e.newFlagSet("code")
+	block := flags.String("block", ethereum.Latest, "block number or tag")
+	file := flags.String("file", "", "file of addresses, - for standard input")
+	batchSize := flags.Int("batch-size", defaultBatchSize, "addresses per batch")
+	if err := parse(flags, args); err != nil {
+		return nil, err
+	}
+	if *batchSize <= 0 {
+		return nil, fmt.Errorf("invalid batch size %v", *batchSize)
+	}
+	addresses, err := e.addresses(flags.Args(), *file)
+	if err != nil {
+		return nil, err
+	}
+	number, err := blockParam(*block)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []codeRow
+	if len(addresses) == 1 && *file == "" {
+		code, err := e.client.GetContractCode(addresses[0], number)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, codeRow{Address: addresses[0], Contract: isContract(code), Code: code})
+	} else {
+		for _, chunk := range chunks(addresses, *batchSize) {
+			responses, err := e.client.GetContractCodeBatch(chunk, number)
+			if err != nil {
+				return nil, err
+			}
+			for _, response := range responses {
+				row := codeRow{Address: response.Address, Contract: isContract(response.Code), Code: response.Code}
+				if response.Error != nil {
+					row = codeRow{Address: response.Address, Error: response.Error.Error()}
+				}
+				rows = append(rows, row)
+			}
+		}
+	}
+
+	r := &result{value: rows, columns: []string{"address", "contract", "bytes", "error"}}
+	for _, row := range rows {
+		size := ""
+		if row.Error == "" {
+			size = strconv.Itoa(len(strings.TrimPrefix(row.Code, "0x")) / 2)
+		}
+		r.rows = append(r.rows, []string{row.Address.Hex(), strconv.FormatBool(row.Contract), size, row.Error})
+	}
+	if len(rows) == 1 && rows[0].Error == "" {
+		r.value = rows[0]
+		r.columns = r.columns[:3]
+		r.rows[0] = r.rows[0][:3]
+	}
+	return r, nil
+}
+
+func isContract(code string) bool {
+	return code != "" && code != "0x"
+}
+
+// listFlag collects the values of a repeated flag.
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func runLogs(e *env, args []string) (*result, error) {
+	flags := e.newFlagSet("logs")
+	var addressFlags, topicFlags listFlag
+	flags.Var(&addressFlags, "address", "emitting contract, repeated or comma separated")
+	from := flags.String("from", "", "first block number or tag")
+	to := flags.String("to", "", "last block number or tag")
+	blockHash := flags.String("blockhash", "", "hash of the only block to search, instead of -from and -to")
+	flags.Var(&topicFlags, "topic", "topic position, repeated in order: a topic or event signature, alternatives separated by |, * for any")
+	if err := parse(flags, args); err != nil {
+		return nil, err
+	}
+	if flags.NArg() > 0 {
+		return nil, errUsage
+	}
+
+	var addresses []utils.Address
+	for _, value := range addressFlags {
+		for _, field := range strings.Split(value, ",") {
+			address, err := utils.ParseAddress(strings.TrimSpace(field))
+			if err != nil {
+				return nil, err
+			}
+			addresses = append(addresses, address)
+		}
+	}
+	topics := ethereum.Topics()
+	for _, value := range topicFlags {
+		if value == "" || value == "*" {
+			topics = topics.Any()
+			continue
+		}
+		var alternatives []utils.Hash
+		for _, field := range strings.Split(value, "|") {
+			topic, err := parseTopic(strings.TrimSpace(field))
+			if err != nil {
+				return nil, err
+			}
+			alternatives = append(alternatives, topic)
+		}
+		topics = topics.OneOf(alternatives...)
+	}
+
+	if *blockHash != "" && (*from != "" || *to != "") {
+		return nil, fmt.Errorf("-blockhash cannot be combined with -from or -to")
+	}
+	var request ethereum.LogRequest
+	if *blockHash != "" {
+		hash, err := utils.ParseHash(*blockHash)
+		if err != nil {
+			return nil, err
+		}
+		request = ethereum.NewBlockHashLogRequest(addresses, hash, topics)
+	} else {
+		request = ethereum.LogRequest{Address: addresses, Topics: topics}
+		var err error
+		if *from != "" {
+			if request.FromBlock, err = blockParam(*from); err != nil {
+				return nil, err
+			}
+		}
+		if *to != "" {
+			if request.ToBlock, err = blockParam(*to); err != nil {
+				return nil, err
+			}
+		}
+	}
+	logs, err := e.client.FilterLogs(request)
+	if err != nil {
+		return nil, err
+	}
+	if logs == nil {
+		logs = ethereum.LogsResponses{}
+	}
+	r := &result{value: logs, columns: []string{"block", "index", "address", "transaction", "topics", "data"}}
+	for _, log := range logs {
+		block, _ := utils.DecodeQuantity(log.BlockNumber)
+		index, _ := utils.DecodeQuantity(log.LogIndex)
+		r.rows = append(r.rows, []string{
+			strconv.FormatUint(block, 10),
+			strconv.FormatUint(index, 10),
+			log.Address,
+			log.TransactionHash,
+			strings.Join(log.Topics, " "),
+			log.Data,
+		})
+	}
+	return r, nil
+}
+
+// parseTopic accepts a 32 byte topic or an event signature such as "Transfer(address,address,uint256)".
+func parseTopic(value string) (utils.Hash, error) {
+	if strings.Contains(value, "(") {
+		return utils.EventTopic(value), nil
+	}
+	return utils.ParseHash(value)
+}
+
+func runGas(e *env, args []string) (*result, error) {
+	if len(args) > 0 {
+		return nil, errUsage
+	}
+	price, err := e.client.GetGasPrice()
+	if err != nil {
+		return nil, err
+	}
+	gwei := utils.FormatGwei(price)
+	return &result{
+		value:   map[string]string{"wei": price.String(), "gwei": gwei},
+		columns: []string{"wei", "gwei"},
+		rows:    [][]string{{price.String(), gwei}},
+	}, nil
+}
+
+func runTx(e *env, args []string) (*result, error) {
+	return e.getByHash(ethereum.EthGetTransaction, "transaction", args)
+}
+
+func runReceipt(e *env, args []string) (*result, error) {
+	return e.getByHash(ethereum.EthGetReceipt, "receipt", args)
+}
+
+func (e *env) getByHash(method string, name string, args []string) (*result, error) {
+	if len(args) != 1 {
+		return nil, errUsage
+	}
+	hash, err := utils.ParseHash(args[0])
+	if err != nil {
+		return nil, err
+	}
+	response, err := e.rpc.Call(context.Background(), method, hash)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != nil {
+		return nil, fmt.Errorf("remote Error: %v", response.Error.Error())
+	}
+	if response.Result == nil {
+		return nil, fmt.Errorf("%v %v not found", name, hash)
+	}
+	var object map[string]interface{}
+	if err := response.GetObject(&object); err != nil {
+		return nil, err
+	}
+	return objectResult(object), nil
+}
+
+func runCall(e *env, args []string) (*result, error) {
+	flags := e.newFlagSet("call")
+	block := flags.String("block", ethereum.Latest, "block number or tag")
+	signature := flags.String("sig", "", "function signature without arguments, e.g. \"decimals()\", instead of data")
+	if err := parse(flags, args); err != nil {
+		return nil, err
+	}
+	if flags.NArg() < 1 || flags.NArg() > 2 || (flags.NArg() == 2) == (*signature != "") {
+		return nil, errUsage
+	}
+	to, err := utils.ParseAddress(flags.Arg(0))
+	if err != nil {
+		return nil, err
+	}
+	data := flags.Arg(1)
+	if *signature != "" {
+		data = utils.SelectorHex(*signature)
+	}
+	number, err := blockParam(*block)
+	if err != nil {
+		return nil, err
+	}
+	output, err := e.client.Call(ethereum.NewCallRequest(to, data), number)
+	if err != nil {
+		return nil, err
+	}
+	return &result{value: output, columns: []string{"result"}, rows: [][]string{{output}}}, nil
+}
+
+func runRaw(e *env, args []string) (*result, error) {
+	if len(args) == 0 {
+		return nil, errUsage
+	}
+	request := &jsonrpc.RPCRequest{Method: args[0], JSONRPC: "2.0"}
+	if len(args) > 1 {
+		params := make([]interface{}, len(args)-1)
+		for i, arg := range args[1:] {
+			params[i] = rawParam(arg)
+		}
+		request.Params = params
+	}
+	response, err := e.rpc.CallRaw(context.Background(), request)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != nil {
+		return nil, fmt.Errorf("remote Error: %v", response.Error.Error())
+	}
+	return &result{value: response.Result}, nil
+}
+
+// rawParam decodes arg as JSON, or else uses it as a string, so that
+// addresses and tags need no quotes.
+func rawParam(arg string) interface{} {
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(arg), &decoded); err == nil {
+		return decoded
+	}
+	return arg
+}
+
+// blockParam converts a decimal block number to a quantity, passing tags and quantities through.
+func blockParam(block string) (string, error) {
+	switch block {
+	case ethereum.Latest, ethereum.Pending, ethereum.Safe, ethereum.Earliest, "finalized":
+		return block, nil
+	}
+	if strings.HasPrefix(block, "0x") {
+		if _, err := utils.DecodeQuantity(block); err != nil {
+			return "", fmt.Errorf("invalid block %q: %w", block, err)
+		}
+		return block, nil
+	}
+	number, err := strconv.ParseUint(block, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid block %q", block)
+	}
+	return utils.EncodeQuantity(number), nil
+}
+
+// addresses parses the addresses given as arguments and in file.
+func (e *env) addresses(args []string, file string) ([]utils.Address, error) {
+	var addresses []utils.Address
+	for _, arg := range args {
+		address, err := utils.ParseAddress(arg)
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, address)
+	}
+	if file != "" {
+		fromFile, err := e.readAddresses(file)
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, fromFile...)
+	}
+	if len(addresses) == 0 {
+		return nil, errUsage
+	}
+	return addresses, nil
+}
+
+func (e *env) readAddresses(path string) ([]utils.Address, error) {
+	var r io.Reader = e.stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	var addresses []utils.Address
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		address, err := utils.ParseAddress(text)
+		if err != nil {
+			return nil, fmt.Errorf("%v:%v: %w", path, line, err)
+		}
+		addresses = append(addresses, address)
+	}
+	return addresses, scanner.Err()
+}
+
+// defaultBatchSize bounds the addresses sent in one batch, which providers limit.
+const defaultBatchSize = ethereum.MaxBatchCalls
+
+// chunks splits addresses into slices of at most size addresses.
+func chunks(addresses []utils.Address, size int) [][]utils.Address {
+	var split [][]utils.Address
+	for len(addresses) > size {
+		split = append(split, addresses[:size])
+		addresses = addresses[size:]
+	}
+	return append(split, addresses)
+}
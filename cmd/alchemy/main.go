@@ -0,0 +1,173 @@
+// Command alchemy runs everyday queries against the Alchemy Ethereum API.
+//
+// Usage:
+//
+//	alchemy [-network name] [-url endpoint] [-o human|json|csv] command [flags] [args]
+//
+// The API key is read from ALCHEMY_API_KEY and the network, eth-mainnet by
+// default, from -network or ALCHEMY_NETWORK. -url sends the requests to
+// another JSON-RPC endpoint instead, e.g. a local node.
+//
+// Commands:
+//
+//	block [number|tag|hash]                 latest block number, or a block
+//	balance [-block b] [-file f] address... balances, batched for several addresses
+//	code [-block b] [-file f] address...    contract code, batched for several addresses
+//	logs [-address a] [-from b] [-to b] [-blockhash h] [-topic t]...
+//	gas                                     gas price
+//	tx hash                                 transaction
+//	receipt hash                            transaction receipt
+//	call [-block b] [-sig s] to [data]      eth_call
+//	raw method [param...]                   any JSON-RPC method, params given as JSON
+//
+// A -file of addresses holds one address per line, "-" reading standard
+// input. Empty lines and lines starting with # are skipped. balance and code
+// send several addresses in batches of -batch-size, 100 by default.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/massigerardi/alchemy-api/ethereum"
+	"github.com/ybbus/jsonrpc/v3"
+)
+
+// errUsage reports invalid arguments, already explained on stderr.
+var errUsage = errors.New("usage")
+
+type env struct {
+	client *ethereum.EthClient
+	rpc    jsonrpc.RPCClient
+	stdin  io.Reader
+	stderr io.Writer
+}
+
+type command struct {
+	usage string
+	run   func(e *env, args []string) (*result, error)
+}
+
+var commands = map[string]command{
+	"block":   {usage: "block [number|tag|hash]", run: runBlock},
+	"balance": {usage: "balance [-block b] [-file f] [-batch-size n] address...", run: runBalance},
+	"code":    {usage: "code [-block b] [-file f] [-batch-size n] address...", run: runCode},
+	"logs":    {usage: "logs [-address a] [-from b] [-to b] [-blockhash h] [-topic t]...", run: runLogs},
+	"gas":     {usage: "gas", run: runGas},
+	"tx":      {usage: "tx hash", run: runTx},
+	"receipt": {usage: "receipt hash", run: runReceipt},
+	"call":    {usage: "call [-block b] [-sig s] to [data]", run: runCall},
+	"raw":     {usage: "raw method [param...]", run: runRaw},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr, os.Getenv))
+}
+
+// run executes the command line args and returns the exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer, getenv func(string) string) int {
+	flags := flag.NewFlagSet("alchemy", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	network := flags.String("network", "", "Alchemy network, e.g. eth-sepolia (default $ALCHEMY_NETWORK or eth-mainnet)")
+	url := flags.String("url", "", "JSON-RPC endpoint to use instead of Alchemy")
+	format := flags.String("o", "human", "output format: human, json or csv")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: alchemy [-network name] [-url endpoint] [-o human|json|csv] command [flags] [args]")
+		flags.PrintDefaults()
+		fmt.Fprintln(stderr, "commands:")
+		names := make([]string, 0, len(commands))
+		for name := range commands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(stderr, "  %v\n", commands[name].usage)
+		}
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+	cmd, ok := commands[flags.Arg(0)]
+	if !ok {
+		fmt.Fprintf(stderr, "alchemy: unknown command %q\n", flags.Arg(0))
+		flags.Usage()
+		return 2
+	}
+	printer, ok := printers[*format]
+	if !ok {
+		fmt.Fprintf(stderr, "alchemy: unknown output format %q\n", *format)
+		return 2
+	}
+
+	rpc, err := newRPCClient(*url, *network, getenv)
+	if err != nil {
+		fmt.Fprintf(stderr, "alchemy: %v\n", err)
+		return 2
+	}
+	e := &env{client: ethereum.NewFromRPCClient(rpc), rpc: rpc, stdin: stdin, stderr: stderr}
+	result, err := cmd.run(e, flags.Args()[1:])
+	if errors.Is(err, errUsage) {
+		fmt.Fprintf(stderr, "usage: alchemy %v\n", cmd.usage)
+		return 2
+	}
+	if err == nil {
+		err = printer(stdout, result)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "alchemy: %v\n", redactor(getenv).Replace(err.Error()))
+		return 1
+	}
+	return 0
+}
+
+// redactor hides the API key in error messages: jsonrpc transport errors
+// carry the endpoint URL, whose path holds the key.
+func redactor(getenv func(string) string) *strings.Replacer {
+	apiKey := strings.TrimSpace(getenv("ALCHEMY_API_KEY"))
+	if apiKey == "" {
+		return strings.NewReplacer()
+	}
+	return strings.NewReplacer(apiKey, "[REDACTED]")
+}
+
+func newRPCClient(url string, network string, getenv func(string) string) (jsonrpc.RPCClient, error) {
+	if url != "" {
+		return jsonrpc.NewClient(url), nil
+	}
+	apiKey := strings.TrimSpace(getenv("ALCHEMY_API_KEY"))
+	if apiKey == "" {
+		return nil, errors.New("ALCHEMY_API_KEY is not set")
+	}
+	if network == "" {
+		network = getenv("ALCHEMY_NETWORK")
+	}
+	if network == "" {
+		network = string(ethereum.Mainnet)
+	}
+	return ethereum.NewNetworkRPCClient(ethereum.Network(network), apiKey), nil
+}
+
+// newFlagSet returns the flag set of a command, printing its errors to stderr.
+func (e *env) newFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(e.stderr)
+	flags.Usage = func() {}
+	return flags
+}
+
+// parse parses the flags of a command, mapping errors to errUsage.
+func parse(flags *flag.FlagSet, args []string) error {
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/massigerardi/alchemy-api/alchemytest"
+	"github.com/massigerardi/alchemy-api/utils"
+)
+
+var (
+	holder = utils.MustParseAddress("0x549c660ce2b988f588769d6ad87be801695b2be3")
+	usdc   = utils.MustParseAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+)
+
+func newTestServer(t *testing.T) *alchemytest.Server {
+	server := alchemytest.NewServer()
+	t.Cleanup(server.Close)
+	server.MineBlocks(20)
+	server.SetBalance(holder, big.NewInt(1_500_000_000_000_000_000))
+	server.SetCode(usdc, "0x60806040")
+	server.SetCallResult(usdc, utils.SelectorHex("decimals()"), "0x0000000000000000000000000000000000000000000000000000000000000006")
+	server.AddLog(5, usdc, "0x01", utils.EventTopic("Transfer(address,address,uint256)"))
+	server.AddLog(6, usdc, "0x02", utils.EventTopic("Approval(address,address,uint256)"))
+	return server
+}
+
+func TestRun(t *testing.T) {
+	server := newTestServer(t)
+	addresses := filepath.Join(t.TempDir(), "addresses.txt")
+	if err := os.WriteFile(addresses, []byte("# holders\n"+holder.Hex()+"\n\n"+usdc.Hex()+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		stdin    string
+		wantCode int
+		want     string
+	}{
+		{name: "Block Number", args: []string{"block"}, want: "20\n"},
+		{name: "Block Number JSON", args: []string{"-o", "json", "block"}, want: "{\n  \"hex\": \"0x14\",\n  \"number\": 20\n}\n"},
+		{name: "Block", args: []string{"block", "3"}, want: "timestamp:     2023-11-14T22:13:56Z"},
+		{name: "Balance", args: []string{"balance", holder.Hex()}, want: "ether:    1.5\n"},
+		{name: "Balance CSV", args: []string{"-o", "csv", "balance", "-file", addresses}, want: "address,wei,ether,error\n" +
+			holder.Hex() + ",1500000000000000000,1.5,\n" + usdc.Hex() + ",0,0,\n"},
+		{name: "Balance Stdin", args: []string{"-o", "csv", "balance", "-file", "-"}, stdin: usdc.Hex() + "\n", want: "address,wei,ether\n" + usdc.Hex() + ",0,0\n"},
+		{name: "Code", args: []string{"code", usdc.Hex()}, want: "bytes:     4\n"},
+		{name: "Code Batch", args: []string{"code", holder.Hex(), usdc.Hex()}, want: "ADDRESS"},
+		{name: "Logs", args: []string{"-o", "csv", "logs", "-from", "0", "-topic", "Transfer(address,address,uint256)|Approval(address,address,uint256)"}, want: "block,index,address,transaction,topics,data\n5,0,"},
+		{name: "Logs Filtered", args: []string{"-o", "csv", "logs", "-from", "6", "-to", "latest", "-address", usdc.Hex()}, want: "\n6,0,"},
+		{name: "Gas", args: []string{"gas"}, want: "gwei:  20\n"},
+		{name: "Call", args: []string{"call", "-sig", "decimals()", usdc.Hex()}, want: "0x0000000000000000000000000000000000000000000000000000000000000006\n"},
+		{name: "Raw", args: []string{"raw", "eth_getBalance", holder.Hex(), "latest"}, want: "\"0x14d1120d7b160000\"\n"},
+		{name: "Raw CSV", args: []string{"-o", "csv", "raw", "eth_chainId"}, wantCode: 1},
+		{name: "Transaction Not Available", args: []string{"tx", "0x8243343df08b9751f5ca0c5f8c9c0460d8a9b6351066fae0acbd4d3e776de8bb"}, wantCode: 1},
+		{name: "Invalid Address", args: []string{"balance", "0x123"}, wantCode: 1},
+		{name: "Missing Address", args: []string{"balance"}, wantCode: 2},
+		{name: "Invalid Batch Size", args: []string{"code", "-batch-size", "0", holder.Hex(), usdc.Hex()}, wantCode: 1},
+		{name: "Unknown Command", args: []string{"blocks"}, wantCode: 2},
+		{name: "Unknown Format", args: []string{"-o", "xml", "block"}, wantCode: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			args := append([]string{"-url", server.URL}, tt.args...)
+			code := run(args, strings.NewReader(tt.stdin), &stdout, &stderr, func(string) string { return "" })
+			if code != tt.wantCode {
+				t.Fatalf("run() = %v, want %v, stderr: %v", code, tt.wantCode, stderr.String())
+			}
+			if !strings.Contains(stdout.String(), tt.want) {
+				t.Errorf("run() output = %q, want it to contain %q", stdout.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRun_Chunks(t *testing.T) {
+	server := newTestServer(t)
+	var lines []string
+	for i := 0; i < 5; i++ {
+		lines = append(lines, holder.Hex(), usdc.Hex())
+	}
+
+	for _, command := range []string{"balance", "code"} {
+		var stdout, stderr bytes.Buffer
+		args := []string{"-url", server.URL, "-o", "csv", command, "-batch-size", "2", "-file", "-"}
+		if code := run(args, strings.NewReader(strings.Join(lines, "\n")), &stdout, &stderr, func(string) string { return "" }); code != 0 {
+			t.Fatalf("run(%v) = %v, stderr: %v", command, code, stderr.String())
+		}
+		rows := strings.Split(strings.TrimSpace(stdout.String()), "\n")[1:]
+		if len(rows) != len(lines) {
+			t.Fatalf("run(%v) got %v rows, want %v", command, len(rows), len(lines))
+		}
+		for i, row := range rows {
+			if !strings.HasPrefix(row, lines[i]+",") {
+				t.Errorf("run(%v) row %v = %v, want %v", command, i, row, lines[i])
+			}
+		}
+	}
+}
+
+func TestRun_RedactsAPIKey(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	getenv := func(name string) string {
+		if name == "ALCHEMY_API_KEY" {
+			return "secret-key"
+		}
+		return ""
+	}
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-url", server.URL + "/v2/secret-key", "block"}, nil, &stdout, &stderr, getenv); code != 1 {
+		t.Fatalf("run() = %v, want 1", code)
+	}
+	if strings.Contains(stderr.String(), "secret-key") || !strings.Contains(stderr.String(), "/v2/[REDACTED]") {
+		t.Errorf("run() stderr = %q, want the API key redacted", stderr.String())
+	}
+}
+
+func TestRun_APIKey(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"block"}, nil, &stdout, &stderr, func(string) string { return "" }); code != 2 {
+		t.Errorf("run() = %v, want 2", code)
+	}
+	if !strings.Contains(stderr.String(), "ALCHEMY_API_KEY") {
+		t.Errorf("run() stderr = %q, want a missing key message", stderr.String())
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// result is the output of a command: value is printed as JSON, the table
+// of columns and rows in the human and CSV formats.
+type result struct {
+	value   interface{}
+	columns []string
+	rows    [][]string
+}
+
+var printers = map[string]func(w io.Writer, r *result) error{
+	"human": printHuman,
+	"json":  printJSON,
+	"csv":   printCSV,
+}
+
+// printHuman prints a single value alone, a single row as one "column: value"
+// line per column and several rows as an aligned table. Results without a
+// table are printed as indented JSON.
+func printHuman(w io.Writer, r *result) error {
+	switch {
+	case r.columns == nil:
+		return printJSON(w, r)
+	case len(r.rows) == 1 && len(r.columns) == 1:
+		_, err := fmt.Fprintln(w, r.rows[0][0])
+		return err
+	case len(r.rows) == 1:
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		for i, column := range r.columns {
+			fmt.Fprintf(tw, "%v:\t%v\n", column, r.rows[0][i])
+		}
+		return tw.Flush()
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.ToUpper(strings.Join(r.columns, "\t")))
+	for _, row := range r.rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+func printJSON(w io.Writer, r *result) error {
+	data, err := json.MarshalIndent(r.value, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
+
+func printCSV(w io.Writer, r *result) error {
+	if r.columns == nil {
+		return errors.New("csv output is not available for this command")
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(r.columns); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(r.rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// objectResult tabulates a JSON object as a single row with a column per
+// top level field, nested values being JSON encoded.
+func objectResult(object map[string]interface{}) *result {
+	fields := make([]string, 0, len(object))
+	for field := range object {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	row := make([]string, len(fields))
+	for i, field := range fields {
+		row[i] = formatValue(object[field])
+	}
+	return &result{value: object, columns: fields, rows: [][]string{row}}
+}
+
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
@@ -0,0 +1,197 @@
+// Package coalesce provides a jsonrpc.RPCClient that lets identical
+// concurrent calls share a single in-flight request, and optionally
+// memoizes head-dependent results such as eth_blockNumber for a short time.
+//
+//	client := ethereum.NewFromRPCClient(coalesce.New(ethereum.NewRPCClient(apiKey), coalesce.Config{TTL: time.Second}))
+package coalesce
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/massigerardi/alchemy-api/middleware"
+	"github.com/ybbus/jsonrpc/v3"
+)
+
+// DefaultMemoized are the methods memoized when Config.TTL is set and Config.Methods is empty.
+var DefaultMemoized = []string{"eth_blockNumber", "eth_gasPrice", "eth_maxPriorityFeePerGas", "eth_chainId"}
+
+// DefaultTimeout bounds a shared request when Config.Timeout is zero.
+const DefaultTimeout = 30 * time.Second
+
+type Config struct {
+	// Timeout bounds a shared request, which runs detached from the context
+	// of its callers, DefaultTimeout if zero.
+	Timeout time.Duration
+	// TTL is how long successful results of memoized methods are reused. Zero disables memoization.
+	TTL time.Duration
+	// Methods lists the memoized methods, DefaultMemoized if empty.
+	Methods []string
+}
+
+// Stats counts calls that started a request (Calls), joined an in-flight
+// request (Shared) or were answered from a memoized result (Memoized).
+type Stats struct {
+	Calls    uint64
+	Shared   uint64
+	Memoized uint64
+}
+
+type flight struct {
+	done     chan struct{}
+	response *jsonrpc.RPCResponse
+	err      error
+}
+
+type memo struct {
+	response *jsonrpc.RPCResponse
+	expires  time.Time
+}
+
+type Client struct {
+	next     jsonrpc.RPCClient
+	timeout  time.Duration
+	ttl      time.Duration
+	memoized map[string]bool
+
+	mu      sync.Mutex
+	flights map[string]*flight
+	memos   map[string]memo
+
+	calls    atomic.Uint64
+	shared   atomic.Uint64
+	memoHits atomic.Uint64
+}
+
+func New(next jsonrpc.RPCClient, config Config) *Client {
+	methods := config.Methods
+	if len(methods) == 0 {
+		methods = DefaultMemoized
+	}
+	memoized := make(map[string]bool, len(methods))
+	for _, method := range methods {
+		memoized[method] = true
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	return &Client{
+		next:     next,
+		timeout:  config.Timeout,
+		ttl:      config.TTL,
+		memoized: memoized,
+		flights:  make(map[string]*flight),
+		memos:    make(map[string]memo),
+	}
+}
+
+// Middleware lets identical concurrent calls share one request like New.
+func Middleware(config Config) middleware.Middleware {
+	return func(next middleware.Invoker) middleware.Invoker {
+		return New(middleware.Client(next), config)
+	}
+}
+
+func (c *Client) Stats() Stats {
+	return Stats{Calls: c.calls.Load(), Shared: c.shared.Load(), Memoized: c.memoHits.Load()}
+}
+
+func (c *Client) Call(ctx context.Context, method string, params ...interface{}) (*jsonrpc.RPCResponse, error) {
+	return c.do(ctx, method, jsonrpc.Params(params...), func(ctx context.Context) (*jsonrpc.RPCResponse, error) {
+		return c.next.Call(ctx, method, params...)
+	})
+}
+
+func (c *Client) CallRaw(ctx context.Context, request *jsonrpc.RPCRequest) (*jsonrpc.RPCResponse, error) {
+	return c.do(ctx, request.Method, request.Params, func(ctx context.Context) (*jsonrpc.RPCResponse, error) {
+		return c.next.CallRaw(ctx, request)
+	})
+}
+
+func (c *Client) CallFor(ctx context.Context, out interface{}, method string, params ...interface{}) error {
+	response, err := c.Call(ctx, method, params...)
+	if err != nil {
+		return err
+	}
+	if response.Error != nil {
+		return response.Error
+	}
+	return response.GetObject(out)
+}
+
+// CallBatch is passed through unchanged: batches are already a single request.
+func (c *Client) CallBatch(ctx context.Context, requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error) {
+	return c.next.CallBatch(ctx, requests)
+}
+
+func (c *Client) CallBatchRaw(ctx context.Context, requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error) {
+	return c.next.CallBatchRaw(ctx, requests)
+}
+
+// do runs fetch once for all concurrent callers with the same method and
+// params. The request runs on a context detached from the caller that
+// started it, so that its cancellation does not fail the others; every
+// caller stops waiting when its own context is done.
+func (c *Client) do(ctx context.Context, method string, params interface{}, fetch func(context.Context) (*jsonrpc.RPCResponse, error)) (*jsonrpc.RPCResponse, error) {
+	encoded, err := json.Marshal(params)
+	if err != nil {
+		c.calls.Add(1)
+		return fetch(ctx)
+	}
+	key := method + " " + string(encoded)
+	memoize := c.ttl > 0 && c.memoized[method]
+
+	c.mu.Lock()
+	if memoize {
+		if m, ok := c.memos[key]; ok && time.Now().Before(m.expires) {
+			c.mu.Unlock()
+			c.memoHits.Add(1)
+			return copyResponse(m.response), nil
+		}
+	}
+	f, ok := c.flights[key]
+	if ok {
+		c.shared.Add(1)
+	} else {
+		f = &flight{done: make(chan struct{})}
+		c.flights[key] = f
+		c.calls.Add(1)
+		go c.fly(context.WithoutCancel(ctx), key, memoize, f, fetch)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-f.done:
+		return copyResponse(f.response), f.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// fly runs the shared request of flight f.
+func (c *Client) fly(ctx context.Context, key string, memoize bool, f *flight, fetch func(context.Context) (*jsonrpc.RPCResponse, error)) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	f.response, f.err = fetch(ctx)
+
+	c.mu.Lock()
+	delete(c.flights, key)
+	if memoize && f.err == nil && f.response != nil && f.response.Error == nil {
+		c.memos[key] = memo{response: f.response, expires: time.Now().Add(c.ttl)}
+	}
+	c.mu.Unlock()
+	close(f.done)
+}
+
+// copyResponse gives every caller its own response, so that one caller
+// changing fields such as ID does not affect the others.
+func copyResponse(response *jsonrpc.RPCResponse) *jsonrpc.RPCResponse {
+	if response == nil {
+		return nil
+	}
+	copied := *response
+	return &copied
+}
@@ -0,0 +1,201 @@
+package coalesce
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/massigerardi/alchemy-api/ethereum"
+	"github.com/massigerardi/alchemy-api/mocks"
+	"github.com/massigerardi/alchemy-api/utils"
+	"github.com/ybbus/jsonrpc/v3"
+)
+
+// gatedClient holds every call until release is closed, or its context is done, and counts the calls reaching the mock client.
+type gatedClient struct {
+	jsonrpc.RPCClient
+	release chan struct{}
+	started chan struct{}
+	calls   atomic.Int32
+}
+
+func newGatedClient() *gatedClient {
+	return &gatedClient{RPCClient: mocks.GetMockClient(), release: make(chan struct{}), started: make(chan struct{}, 100)}
+}
+
+func (g *gatedClient) Call(ctx context.Context, method string, params ...interface{}) (*jsonrpc.RPCResponse, error) {
+	g.calls.Add(1)
+	g.started <- struct{}{}
+	select {
+	case <-g.release:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return g.RPCClient.Call(ctx, method, params...)
+}
+
+func TestClient_Coalesces(t *testing.T) {
+	upstream := newGatedClient()
+	coalescing := New(upstream, Config{})
+	c := ethereum.NewFromRPCClient(coalescing)
+
+	const callers = 20
+	var wg sync.WaitGroup
+	results := make([]string, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = c.GetBlockNumber()
+		}(i)
+	}
+	<-upstream.started
+	// let the other callers join the flight before releasing it
+	for coalescing.Stats().Shared < callers-1 {
+		time.Sleep(time.Millisecond)
+	}
+	close(upstream.release)
+	wg.Wait()
+
+	if got := upstream.calls.Load(); got != 1 {
+		t.Errorf("upstream calls = %v, want 1", got)
+	}
+	for i, result := range results {
+		if result != "0x1234" {
+			t.Errorf("caller %v got %v", i, result)
+		}
+	}
+	if stats := coalescing.Stats(); stats.Calls != 1 || stats.Shared != callers-1 {
+		t.Errorf("Stats() = %+v", stats)
+	}
+}
+
+func TestClient_DifferentParams(t *testing.T) {
+	upstream := newGatedClient()
+	close(upstream.release)
+	c := ethereum.NewFromRPCClient(New(upstream, Config{}))
+	for _, address := range []string{"0x549c660ce2b988f588769d6ad87be801695b2be3", "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"} {
+		if _, err := c.GetContractCode(utils.MustParseAddress(address)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := upstream.calls.Load(); got != 2 {
+		t.Errorf("upstream calls = %v, want 2", got)
+	}
+}
+
+func TestClient_Memoize(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    Config
+		wantCalls int32
+	}{
+		{name: "Disabled", config: Config{}, wantCalls: 3},
+		{name: "Default Methods", config: Config{TTL: time.Minute}, wantCalls: 1},
+		{name: "Other Methods", config: Config{TTL: time.Minute, Methods: []string{"eth_blockNumber"}}, wantCalls: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upstream := newGatedClient()
+			close(upstream.release)
+			c := ethereum.NewFromRPCClient(New(upstream, tt.config))
+			for i := 0; i < 3; i++ {
+				got, err := c.GetGasPrice()
+				if err != nil || got.Int64() != 11831650940 {
+					t.Fatalf("GetGasPrice() = %v, %v", got, err)
+				}
+			}
+			if got := upstream.calls.Load(); got != tt.wantCalls {
+				t.Errorf("upstream calls = %v, want %v", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestClient_MemoizeExpires(t *testing.T) {
+	upstream := newGatedClient()
+	close(upstream.release)
+	c := ethereum.NewFromRPCClient(New(upstream, Config{TTL: 10 * time.Millisecond}))
+	_, _ = c.GetBlockNumber()
+	time.Sleep(20 * time.Millisecond)
+	_, _ = c.GetBlockNumber()
+	if got := upstream.calls.Load(); got != 2 {
+		t.Errorf("upstream calls = %v, want 2", got)
+	}
+}
+
+func TestClient_ErrorsNotMemoized(t *testing.T) {
+	upstream := &gatedClient{RPCClient: mocks.GetMockClient(true), release: make(chan struct{}), started: make(chan struct{}, 100)}
+	close(upstream.release)
+	c := ethereum.NewFromRPCClient(New(upstream, Config{TTL: time.Minute}))
+	for i := 0; i < 2; i++ {
+		if _, err := c.GetBlockNumber(); err == nil {
+			t.Errorf("GetBlockNumber() expected remote error")
+		}
+	}
+	if got := upstream.calls.Load(); got != 2 {
+		t.Errorf("upstream calls = %v, want 2", got)
+	}
+}
+
+func TestClient_WaiterCancel(t *testing.T) {
+	upstream := newGatedClient()
+	coalescing := New(upstream, Config{})
+	go func() {
+		_, _ = coalescing.Call(context.Background(), ethereum.EthBlockNumber)
+	}()
+	<-upstream.started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := coalescing.Call(ctx, ethereum.EthBlockNumber); !errors.Is(err, context.Canceled) {
+		t.Errorf("Call() error = %v, want %v", err, context.Canceled)
+	}
+	close(upstream.release)
+}
+
+func TestClient_StarterCancel(t *testing.T) {
+	upstream := newGatedClient()
+	coalescing := New(upstream, Config{})
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan error)
+	go func() {
+		_, err := coalescing.Call(ctx, ethereum.EthBlockNumber)
+		started <- err
+	}()
+	<-upstream.started
+
+	joined := make(chan string)
+	go func() {
+		response, err := coalescing.Call(context.Background(), ethereum.EthBlockNumber)
+		if err != nil {
+			joined <- err.Error()
+			return
+		}
+		result, _ := response.GetString()
+		joined <- result
+	}()
+	for coalescing.Stats().Shared < 1 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-started; !errors.Is(err, context.Canceled) {
+		t.Errorf("Call() error = %v, want %v for the canceled caller", err, context.Canceled)
+	}
+	close(upstream.release)
+	if got := <-joined; got != "0x1234" {
+		t.Errorf("Call() got = %v, want 0x1234 for the caller that joined", got)
+	}
+}
+
+func TestClient_Timeout(t *testing.T) {
+	upstream := newGatedClient()
+	defer close(upstream.release)
+	coalescing := New(upstream, Config{Timeout: time.Millisecond})
+	if _, err := coalescing.Call(context.Background(), ethereum.EthBlockNumber); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Call() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
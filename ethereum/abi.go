@@ -0,0 +1,87 @@
+package ethereum
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/massigerardi/alchemy-api/utils"
+)
+
+// abiWordSize is the size in bytes of an ABI encoded word.
+const abiWordSize = 32
+
+// callData builds 0x prefixed call data from a function signature and already encoded head words.
+func callData(signature string, words ...[]byte) string {
+	var builder strings.Builder
+	builder.WriteString(utils.SelectorHex(signature))
+	for _, word := range words {
+		builder.WriteString(hex.EncodeToString(word))
+	}
+	return builder.String()
+}
+
+// encodeUint returns n as a single ABI word.
+func encodeUint(n uint64) []byte {
+	word := make([]byte, abiWordSize)
+	new(big.Int).SetUint64(n).FillBytes(word)
+	return word
+}
+
+// encodeDynamicBytes returns the length prefixed, right padded tail encoding of a bytes or string value.
+func encodeDynamicBytes(data []byte) []byte {
+	padded := (len(data) + abiWordSize - 1) / abiWordSize * abiWordSize
+	encoded := make([]byte, abiWordSize+padded)
+	copy(encoded, encodeUint(uint64(len(data))))
+	copy(encoded[abiWordSize:], data)
+	return encoded
+}
+
+// decodeHexResult strips the 0x prefix of an eth_call result and decodes it.
+func decodeHexResult(result string) ([]byte, error) {
+	data, err := hex.DecodeString(strings.TrimPrefix(result, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid call result %v", result)
+	}
+	return data, nil
+}
+
+// decodeAddress decodes an eth_call result holding a single address.
+func decodeAddress(result string) (utils.Address, error) {
+	var address utils.Address
+	data, err := decodeHexResult(result)
+	if err != nil {
+		return address, err
+	}
+	if len(data) < abiWordSize {
+		return address, fmt.Errorf("short call result %v", result)
+	}
+	copy(address[:], data[abiWordSize-utils.AddressLength:abiWordSize])
+	return address, nil
+}
+
+// decodeString decodes an eth_call result holding a single dynamic string.
+func decodeString(result string) (string, error) {
+	data, err := decodeHexResult(result)
+	if err != nil {
+		return "", err
+	}
+	if len(data) == 0 {
+		return "", nil
+	}
+	if len(data) < abiWordSize {
+		return "", fmt.Errorf("short call result %v", result)
+	}
+	offset := new(big.Int).SetBytes(data[:abiWordSize])
+	if !offset.IsUint64() || offset.Uint64() > uint64(len(data)-abiWordSize) {
+		return "", fmt.Errorf("invalid string offset in call result %v", result)
+	}
+	start := offset.Uint64()
+	length := new(big.Int).SetBytes(data[start : start+abiWordSize])
+	if !length.IsUint64() || length.Uint64() > uint64(len(data))-start-abiWordSize {
+		return "", fmt.Errorf("invalid string length in call result %v", result)
+	}
+	start += abiWordSize
+	return string(data[start : start+length.Uint64()]), nil
+}
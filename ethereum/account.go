@@ -0,0 +1,151 @@
+package ethereum
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/massigerardi/alchemy-api/utils"
+	"github.com/ybbus/jsonrpc/v3"
+)
+
+type AccountStates []*AccountState
+
+// AccountState is the state of an account at a block: its balance, its
+// nonce, its code and the words stored at the requested storage slots.
+type AccountState struct {
+	Address utils.Address
+	Block   string
+	Balance *big.Int
+	Nonce   uint64
+	Code    string
+	Storage map[utils.Hash]utils.Hash
+	Error   error
+}
+
+// GetTransactionCount returns the nonce of address, the number of
+// transactions it sent.
+func (c EthClient) GetTransactionCount(address utils.Address, blockNumberOpt ...string) (uint64, error) {
+	response, err := c.client.GetTransactionCountRaw(address, optBlockNumber(blockNumberOpt))
+	if err != nil {
+		return 0, err
+	}
+	return getTransactionCount(response)
+}
+
+// GetTransactionCount adds an eth_getTransactionCount call to the batch.
+func (b *Batch) GetTransactionCount(address utils.Address, blockNumberOpt ...string) *Future[uint64] {
+	return Add(b, getTransactionCount, EthGetTransactionCount, address.Hex(), optBlockNumber(blockNumberOpt))
+}
+
+func getTransactionCount(response *jsonrpc.RPCResponse) (uint64, error) {
+	result, err := utils.GetString(response)
+	if err != nil {
+		return 0, err
+	}
+	return utils.DecodeQuantity(result)
+}
+
+// GetAccountState reads the state of address at blockNumber and the words
+// at slots in its storage in a single batch. Since the calls of a batch may
+// be served by different nodes, pass a block number rather than a tag such
+// as Latest for a consistent snapshot.
+func (c EthClient) GetAccountState(address utils.Address, blockNumber string, slots ...utils.Hash) (*AccountState, error) {
+	b := c.NewBatch()
+	account := b.accountState(address, blockNumber, slots)
+	if err := b.Execute(); err != nil {
+		return nil, err
+	}
+	return account.get()
+}
+
+// GetAccountStates reads the state of every address like GetAccountState,
+// in batches of at most MaxBatchCalls calls. With the CollectPartial policy,
+// an account whose state could not be read entirely has only its Error set.
+func (c EthClient) GetAccountStates(addresses []utils.Address, blockNumber string, slots ...utils.Hash) (AccountStates, error) {
+	// an account takes a balance, a nonce and a code call, and one per slot
+	size := max(MaxBatchCalls/(3+len(slots)), 1)
+	states := make(AccountStates, 0, len(addresses))
+	for start := 0; start < len(addresses); start += size {
+		end := min(start+size, len(addresses))
+		b := c.NewBatch()
+		accounts := make([]*accountFutures, end-start)
+		for i, address := range addresses[start:end] {
+			accounts[i] = b.accountState(address, blockNumber, slots)
+		}
+		if err := b.Execute(); err != nil {
+			return nil, err
+		}
+		for _, account := range accounts {
+			state, err := account.get()
+			if err != nil {
+				err = newResponseError(err).asError()
+				if c.batchPolicy == FailFast {
+					return nil, fmt.Errorf("state of %v: %w", account.address, err)
+				}
+				state = &AccountState{Address: account.address, Block: blockNumber, Error: err}
+			}
+			states = append(states, state)
+		}
+	}
+	return states, nil
+}
+
+// accountFutures holds the calls reading the state of an account.
+type accountFutures struct {
+	address     utils.Address
+	blockNumber string
+	balance     *Future[*big.Int]
+	nonce       *Future[uint64]
+	code        *Future[string]
+	slots       []utils.Hash
+	storage     []*Future[utils.Hash]
+}
+
+func (b *Batch) accountState(address utils.Address, blockNumber string, slots []utils.Hash) *accountFutures {
+	account := &accountFutures{
+		address:     address,
+		blockNumber: blockNumber,
+		balance:     Add(b, keepCode(utils.GetBigInt), EthGetBalance, address.Hex(), blockNumber),
+		nonce:       Add(b, keepCode(getTransactionCount), EthGetTransactionCount, address.Hex(), blockNumber),
+		code:        Add(b, keepCode(utils.GetString), EthGetCode, address.Hex(), blockNumber),
+		slots:       slots,
+		storage:     make([]*Future[utils.Hash], len(slots)),
+	}
+	for i, slot := range slots {
+		account.storage[i] = Add(b, keepCode(getStorageWord), EthGetStorageAt, address.Hex(), slot.Hex(), blockNumber)
+	}
+	return account
+}
+
+// keepCode makes decode fail with a *ResponseError on error responses, so
+// that the code of remote errors is kept in AccountState.Error.
+func keepCode[T any](decode func(*jsonrpc.RPCResponse) (T, error)) func(*jsonrpc.RPCResponse) (T, error) {
+	return func(response *jsonrpc.RPCResponse) (T, error) {
+		if response.Error != nil {
+			var zero T
+			return zero, &ResponseError{Code: response.Error.Code, Message: response.Error.Message}
+		}
+		return decode(response)
+	}
+}
+
+// get returns the state of the account, or the first error of its calls.
+func (f *accountFutures) get() (*AccountState, error) {
+	state := &AccountState{Address: f.address, Block: f.blockNumber, Storage: make(map[utils.Hash]utils.Hash, len(f.slots))}
+	var err error
+	if state.Balance, err = f.balance.Get(); err != nil {
+		return nil, err
+	}
+	if state.Nonce, err = f.nonce.Get(); err != nil {
+		return nil, err
+	}
+	if state.Code, err = f.code.Get(); err != nil {
+		return nil, err
+	}
+	for i, slot := range f.slots {
+		if state.Storage[slot], err = f.storage[i].Get(); err != nil {
+			return nil, err
+		}
+	}
+	return state, nil
+}
@@ -0,0 +1,148 @@
+package ethereum
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/massigerardi/alchemy-api/mocks"
+	"github.com/massigerardi/alchemy-api/utils"
+)
+
+func TestEthClient_GetTransactionCount(t *testing.T) {
+	client := mocks.New().
+		On(EthGetTransactionCount, batchHolder, Latest).Return("0x7").
+		On(EthGetTransactionCount, batchHolder, "0x10").Return("0x").
+		On(EthGetTransactionCount).ReturnError(-32000, "header not found")
+	c := NewFromRPCClient(client)
+
+	if got, err := c.GetTransactionCount(batchHolder); err != nil || got != 7 {
+		t.Errorf("GetTransactionCount() got = %v, %v, want 7", got, err)
+	}
+	if _, err := c.GetTransactionCount(batchHolder, "0x10"); err == nil {
+		t.Errorf("GetTransactionCount() error = nil for an invalid quantity")
+	}
+	if _, err := c.GetTransactionCount(batchUsdc); err == nil || err.Error() != "-32000: header not found" {
+		t.Errorf("GetTransactionCount() error = %v, want header not found", err)
+	}
+}
+
+func newAccountMock() *mocks.Client {
+	return mocks.New().
+		On(EthGetBalance, batchUsdc, "0x429d3b").Return("0x14d1120d7b160000").
+		On(EthGetTransactionCount, batchUsdc, "0x429d3b").Return("0x1").
+		On(EthGetCode, batchUsdc, "0x429d3b").Return(mocks.UsdcCode).
+		On(EthGetStorageAt, batchUsdc, utils.Slot(1), "0x429d3b").Return("0x2a").
+		On(EthGetStorageAt, batchUsdc, utils.Slot(2), "0x429d3b").Return(utils.Hash{}.Hex()).
+		On(EthGetBalance, batchHolder, "0x429d3b").Return("0x0").
+		On(EthGetTransactionCount, batchHolder, "0x429d3b").Return("0x7").
+		On(EthGetCode, batchHolder, "0x429d3b").Return("0x").
+		On(EthGetStorageAt, batchHolder, mocks.Any, "0x429d3b").Return(utils.Hash{}.Hex()).
+		On(EthGetTransactionCount, traceContract, "0x429d3b").ReturnError(-32000, "header not found").
+		On(EthGetBalance).Return("0x0").
+		On(EthGetCode).Return("0x").
+		On(EthGetStorageAt).Return(utils.Hash{}.Hex())
+}
+
+func TestEthClient_GetAccountState(t *testing.T) {
+	client := newAccountMock()
+	c := NewFromRPCClient(client)
+
+	got, err := c.GetAccountState(batchUsdc, "0x429d3b", utils.Slot(1), utils.Slot(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	balance, _ := new(big.Int).SetString("14d1120d7b160000", 16)
+	want := &AccountState{
+		Address: batchUsdc,
+		Block:   "0x429d3b",
+		Balance: balance,
+		Nonce:   1,
+		Code:    mocks.UsdcCode,
+		Storage: map[utils.Hash]utils.Hash{utils.Slot(1): utils.Slot(42), utils.Slot(2): {}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetAccountState() got = %+v, want %+v", got, want)
+	}
+	calls := client.Calls()
+	if len(calls) != 5 {
+		t.Fatalf("GetAccountState() sent %v calls, want 5", len(calls))
+	}
+	for _, call := range calls {
+		if !call.Batch {
+			t.Errorf("GetAccountState() sent %+v outside of the batch", call)
+		}
+	}
+
+	_, err = c.GetAccountState(traceContract, "0x429d3b")
+	var responseError *ResponseError
+	if !errors.As(err, &responseError) || responseError.Code != -32000 {
+		t.Errorf("GetAccountState() error = %v, want header not found", err)
+	}
+}
+
+func TestEthClient_GetAccountStates(t *testing.T) {
+	addresses := []utils.Address{batchUsdc, traceContract, batchHolder}
+
+	states, err := NewFromRPCClient(newAccountMock()).GetAccountStates(addresses, "0x429d3b", utils.Slot(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(states) != 3 || states[0].Nonce != 1 || states[0].Storage[utils.Slot(1)] != utils.Slot(42) || states[2].Nonce != 7 || states[2].Code != "0x" {
+		t.Errorf("GetAccountStates() got = %+v", states)
+	}
+	want := &AccountState{Address: traceContract, Block: "0x429d3b", Error: &ResponseError{Code: -32000, Message: "header not found"}}
+	if !reflect.DeepEqual(states[1], want) {
+		t.Errorf("GetAccountStates() got[1] = %+v, want %+v", states[1], want)
+	}
+
+	_, err = NewFromRPCClient(newAccountMock(), WithBatchPolicy(FailFast)).GetAccountStates(addresses, "0x429d3b")
+	if err == nil || err.Error() != "state of "+traceContract.Hex()+": -32000: header not found" {
+		t.Errorf("GetAccountStates() error = %v, want the state of %v", err, traceContract)
+	}
+}
+
+func TestEthClient_GetAccountStates_Chunks(t *testing.T) {
+	addresses := streamAddresses(60)
+	client := &batchCounter{RPCClient: mocks.New().
+		On(EthGetBalance).Return("0x1").
+		On(EthGetTransactionCount).Return("0x2").
+		On(EthGetCode).Return("0x").
+		On(EthGetStorageAt).Return("0x2a")}
+
+	states, err := NewFromRPCClient(client).GetAccountStates(addresses, "0x429d3b", utils.Slot(1), utils.Slot(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(states) != len(addresses) || states[59].Address != addresses[59] || states[59].Storage[utils.Slot(2)] != utils.Slot(42) {
+		t.Errorf("GetAccountStates() got = %+v", states)
+	}
+	// 5 calls per account, 20 accounts per batch
+	if client.batches != 3 {
+		t.Errorf("GetAccountStates() sent %v batches, want 3", client.batches)
+	}
+}
+
+func TestAccountStates_JSON(t *testing.T) {
+	balance, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	states := AccountStates{
+		&AccountState{Address: batchUsdc, Block: "0x429d3b", Balance: balance, Nonce: 1, Code: "0x6080", Storage: map[utils.Hash]utils.Hash{utils.Slot(1): utils.Slot(42)}},
+		&AccountState{Address: batchHolder, Block: "0x429d3b", Error: &ResponseError{Code: -32000, Message: "header not found"}},
+	}
+	data, err := json.Marshal(states)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got AccountStates
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, states) {
+		t.Errorf("Unmarshal() got = %+v, want %+v after Marshal of %s", got, states, data)
+	}
+	if err := json.Unmarshal([]byte(`{"balance":"0x1"}`), &AccountState{}); err == nil {
+		t.Errorf("Unmarshal() error = nil for a hex balance")
+	}
+}
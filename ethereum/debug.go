@@ -0,0 +1,224 @@
+package ethereum
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/massigerardi/alchemy-api/utils"
+)
+
+// Built-in tracers of the debug namespace.
+const (
+	CallTracer     = "callTracer"
+	PrestateTracer = "prestateTracer"
+)
+
+// TraceOptions configures a debug trace. Without a Tracer the node returns
+// its default opcode level trace, to be decoded with DebugTrace.Decode.
+type TraceOptions struct {
+	// Tracer is a built-in tracer such as CallTracer, or JavaScript code.
+	Tracer string
+	// TracerConfig is the config of the tracer, e.g. a CallTracerConfig.
+	TracerConfig interface{}
+	// Timeout bounds the tracing time on the node, its default, usually
+	// 5s, if zero.
+	Timeout time.Duration
+}
+
+type CallTracerConfig struct {
+	// OnlyTopCall skips the frames of internal calls.
+	OnlyTopCall bool `json:"onlyTopCall,omitempty"`
+	// WithLog collects the logs emitted by each frame.
+	WithLog bool `json:"withLog,omitempty"`
+}
+
+type PrestateTracerConfig struct {
+	// DiffMode returns the pre and post state of the changed accounts
+	// instead of the pre state of every account touched.
+	DiffMode       bool `json:"diffMode,omitempty"`
+	DisableCode    bool `json:"disableCode,omitempty"`
+	DisableStorage bool `json:"disableStorage,omitempty"`
+}
+
+// CallTracerOptions returns the options of a callTracer trace.
+func CallTracerOptions(config CallTracerConfig) TraceOptions {
+	return TraceOptions{Tracer: CallTracer, TracerConfig: config}
+}
+
+// PrestateTracerOptions returns the options of a prestateTracer trace.
+func PrestateTracerOptions(config PrestateTracerConfig) TraceOptions {
+	return TraceOptions{Tracer: PrestateTracer, TracerConfig: config}
+}
+
+// WithTimeout returns a copy of the options with the given timeout.
+func (o TraceOptions) WithTimeout(timeout time.Duration) TraceOptions {
+	o.Timeout = timeout
+	return o
+}
+
+// MarshalJSON encodes the options as a geth trace config, the timeout
+// being a duration string such as "10s".
+func (o TraceOptions) MarshalJSON() ([]byte, error) {
+	config := struct {
+		Tracer       string      `json:"tracer,omitempty"`
+		TracerConfig interface{} `json:"tracerConfig,omitempty"`
+		Timeout      string      `json:"timeout,omitempty"`
+	}{Tracer: o.Tracer, TracerConfig: o.TracerConfig}
+	if o.Timeout > 0 {
+		config.Timeout = o.Timeout.String()
+	}
+	return json.Marshal(config)
+}
+
+// DebugTrace is the result of a debug trace, whose format depends on the
+// tracer: decode it with CallFrame, Prestate, PrestateDiff or Decode.
+type DebugTrace struct {
+	Raw json.RawMessage
+}
+
+func (t *DebugTrace) UnmarshalJSON(data []byte) error {
+	t.Raw = append(t.Raw[:0], data...)
+	return nil
+}
+
+func (t DebugTrace) MarshalJSON() ([]byte, error) {
+	if len(t.Raw) == 0 {
+		return []byte("null"), nil
+	}
+	return t.Raw, nil
+}
+
+// Decode unmarshals the trace into v, e.g. the result of a custom tracer.
+func (t DebugTrace) Decode(v interface{}) error {
+	return json.Unmarshal(t.Raw, v)
+}
+
+// CallFrame decodes the result of the callTracer.
+func (t DebugTrace) CallFrame() (*CallFrame, error) {
+	var frame CallFrame
+	if err := t.Decode(&frame); err != nil {
+		return nil, fmt.Errorf("decoding %v result: %w", CallTracer, err)
+	}
+	return &frame, nil
+}
+
+// Prestate decodes the result of the prestateTracer.
+func (t DebugTrace) Prestate() (PrestateAccounts, error) {
+	var accounts PrestateAccounts
+	if err := t.Decode(&accounts); err != nil {
+		return nil, fmt.Errorf("decoding %v result: %w", PrestateTracer, err)
+	}
+	return accounts, nil
+}
+
+// PrestateDiff decodes the result of the prestateTracer in diff mode.
+func (t DebugTrace) PrestateDiff() (*PrestateDiff, error) {
+	var diff PrestateDiff
+	if err := t.Decode(&diff); err != nil {
+		return nil, fmt.Errorf("decoding %v result: %w", PrestateTracer, err)
+	}
+	return &diff, nil
+}
+
+// CallFrame is a call traced by the callTracer, Type being CALL,
+// DELEGATECALL, STATICCALL, CALLCODE, CREATE, CREATE2 or SELFDESTRUCT.
+type CallFrame struct {
+	Type         string        `json:"type"`
+	From         utils.Address `json:"from"`
+	To           utils.Address `json:"to"`
+	Value        string        `json:"value,omitempty"`
+	Gas          string        `json:"gas"`
+	GasUsed      string        `json:"gasUsed"`
+	Input        string        `json:"input"`
+	Output       string        `json:"output,omitempty"`
+	Error        string        `json:"error,omitempty"`
+	RevertReason string        `json:"revertReason,omitempty"`
+	Calls        []*CallFrame  `json:"calls,omitempty"`
+	Logs         []*CallLog    `json:"logs,omitempty"`
+}
+
+// CallLog is a log collected by the callTracer, Position being the index of
+// the log among the calls of its frame.
+type CallLog struct {
+	Address  utils.Address `json:"address"`
+	Topics   []utils.Hash  `json:"topics"`
+	Data     string        `json:"data"`
+	Position string        `json:"position,omitempty"`
+}
+
+// Walk calls fn for the frame and its nested calls depth first, the frame
+// having depth 0.
+func (f *CallFrame) Walk(fn func(frame *CallFrame, depth int)) {
+	f.walk(fn, 0)
+}
+
+func (f *CallFrame) walk(fn func(frame *CallFrame, depth int), depth int) {
+	fn(f, depth)
+	for _, call := range f.Calls {
+		call.walk(fn, depth+1)
+	}
+}
+
+// PrestateAccounts holds the state of the accounts touched by a transaction.
+type PrestateAccounts map[utils.Address]*PrestateAccount
+
+type PrestateAccount struct {
+	Balance string                    `json:"balance,omitempty"`
+	Nonce   uint64                    `json:"nonce,omitempty"`
+	Code    string                    `json:"code,omitempty"`
+	Storage map[utils.Hash]utils.Hash `json:"storage,omitempty"`
+}
+
+// PrestateDiff is the result of the prestateTracer in diff mode: Pre holds
+// the changed fields of the accounts before the transaction and Post after.
+type PrestateDiff struct {
+	Pre  PrestateAccounts `json:"pre"`
+	Post PrestateAccounts `json:"post"`
+}
+
+// BlockTraces are the traces of the transactions of a block, in order.
+type BlockTraces []*BlockTrace
+
+type BlockTrace struct {
+	TxHash string     `json:"txHash,omitempty"`
+	Result DebugTrace `json:"result"`
+	Error  string     `json:"error,omitempty"`
+}
+
+// DebugTraceTransaction traces a mined transaction.
+func (c EthClient) DebugTraceTransaction(hash utils.Hash, options TraceOptions) (*DebugTrace, error) {
+	response, err := c.client.DebugTraceTransactionRaw(hash, options)
+	if err != nil {
+		return nil, err
+	}
+	return decodeObject[*DebugTrace](response)
+}
+
+// DebugTraceCall traces request executed on top of a block without
+// creating a transaction.
+func (c EthClient) DebugTraceCall(request CallRequest, options TraceOptions, blockNumberOpt ...string) (*DebugTrace, error) {
+	response, err := c.client.DebugTraceCallRaw(request, optBlockNumber(blockNumberOpt), options)
+	if err != nil {
+		return nil, err
+	}
+	return decodeObject[*DebugTrace](response)
+}
+
+// DebugTraceBlockByNumber traces every transaction of a block.
+func (c EthClient) DebugTraceBlockByNumber(blockNumber string, options TraceOptions) (BlockTraces, error) {
+	response, err := c.client.DebugTraceBlockByNumberRaw(blockNumber, options)
+	if err != nil {
+		return nil, err
+	}
+	return decodeObject[BlockTraces](response)
+}
+
+// DebugTraceBlockByHash traces every transaction of a block.
+func (c EthClient) DebugTraceBlockByHash(blockHash utils.Hash, options TraceOptions) (BlockTraces, error) {
+	response, err := c.client.DebugTraceBlockByHashRaw(blockHash, options)
+	if err != nil {
+		return nil, err
+	}
+	return decodeObject[BlockTraces](response)
+}
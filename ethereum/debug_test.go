@@ -0,0 +1,201 @@
+package ethereum
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/massigerardi/alchemy-api/mocks"
+	"github.com/massigerardi/alchemy-api/utils"
+)
+
+const callFrameJS = `{
+  "type": "CALL",
+  "from": "0x549c660ce2b988f588769d6ad87be801695b2be3",
+  "to": "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48",
+  "value": "0x0",
+  "gas": "0x1d4c0",
+  "gasUsed": "0x9c40",
+  "input": "0xa9059cbb",
+  "output": "0x",
+  "calls": [
+    {
+      "type": "DELEGATECALL",
+      "from": "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48",
+      "to": "0x558fa75074cc7cf045c764aed47d37776ea697d2",
+      "gas": "0x10000",
+      "gasUsed": "0x8000",
+      "input": "0xa9059cbb",
+      "error": "execution reverted",
+      "revertReason": "ERC20: transfer amount exceeds balance",
+      "logs": [
+        {
+          "address": "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48",
+          "topics": ["0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"],
+          "data": "0x01",
+          "position": "0x0"
+        }
+      ],
+      "calls": [
+        {"type": "STATICCALL", "from": "0x558fa75074cc7cf045c764aed47d37776ea697d2", "to": "0x549c660ce2b988f588769d6ad87be801695b2be3", "gas": "0x100", "gasUsed": "0x10", "input": "0x"}
+      ]
+    }
+  ]
+}`
+
+const prestateJS = `{
+  "0x549c660ce2b988f588769d6ad87be801695b2be3": {"balance": "0x2", "nonce": 7},
+  "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48": {
+    "balance": "0x0",
+    "code": "0x6080",
+    "storage": {"0x0000000000000000000000000000000000000000000000000000000000000001": "0x000000000000000000000000000000000000000000000000000000000000002a"}
+  }
+}`
+
+const prestateDiffJS = `{
+  "pre": {"0x549c660ce2b988f588769d6ad87be801695b2be3": {"balance": "0x2", "nonce": 7}},
+  "post": {"0x549c660ce2b988f588769d6ad87be801695b2be3": {"balance": "0x1", "nonce": 8}}
+}`
+
+var debugTx = utils.MustParseHash("0x46e9e6ecb2c6bbf8d1a7e7d2d1d6e0a0b2f5b1e6f2c0e7b0c2d4f6a8b0c2e4f6")
+
+func TestTraceOptions_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		options TraceOptions
+		want    string
+	}{
+		{name: "Default", options: TraceOptions{}, want: `{}`},
+		{name: "Call Tracer", options: CallTracerOptions(CallTracerConfig{WithLog: true}).WithTimeout(10 * time.Second),
+			want: `{"tracer":"callTracer","tracerConfig":{"withLog":true},"timeout":"10s"}`},
+		{name: "Prestate Diff", options: PrestateTracerOptions(PrestateTracerConfig{DiffMode: true}),
+			want: `{"tracer":"prestateTracer","tracerConfig":{"diffMode":true}}`},
+		{name: "Custom", options: TraceOptions{Tracer: "{result: function() { return 1 }}", Timeout: 90 * time.Second},
+			want: `{"tracer":"{result: function() { return 1 }}","timeout":"1m30s"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.options)
+			if err != nil || string(got) != tt.want {
+				t.Errorf("MarshalJSON() got = %s, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestEthClient_DebugTraceTransaction(t *testing.T) {
+	options := CallTracerOptions(CallTracerConfig{WithLog: true}).WithTimeout(10 * time.Second)
+	client := mocks.New().
+		On(DebugTraceTransaction, debugTx, map[string]interface{}{"tracer": CallTracer, "tracerConfig": map[string]bool{"withLog": true}, "timeout": "10s"}).ReturnJSON(callFrameJS).
+		On(DebugTraceTransaction).ReturnError(-32000, "transaction not found")
+	c := NewFromRPCClient(client)
+
+	trace, err := c.DebugTraceTransaction(debugTx, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	frame, err := trace.CallFrame()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if frame.Type != "CALL" || frame.To != traceUsdc || frame.GasUsed != "0x9c40" || len(frame.Calls) != 1 {
+		t.Errorf("CallFrame() got = %+v", frame)
+	}
+	inner := frame.Calls[0]
+	if inner.RevertReason != "ERC20: transfer amount exceeds balance" || len(inner.Logs) != 1 || inner.Logs[0].Topics[0] != utils.EventTopic("Transfer(address,address,uint256)") {
+		t.Errorf("CallFrame() got calls[0] = %+v", inner)
+	}
+
+	var types []string
+	var depths []int
+	frame.Walk(func(frame *CallFrame, depth int) {
+		types = append(types, frame.Type)
+		depths = append(depths, depth)
+	})
+	if !reflect.DeepEqual(types, []string{"CALL", "DELEGATECALL", "STATICCALL"}) || !reflect.DeepEqual(depths, []int{0, 1, 2}) {
+		t.Errorf("Walk() got = %v, %v", types, depths)
+	}
+
+	if _, err := c.DebugTraceTransaction(debugTx, TraceOptions{}); err == nil || err.Error() != "remote Error: -32000: transaction not found" {
+		t.Errorf("DebugTraceTransaction() error = %v, want transaction not found", err)
+	}
+}
+
+func TestEthClient_DebugTraceCall(t *testing.T) {
+	request := NewCallRequest(traceUsdc, "0xa9059cbb")
+	client := mocks.New().
+		On(DebugTraceCall, request, "0x10", map[string]interface{}{"tracer": PrestateTracer, "tracerConfig": map[string]bool{}}).ReturnJSON(prestateJS).
+		On(DebugTraceCall, request, Latest, map[string]interface{}{"tracer": PrestateTracer, "tracerConfig": map[string]bool{"diffMode": true}}).ReturnJSON(prestateDiffJS)
+	c := NewFromRPCClient(client)
+
+	trace, err := c.DebugTraceCall(request, PrestateTracerOptions(PrestateTracerConfig{}), "0x10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	accounts, err := trace.Prestate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := PrestateAccounts{
+		traceHolder: {Balance: "0x2", Nonce: 7},
+		traceUsdc: {Balance: "0x0", Code: "0x6080", Storage: map[utils.Hash]utils.Hash{
+			utils.MustParseHash("0x0000000000000000000000000000000000000000000000000000000000000001"): utils.MustParseHash("0x000000000000000000000000000000000000000000000000000000000000002a"),
+		}},
+	}
+	if !reflect.DeepEqual(accounts, want) {
+		t.Errorf("Prestate() got = %v, want %v", accounts, want)
+	}
+
+	trace, err = c.DebugTraceCall(request, PrestateTracerOptions(PrestateTracerConfig{DiffMode: true}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff, err := trace.PrestateDiff()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff.Pre[traceHolder].Nonce != 7 || diff.Post[traceHolder].Nonce != 8 || diff.Post[traceHolder].Balance != "0x1" {
+		t.Errorf("PrestateDiff() got = %+v, %+v", diff.Pre[traceHolder], diff.Post[traceHolder])
+	}
+	if _, err := trace.Prestate(); err == nil {
+		t.Errorf("Prestate() error = nil for a diff mode result")
+	}
+}
+
+func TestEthClient_DebugTraceBlock(t *testing.T) {
+	blockJS := `[{"txHash": "` + debugTx.Hex() + `", "result": ` + callFrameJS + `}, {"error": "execution timeout"}]`
+	blockHash := utils.MustParseHash("0x8243343df08b9751f5ca0c5f8c9c0460d8a9b6351066fae0acbd4d3e776de8bb")
+	client := mocks.New().
+		On(DebugTraceBlockByNumber, "0x429d3b", map[string]interface{}{"tracer": CallTracer, "tracerConfig": map[string]bool{"onlyTopCall": true}}).ReturnJSON(blockJS).
+		On(DebugTraceBlockByHash, blockHash, mocks.Any).ReturnJSON(blockJS)
+	c := NewFromRPCClient(client)
+
+	options := CallTracerOptions(CallTracerConfig{OnlyTopCall: true})
+	byNumber, err := c.DebugTraceBlockByNumber("0x429d3b", options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byHash, err := c.DebugTraceBlockByHash(blockHash, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(byNumber, byHash) || len(byNumber) != 2 {
+		t.Fatalf("DebugTraceBlockByHash() got = %v, want %v", byHash, byNumber)
+	}
+	if frame, err := byNumber[0].Result.CallFrame(); err != nil || byNumber[0].TxHash != debugTx.Hex() || frame.Type != "CALL" {
+		t.Errorf("DebugTraceBlockByNumber() got[0] = %+v, %v", byNumber[0], err)
+	}
+	if byNumber[1].Error != "execution timeout" {
+		t.Errorf("DebugTraceBlockByNumber() got[1] = %+v", byNumber[1])
+	}
+
+	data, err := json.Marshal(byNumber)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded BlockTraces
+	if err := json.Unmarshal(data, &decoded); err != nil || len(decoded) != 2 {
+		t.Errorf("Unmarshal() got = %v, %v after Marshal", decoded, err)
+	}
+}
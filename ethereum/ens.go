@@ -0,0 +1,252 @@
+package ethereum
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/massigerardi/alchemy-api/utils"
+	"github.com/ybbus/jsonrpc/v3"
+)
+
+// ENSRegistry is the address of the ENS registry on mainnet.
+var ENSRegistry = utils.MustParseAddress("0x00000000000C2E074eC69A0dFb2997BA6C7d2e1e")
+
+const (
+	ensResolver = "resolver(bytes32)"
+	ensAddr     = "addr(bytes32)"
+	ensName     = "name(bytes32)"
+	ensText     = "text(bytes32,string)"
+)
+
+// ErrENSNotFound is returned when a name or address has no resolver or no record set.
+var ErrENSNotFound = errors.New("ens: no record found")
+
+// Namehash returns the ENS namehash of name. Names are lowercased before
+// hashing; full UTS-46 normalization is left to the caller.
+func Namehash(name string) utils.Hash {
+	var node utils.Hash
+	if name == "" {
+		return node
+	}
+	labels := strings.Split(strings.ToLower(name), ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		node = utils.Keccak256Hash(node[:], utils.Keccak256([]byte(labels[i])))
+	}
+	return node
+}
+
+// ReverseNode returns the name under addr.reverse used for reverse resolution of address.
+func ReverseNode(address utils.Address) string {
+	return strings.ToLower(strings.TrimPrefix(address.Hex(), "0x")) + ".addr.reverse"
+}
+
+// ResolveName returns the address an ENS name resolves to.
+func (c EthClient) ResolveName(name string) (utils.Address, error) {
+	node := Namehash(name)
+	resolver, err := c.ensResolver(node)
+	if err != nil {
+		return utils.Address{}, err
+	}
+	result, err := c.Call(NewCallRequest(resolver, callData(ensAddr, node[:])))
+	if err != nil {
+		return utils.Address{}, err
+	}
+	address, err := decodeAddress(result)
+	if err != nil {
+		return utils.Address{}, err
+	}
+	if address.IsZero() {
+		return utils.Address{}, fmt.Errorf("%w for %v", ErrENSNotFound, name)
+	}
+	return address, nil
+}
+
+// ResolveAddress accepts either a hex address or an ENS name and returns the corresponding address.
+func (c EthClient) ResolveAddress(nameOrAddress string) (utils.Address, error) {
+	if strings.HasPrefix(nameOrAddress, "0x") || strings.HasPrefix(nameOrAddress, "0X") {
+		return utils.ParseAddress(nameOrAddress)
+	}
+	return c.ResolveName(nameOrAddress)
+}
+
+// LookupAddress returns the primary ENS name of address. The name is only
+// returned if it resolves back to the same address.
+func (c EthClient) LookupAddress(address utils.Address) (string, error) {
+	node := Namehash(ReverseNode(address))
+	resolver, err := c.ensResolver(node)
+	if err != nil {
+		return "", err
+	}
+	result, err := c.Call(NewCallRequest(resolver, callData(ensName, node[:])))
+	if err != nil {
+		return "", err
+	}
+	name, err := decodeString(result)
+	if err != nil {
+		return "", err
+	}
+	if name == "" {
+		return "", fmt.Errorf("%w for %v", ErrENSNotFound, address)
+	}
+	resolved, err := c.ResolveName(name)
+	if err != nil {
+		return "", err
+	}
+	if resolved != address {
+		return "", fmt.Errorf("ens: %v resolves to %v, not %v", name, resolved, address)
+	}
+	return name, nil
+}
+
+// GetText returns the text record key of an ENS name, e.g. "url" or "com.twitter".
+func (c EthClient) GetText(name string, key string) (string, error) {
+	node := Namehash(name)
+	resolver, err := c.ensResolver(node)
+	if err != nil {
+		return "", err
+	}
+	result, err := c.Call(NewCallRequest(resolver, callData(ensText, node[:], encodeUint(2*abiWordSize), encodeDynamicBytes([]byte(key)))))
+	if err != nil {
+		return "", err
+	}
+	return decodeString(result)
+}
+
+// LookupAddressBatch performs verified reverse lookups for many addresses
+// using a fixed number of batch calls, independent of the number of addresses.
+func (c EthClient) LookupAddressBatch(addresses []utils.Address) (ENSNameResponses, error) {
+	responses := make(ENSNameResponses, len(addresses))
+	nodes := make([]utils.Hash, len(addresses))
+	for i, address := range addresses {
+		responses[i] = &ENSNameResponse{Address: address}
+		nodes[i] = Namehash(ReverseNode(address))
+	}
+
+	resolvers, err := c.ensResolverBatch(responses, nodes)
+	if err != nil {
+		return nil, err
+	}
+	err = c.ensCallBatch(responses, func(i int) CallRequest {
+		return NewCallRequest(resolvers[i], callData(ensName, nodes[i][:]))
+	}, func(i int, result string) error {
+		name, err := decodeString(result)
+		if err != nil {
+			return err
+		}
+		if name == "" {
+			return fmt.Errorf("%w for %v", ErrENSNotFound, addresses[i])
+		}
+		responses[i].Name = name
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, response := range responses {
+		if response.Error == nil {
+			nodes[i] = Namehash(response.Name)
+		}
+	}
+	resolvers, err = c.ensResolverBatch(responses, nodes)
+	if err != nil {
+		return nil, err
+	}
+	err = c.ensCallBatch(responses, func(i int) CallRequest {
+		return NewCallRequest(resolvers[i], callData(ensAddr, nodes[i][:]))
+	}, func(i int, result string) error {
+		resolved, err := decodeAddress(result)
+		if err != nil {
+			return err
+		}
+		if resolved != addresses[i] {
+			return fmt.Errorf("ens: %v resolves to %v, not %v", responses[i].Name, resolved, addresses[i])
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, response := range responses {
+		if response.Error != nil {
+			response.Name = ""
+		}
+	}
+	return responses, nil
+}
+
+func (c EthClient) ensResolver(node utils.Hash) (utils.Address, error) {
+	result, err := c.Call(NewCallRequest(ENSRegistry, callData(ensResolver, node[:])))
+	if err != nil {
+		return utils.Address{}, err
+	}
+	resolver, err := decodeAddress(result)
+	if err != nil {
+		return utils.Address{}, err
+	}
+	if resolver.IsZero() {
+		return utils.Address{}, fmt.Errorf("%w for node %v", ErrENSNotFound, node)
+	}
+	return resolver, nil
+}
+
+// ensResolverBatch looks up the resolver of every node whose response has no error yet.
+func (c EthClient) ensResolverBatch(responses ENSNameResponses, nodes []utils.Hash) ([]utils.Address, error) {
+	resolvers := make([]utils.Address, len(nodes))
+	err := c.ensCallBatch(responses, func(i int) CallRequest {
+		return NewCallRequest(ENSRegistry, callData(ensResolver, nodes[i][:]))
+	}, func(i int, result string) error {
+		resolver, err := decodeAddress(result)
+		if err != nil {
+			return err
+		}
+		if resolver.IsZero() {
+			return fmt.Errorf("%w for %v", ErrENSNotFound, responses[i].Address)
+		}
+		resolvers[i] = resolver
+		return nil
+	})
+	return resolvers, err
+}
+
+// ensCallBatch sends one eth_call per response without an error and hands each result to handle.
+// Per item failures are recorded on the response, only transport failures are returned.
+func (c EthClient) ensCallBatch(responses ENSNameResponses, request func(i int) CallRequest, handle func(i int, result string) error) error {
+	var calls []CallRequest
+	var indexes []int
+	for i, response := range responses {
+		if response.Error != nil {
+			continue
+		}
+		calls = append(calls, request(i))
+		indexes = append(indexes, i)
+	}
+	if len(calls) == 0 {
+		return nil
+	}
+	results, err := c.client.CallBatchRaw(calls)
+	if err != nil {
+		return err
+	}
+	for j, i := range indexes {
+		result, err := ensBatchResult(results, j)
+		if err == nil {
+			err = handle(i, result)
+		}
+		if err != nil {
+			responses[i].Error = err
+		}
+	}
+	return nil
+}
+
+// ensBatchResult returns the result of the call with ID id, matched by ID
+// since the responses of a batch may come in any order.
+func ensBatchResult(results jsonrpc.RPCResponses, id int) (string, error) {
+	response, err := batchResponse(results, id)
+	if err != nil {
+		return "", err
+	}
+	return response.GetString()
+}
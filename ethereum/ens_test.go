@@ -0,0 +1,197 @@
+package ethereum
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/massigerardi/alchemy-api/utils"
+	"github.com/ybbus/jsonrpc/v3"
+)
+
+// ensMockClient answers eth_call requests from a table keyed by target address and call data,
+// returning batch responses in reverse order if reversed.
+type ensMockClient struct {
+	jsonrpc.RPCClient
+	results  map[string]string
+	reversed bool
+}
+
+func (m ensMockClient) result(params interface{}) *jsonrpc.RPCResponse {
+	request := params.(CallRequest)
+	result, ok := m.results[request.To.Hex()+request.Data]
+	if !ok {
+		return &jsonrpc.RPCResponse{Result: "0x" + hex.EncodeToString(make([]byte, abiWordSize))}
+	}
+	return &jsonrpc.RPCResponse{Result: result}
+}
+
+func (m ensMockClient) Call(_ context.Context, _ string, params ...interface{}) (*jsonrpc.RPCResponse, error) {
+	return m.result(params[0]), nil
+}
+
+func (m ensMockClient) CallBatch(_ context.Context, requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error) {
+	responses := make(jsonrpc.RPCResponses, len(requests))
+	for i, request := range requests {
+		responses[i] = m.result(request.Params.([]interface{})[0])
+		responses[i].ID = request.ID
+	}
+	for i, j := 0, len(responses)-1; m.reversed && i < j; i, j = i+1, j-1 {
+		responses[i], responses[j] = responses[j], responses[i]
+	}
+	return responses, nil
+}
+
+func addressResult(address utils.Address) string {
+	word := make([]byte, abiWordSize)
+	copy(word[abiWordSize-utils.AddressLength:], address[:])
+	return "0x" + hex.EncodeToString(word)
+}
+
+func stringResult(s string) string {
+	return "0x" + hex.EncodeToString(encodeUint(abiWordSize)) + hex.EncodeToString(encodeDynamicBytes([]byte(s)))
+}
+
+var (
+	ensResolverAddress = utils.MustParseAddress("0x4976fb03c32e5b8cfe2b6ccb31c09ba78ebaba41")
+	vitalik            = utils.MustParseAddress("0xd8da6bf26964af9d7eed9e03e53415d37aa96045")
+	impostor           = utils.MustParseAddress("0x549c660ce2b988f588769d6ad87be801695b2be3")
+	unnamed            = utils.MustParseAddress("0xb59f67a8bff5d8cd03f6ac17265c550ed8f33907")
+)
+
+func getENSMockClient() jsonrpc.RPCClient {
+	name := Namehash("vitalik.eth")
+	reverse := Namehash(ReverseNode(vitalik))
+	impostorReverse := Namehash(ReverseNode(impostor))
+	registry := ENSRegistry.Hex()
+	resolver := ensResolverAddress.Hex()
+	text := callData(ensText, name[:], encodeUint(2*abiWordSize), encodeDynamicBytes([]byte("url")))
+	return ensMockClient{results: map[string]string{
+		registry + callData(ensResolver, name[:]):            addressResult(ensResolverAddress),
+		resolver + callData(ensAddr, name[:]):                addressResult(vitalik),
+		resolver + text:                                      stringResult("https://vitalik.ca"),
+		registry + callData(ensResolver, reverse[:]):         addressResult(ensResolverAddress),
+		resolver + callData(ensName, reverse[:]):             stringResult("vitalik.eth"),
+		registry + callData(ensResolver, impostorReverse[:]): addressResult(ensResolverAddress),
+		resolver + callData(ensName, impostorReverse[:]):     stringResult("vitalik.eth"),
+	}}
+}
+
+func TestNamehash(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "", want: "0x0000000000000000000000000000000000000000000000000000000000000000"},
+		{name: "eth", want: "0x93cdeb708b7545dc668eb9280176169d1c33cfd8ed6f04690a0bcc88a93fc4ae"},
+		{name: "foo.eth", want: "0xde9b09fd7c5f901e23a3f19fecc54828e9c848539801e86591bd9801b019f84f"},
+		{name: "Foo.ETH", want: "0xde9b09fd7c5f901e23a3f19fecc54828e9c848539801e86591bd9801b019f84f"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Namehash(tt.name).Hex(); got != tt.want {
+				t.Errorf("Namehash() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEthClient_ResolveName(t *testing.T) {
+	c := EthClient{client: &ETHClientRaw{getENSMockClient()}}
+	got, err := c.ResolveName("vitalik.eth")
+	if err != nil {
+		t.Fatalf("ResolveName() error = %v", err)
+	}
+	if got != vitalik {
+		t.Errorf("ResolveName() got = %v, want %v", got, vitalik)
+	}
+	_, err = c.ResolveName("unknown.eth")
+	if !errors.Is(err, ErrENSNotFound) {
+		t.Errorf("ResolveName() error = %v, want %v", err, ErrENSNotFound)
+	}
+}
+
+func TestEthClient_ResolveAddress(t *testing.T) {
+	c := EthClient{client: &ETHClientRaw{getENSMockClient()}}
+	tests := []struct {
+		name    string
+		input   string
+		want    utils.Address
+		wantErr bool
+	}{
+		{name: "Hex Address", input: "0xb59f67a8bff5d8cd03f6ac17265c550ed8f33907", want: unnamed},
+		{name: "ENS Name", input: "vitalik.eth", want: vitalik},
+		{name: "Bad Checksum", input: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB49", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.ResolveAddress(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ResolveAddress() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ResolveAddress() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEthClient_LookupAddress(t *testing.T) {
+	c := EthClient{client: &ETHClientRaw{getENSMockClient()}}
+	tests := []struct {
+		name    string
+		address utils.Address
+		want    string
+		wantErr bool
+	}{
+		{name: "Verified", address: vitalik, want: "vitalik.eth"},
+		{name: "Forward Mismatch", address: impostor, wantErr: true},
+		{name: "No Resolver", address: unnamed, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.LookupAddress(tt.address)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LookupAddress() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("LookupAddress() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEthClient_GetText(t *testing.T) {
+	c := EthClient{client: &ETHClientRaw{getENSMockClient()}}
+	got, err := c.GetText("vitalik.eth", "url")
+	if err != nil {
+		t.Fatalf("GetText() error = %v", err)
+	}
+	if got != "https://vitalik.ca" {
+		t.Errorf("GetText() got = %v", got)
+	}
+}
+
+func TestEthClient_LookupAddressBatch(t *testing.T) {
+	for _, reversed := range []bool{false, true} {
+		client := getENSMockClient().(ensMockClient)
+		client.reversed = reversed
+		c := EthClient{client: &ETHClientRaw{client}}
+		got, err := c.LookupAddressBatch([]utils.Address{vitalik, impostor, unnamed})
+		if err != nil {
+			t.Fatalf("LookupAddressBatch() error = %v", err)
+		}
+		if got[0].Name != "vitalik.eth" || got[0].Error != nil {
+			t.Errorf("LookupAddressBatch()[0] = %+v, reversed %v", got[0], reversed)
+		}
+		if got[1].Name != "" || got[1].Error == nil {
+			t.Errorf("LookupAddressBatch()[1] = %+v, want forward mismatch, reversed %v", got[1], reversed)
+		}
+		if got[2].Name != "" || !errors.Is(got[2].Error, ErrENSNotFound) {
+			t.Errorf("LookupAddressBatch()[2] = %+v, want not found, reversed %v", got[2], reversed)
+		}
+	}
+}
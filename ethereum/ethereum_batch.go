@@ -1,18 +1,53 @@
 package ethereum
 
 import (
+	"fmt"
+	"math/big"
+
 	"github.com/massigerardi/alchemy-api/utils"
+	"github.com/ybbus/jsonrpc/v3"
+)
+
+// BatchPolicy selects how the batch methods handle items that failed.
+type BatchPolicy int
+
+const (
+	// CollectPartial records the error of each failed item on its response
+	// and returns the results of the others. It is the default.
+	CollectPartial BatchPolicy = iota
+	// FailFast returns no results but the error of the first failed item.
+	FailFast
 )
 
-func (c EthClient) GetContractCodeBatch(addresses []string, blockNumberOpt ...string) (ContractCodeResponses, error) {
+// WithBatchPolicy sets the policy of GetBalanceBatch, GetContractCodeBatch
+// and GetAccountStates.
+func WithBatchPolicy(policy BatchPolicy) Option {
+	return func(o *options) {
+		o.batchPolicy = policy
+	}
+}
+
+func (c EthClient) GetContractCodeBatch(addresses []utils.Address, blockNumberOpt ...string) (ContractCodeResponses, error) {
 	responses, err := c.client.GetContractCodeBatchRaw(addresses, blockNumberOpt...)
 	if err != nil {
 		return nil, err
 	}
+	return c.contractCodeResponses(addresses, responses)
+}
+
+// contractCodeResponses decodes the responses of an eth_getCode batch for addresses.
+func (c EthClient) contractCodeResponses(addresses []utils.Address, responses jsonrpc.RPCResponses) (ContractCodeResponses, error) {
 	contractCodeResponses := make(ContractCodeResponses, len(addresses))
-	for i, response := range responses {
-		address := addresses[i]
-		code, codeError := utils.GetString(response)
+	for i, address := range addresses {
+		var code string
+		response, codeError := batchResponse(responses, i)
+		if codeError == nil {
+			code, codeError = response.GetString()
+		}
+		codeError = newResponseError(codeError).asError()
+		if codeError != nil && c.batchPolicy == FailFast {
+			return nil, fmt.Errorf("code of %v: %w", address, codeError)
+		}
 		contractCodeResponses[i] = &ContractCodeResponse{
 			Address: address,
 			Code:    code,
@@ -22,20 +57,57 @@ func (c EthClient) GetContractCodeBatch(addresses []string, blockNumberOpt ...st
 	return contractCodeResponses, nil
 }
 
-func (c EthClient) GetBalanceBatch(addresses []string, blockNumberOpt ...string) (BalanceResponses, error) {
+// GetBalanceBatch returns the balances of addresses. With the CollectPartial
+// policy, an address whose balance could not be read has a nil Amount and
+// its Error set.
+func (c EthClient) GetBalanceBatch(addresses []utils.Address, blockNumberOpt ...string) (BalanceResponses, error) {
 	responses, err := c.client.GetBalanceBatch(addresses, blockNumberOpt...)
 	if err != nil {
 		return nil, err
 	}
+	return c.balanceResponses(addresses, responses)
+}
+
+// balanceResponses decodes the responses of an eth_getBalance batch for addresses.
+func (c EthClient) balanceResponses(addresses []utils.Address, responses jsonrpc.RPCResponses) (BalanceResponses, error) {
 	balanceResponses := make(BalanceResponses, len(addresses))
-	for i, response := range responses {
-		address := addresses[i]
-		amount, codeError := utils.GetBigInt(response)
+	for i, address := range addresses {
+		var amount *big.Int
+		response, amountError := batchResponse(responses, i)
+		if amountError == nil {
+			amount, amountError = utils.GetBigInt(response)
+		}
+		amountError = newResponseError(amountError).asError()
+		if amountError != nil && c.batchPolicy == FailFast {
+			return nil, fmt.Errorf("balance of %v: %w", address, amountError)
+		}
 		balanceResponses[i] = &BalanceResponse{
 			Address: address,
-			Amount:  *amount,
-			Error:   codeError,
+			Amount:  amount,
+			Error:   amountError,
 		}
 	}
 	return balanceResponses, nil
 }
+
+// batchResponse returns the response to the request with ID id, which is not
+// necessarily at the same position in the batch. Error responses are
+// returned as a *ResponseError.
+func batchResponse(responses jsonrpc.RPCResponses, id int) (*jsonrpc.RPCResponse, error) {
+	var found *jsonrpc.RPCResponse
+	if id < len(responses) && responses[id] != nil && responses[id].ID == id {
+		found = responses[id]
+	}
+	for _, response := range responses {
+		if found == nil && response != nil && response.ID == id {
+			found = response
+		}
+	}
+	switch {
+	case found == nil:
+		return nil, &ResponseError{Message: fmt.Sprintf("missing response %v in batch", id)}
+	case found.Error != nil:
+		return nil, &ResponseError{Code: found.Error.Code, Message: found.Error.Message}
+	}
+	return found, nil
+}
@@ -0,0 +1,190 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/massigerardi/alchemy-api/batch"
+	"github.com/massigerardi/alchemy-api/utils"
+	"github.com/ybbus/jsonrpc/v3"
+)
+
+// MaxBatchCalls is the number of calls that methods reading many values send
+// in one batch, below the batch limits of providers.
+const MaxBatchCalls = 100
+
+var (
+	// ErrNotExecuted is returned by a Future whose batch was not executed yet.
+	ErrNotExecuted = errors.New("batch not executed")
+	// ErrBatchExecuted is returned when executing a batch or adding a call to
+	// it a second time.
+	ErrBatchExecuted = errors.New("batch already executed")
+)
+
+// Future is the result of a call added to a Batch, available once the batch
+// was executed.
+type Future[T any] struct {
+	value T
+	err   error
+}
+
+// Get returns the result of the call, or ErrNotExecuted before the batch was
+// executed.
+func (f *Future[T]) Get() (T, error) {
+	return f.value, f.err
+}
+
+func (f *Future[T]) resolve(response *jsonrpc.RPCResponse, decode func(*jsonrpc.RPCResponse) (T, error)) {
+	f.value, f.err = decode(response)
+}
+
+func (f *Future[T]) fail(err error) {
+	f.err = err
+}
+
+// Batch collects calls of any method to send them in one round trip.
+// Each call returns a typed Future, resolved by Execute.
+//
+//	b := client.NewBatch()
+//	balance := b.GetBalance(address)
+//	block := b.GetBlockByNumber(Latest)
+//	if err := b.Execute(); err != nil {
+//		return err
+//	}
+//	amount, err := balance.Get()
+//
+// A Batch is not safe for concurrent use.
+type Batch struct {
+	client   jsonrpc.RPCClient
+	requests jsonrpc.RPCRequests
+	resolve  []func(*jsonrpc.RPCResponse)
+	fail     []func(error)
+	executed bool
+}
+
+// NewBatch returns an empty batch sent through the client.
+func (c EthClient) NewBatch() *Batch {
+	return &Batch{client: c.client.client}
+}
+
+// Len returns the number of calls in the batch.
+func (b *Batch) Len() int {
+	return len(b.requests)
+}
+
+// Add adds a call of method to the batch, decoding its result with decode.
+// It is the building block of the typed Batch methods and of calls to
+// methods the client does not wrap.
+func Add[T any](b *Batch, decode func(*jsonrpc.RPCResponse) (T, error), method string, params ...interface{}) *Future[T] {
+	future := &Future[T]{err: ErrNotExecuted}
+	if b.executed {
+		future.fail(ErrBatchExecuted)
+		return future
+	}
+	b.requests = append(b.requests, newBatchRequest(len(b.requests), method, params...))
+	b.resolve = append(b.resolve, func(response *jsonrpc.RPCResponse) { future.resolve(response, decode) })
+	b.fail = append(b.fail, future.fail)
+	return future
+}
+
+// AddCall adds a call of method to the batch, its result being unmarshalled
+// into a T.
+func AddCall[T any](b *Batch, method string, params ...interface{}) *Future[T] {
+	return Add(b, decodeObject[T], method, params...)
+}
+
+// Execute sends the calls of the batch and resolves their futures. An error
+// of the whole batch is returned and resolves every future with it, while
+// an error response only fails its own future.
+func (b *Batch) Execute() error {
+	return b.ExecuteContext(context.Background())
+}
+
+// ExecuteContext is Execute with a context, which cancels the batch.
+func (b *Batch) ExecuteContext(ctx context.Context) error {
+	if b.executed {
+		return ErrBatchExecuted
+	}
+	b.executed = true
+	if len(b.requests) == 0 {
+		return nil
+	}
+	responses, err := batch.DoBatchCallContext(ctx, b.client, b.requests)
+	if err != nil {
+		for _, fail := range b.fail {
+			fail(err)
+		}
+		return err
+	}
+	byID := responses.AsMap()
+	for i, resolve := range b.resolve {
+		response, ok := byID[i]
+		if !ok {
+			b.fail[i](fmt.Errorf("no response for %v request %v", b.requests[i].Method, i))
+			continue
+		}
+		resolve(response)
+	}
+	return nil
+}
+
+// GetBlockNumber adds an eth_blockNumber call to the batch.
+func (b *Batch) GetBlockNumber() *Future[string] {
+	return Add(b, utils.GetString, EthBlockNumber)
+}
+
+// GetBalance adds an eth_getBalance call to the batch.
+func (b *Batch) GetBalance(address utils.Address, blockNumberOpt ...string) *Future[*big.Int] {
+	return Add(b, utils.GetBigInt, EthGetBalance, address.Hex(), optBlockNumber(blockNumberOpt))
+}
+
+// GetContractCode adds an eth_getCode call to the batch.
+func (b *Batch) GetContractCode(address utils.Address, blockNumberOpt ...string) *Future[string] {
+	return Add(b, utils.GetString, EthGetCode, address.Hex(), optBlockNumber(blockNumberOpt))
+}
+
+// GetBlockByNumber adds an eth_getBlockByNumber call to the batch.
+func (b *Batch) GetBlockByNumber(blockNumber string) *Future[*Block] {
+	decode := func(response *jsonrpc.RPCResponse) (*Block, error) { return getBlock(response, blockNumber) }
+	return Add(b, decode, EthGetBlockByNumber, blockNumber, false)
+}
+
+// GetBlockByHash adds an eth_getBlockByHash call to the batch.
+func (b *Batch) GetBlockByHash(blockHash utils.Hash) *Future[*Block] {
+	decode := func(response *jsonrpc.RPCResponse) (*Block, error) { return getBlock(response, blockHash.Hex()) }
+	return Add(b, decode, EthGetBlockByHash, blockHash.Hex(), false)
+}
+
+// GetTransactionReceipt adds an eth_getTransactionReceipt call to the batch.
+func (b *Batch) GetTransactionReceipt(hash utils.Hash) *Future[*Receipt] {
+	decode := func(response *jsonrpc.RPCResponse) (*Receipt, error) { return getReceipt(response, hash.Hex()) }
+	return Add(b, decode, EthGetReceipt, hash.Hex())
+}
+
+// Call adds an eth_call to the batch.
+func (b *Batch) Call(request CallRequest, blockNumberOpt ...string) *Future[string] {
+	return Add(b, utils.GetString, EthCall, request, optBlockNumber(blockNumberOpt))
+}
+
+// GetGasPrice adds an eth_gasPrice call to the batch.
+func (b *Batch) GetGasPrice() *Future[*big.Int] {
+	return Add(b, utils.GetBigInt, EthGasPrice)
+}
+
+func decodeObject[T any](response *jsonrpc.RPCResponse) (T, error) {
+	var result T
+	if response.Error != nil {
+		return result, fmt.Errorf("remote Error: %v", response.Error.Error())
+	}
+	err := response.GetObject(&result)
+	return result, err
+}
+
+func optBlockNumber(blockNumberOpt []string) string {
+	if len(blockNumberOpt) > 0 {
+		return blockNumberOpt[0]
+	}
+	return Latest
+}
@@ -0,0 +1,171 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/massigerardi/alchemy-api/mocks"
+	"github.com/massigerardi/alchemy-api/utils"
+	"github.com/ybbus/jsonrpc/v3"
+)
+
+const receiptJS = `{
+  "transactionHash": "0x8243343df08b9751f5ca0c5f8c9c0460d8a9b6351066fae0acbd4d3e776de8bb",
+  "blockNumber": "0x429d3b",
+  "gasUsed": "0x5208",
+  "status": "0x1",
+  "logs": []
+}`
+
+var (
+	batchHolder = utils.MustParseAddress("0x549c660ce2b988f588769d6ad87be801695b2be3")
+	batchUsdc   = utils.MustParseAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+	batchTx     = utils.MustParseHash("0x8243343df08b9751f5ca0c5f8c9c0460d8a9b6351066fae0acbd4d3e776de8bb")
+)
+
+func TestBatch_Execute(t *testing.T) {
+	client := mocks.New().
+		On(EthBlockNumber).Return("0x1234").
+		On(EthGetBalance, batchHolder, Latest).Return("0x14d1120d7b160000").
+		On(EthGetCode, batchUsdc, "0x1").Return(mocks.UsdcCode).
+		On(EthGetBlockByNumber, "0x429d3b", false).ReturnJSON(mocks.BlockJS).
+		On(EthGetReceipt, batchTx).ReturnJSON(receiptJS).
+		On(EthCall, mocks.Any, Latest).Return("0x06").
+		On(EthGasPrice).ReturnError(-32000, "header not found").
+		On("eth_chainId").Return("0x1")
+	b := NewFromRPCClient(client).NewBatch()
+
+	blockNumber := b.GetBlockNumber()
+	balance := b.GetBalance(batchHolder)
+	code := b.GetContractCode(batchUsdc, "0x1")
+	block := b.GetBlockByNumber("0x429d3b")
+	receipt := b.GetTransactionReceipt(batchTx)
+	call := b.Call(NewCallRequest(batchUsdc, "0x313ce567"))
+	gasPrice := b.GetGasPrice()
+	chainID := AddCall[string](b, "eth_chainId")
+	if b.Len() != 8 {
+		t.Fatalf("Len() got = %v, want 8", b.Len())
+	}
+	if _, err := balance.Get(); !errors.Is(err, ErrNotExecuted) {
+		t.Errorf("Get() error = %v before Execute, want %v", err, ErrNotExecuted)
+	}
+
+	if err := b.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if calls := client.Calls(); len(calls) != 8 || !calls[0].Batch {
+		t.Errorf("Execute() sent %+v, want one batch", calls)
+	}
+	if got, err := blockNumber.Get(); err != nil || got != "0x1234" {
+		t.Errorf("GetBlockNumber() got = %v, %v", got, err)
+	}
+	if got, err := balance.Get(); err != nil || got.String() != "1500000000000000000" {
+		t.Errorf("GetBalance() got = %v, %v", got, err)
+	}
+	if got, err := code.Get(); err != nil || got != mocks.UsdcCode {
+		t.Errorf("GetContractCode() got = %v, %v", got, err)
+	}
+	if got, err := block.Get(); err != nil || got.Hash != batchTx.Hex() {
+		t.Errorf("GetBlockByNumber() got = %+v, %v", got, err)
+	}
+	if got, err := receipt.Get(); err != nil || got.Status != "0x1" || got.GasUsed != "0x5208" {
+		t.Errorf("GetTransactionReceipt() got = %+v, %v", got, err)
+	}
+	if got, err := call.Get(); err != nil || got != "0x06" {
+		t.Errorf("Call() got = %v, %v", got, err)
+	}
+	if got, err := gasPrice.Get(); err == nil || err.Error() != "remote Error: -32000: header not found" {
+		t.Errorf("GetGasPrice() got = %v, %v, want remote error", got, err)
+	}
+	if got, err := chainID.Get(); err != nil || got != "0x1" {
+		t.Errorf("AddCall() got = %v, %v", got, err)
+	}
+}
+
+func TestBatch_Errors(t *testing.T) {
+	failure := errors.New("connection refused")
+	client := mocks.New().
+		OnBatch(EthBlockNumber, EthGasPrice).Once().Error(failure).
+		OnBatch().Return(&jsonrpc.RPCResponse{Result: "0x1"})
+
+	b := NewFromRPCClient(client).NewBatch()
+	blockNumber, gasPrice := b.GetBlockNumber(), b.GetGasPrice()
+	if err := b.Execute(); !errors.Is(err, failure) {
+		t.Errorf("Execute() error = %v, want %v", err, failure)
+	}
+	if _, err := blockNumber.Get(); !errors.Is(err, failure) {
+		t.Errorf("GetBlockNumber() error = %v, want %v", err, failure)
+	}
+	if _, err := gasPrice.Get(); !errors.Is(err, failure) {
+		t.Errorf("GetGasPrice() error = %v, want %v", err, failure)
+	}
+	if err := b.Execute(); !errors.Is(err, ErrBatchExecuted) {
+		t.Errorf("Execute() error = %v, want %v", err, ErrBatchExecuted)
+	}
+	if _, err := b.GetBlockNumber().Get(); !errors.Is(err, ErrBatchExecuted) {
+		t.Errorf("GetBlockNumber() error = %v after Execute, want %v", err, ErrBatchExecuted)
+	}
+
+	b = NewFromRPCClient(client).NewBatch()
+	blockNumber, gasPrice = b.GetBlockNumber(), b.GetGasPrice()
+	if err := b.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if got, err := blockNumber.Get(); err != nil || got != "0x1" {
+		t.Errorf("GetBlockNumber() got = %v, %v", got, err)
+	}
+	if _, err := gasPrice.Get(); err == nil {
+		t.Errorf("GetGasPrice() error = nil for a missing response")
+	}
+
+	if err := NewFromRPCClient(client).NewBatch().Execute(); err != nil {
+		t.Errorf("Execute() error = %v for an empty batch", err)
+	}
+}
+
+// contextClient fails batches whose context is done.
+type contextClient struct {
+	jsonrpc.RPCClient
+}
+
+func (c contextClient) CallBatch(ctx context.Context, requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.RPCClient.CallBatch(ctx, requests)
+}
+
+func TestBatch_ExecuteContext(t *testing.T) {
+	c := NewFromRPCClient(contextClient{mocks.New().On(EthBlockNumber).Return("0x1")})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	b := c.NewBatch()
+	blockNumber := b.GetBlockNumber()
+	if err := b.ExecuteContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("ExecuteContext() error = %v, want %v", err, context.Canceled)
+	}
+	if _, err := blockNumber.Get(); !errors.Is(err, context.Canceled) {
+		t.Errorf("GetBlockNumber() error = %v, want %v", err, context.Canceled)
+	}
+
+	b = c.NewBatch()
+	blockNumber = b.GetBlockNumber()
+	if err := b.ExecuteContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := blockNumber.Get(); err != nil || got != "0x1" {
+		t.Errorf("GetBlockNumber() got = %v, %v", got, err)
+	}
+}
+
+func TestBatch_ReceiptNotFound(t *testing.T) {
+	b := NewFromRPCClient(mocks.New().On(EthGetReceipt).Return(nil)).NewBatch()
+	receipt := b.GetTransactionReceipt(batchTx)
+	if err := b.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := receipt.Get(); err == nil {
+		t.Errorf("GetTransactionReceipt() got = %+v, want not found error", got)
+	}
+}
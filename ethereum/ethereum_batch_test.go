@@ -1,12 +1,12 @@
 package ethereum
 
 import (
-	"fmt"
 	"math/big"
 	"reflect"
 	"testing"
 
 	"github.com/massigerardi/alchemy-api/mocks"
+	"github.com/massigerardi/alchemy-api/utils"
 	"github.com/ybbus/jsonrpc/v3"
 )
 
@@ -15,7 +15,7 @@ func TestEthClient_GetContractCodeBatch(t *testing.T) {
 		client jsonrpc.RPCClient
 	}
 	type args struct {
-		addresses      []string
+		addresses      []utils.Address
 		blockNumberOpt []string
 	}
 
@@ -32,29 +32,22 @@ func TestEthClient_GetContractCodeBatch(t *testing.T) {
 			name:   "Test Success",
 			fields: fields{client: client},
 			args: args{
-				addresses:      []string{"0x549c660ce2b988f588769d6ad87be801695b2be3", "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"},
+				addresses:      []utils.Address{utils.MustParseAddress("0x549c660ce2b988f588769d6ad87be801695b2be3"), utils.MustParseAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")},
 				blockNumberOpt: []string{Latest}},
 			want: ContractCodeResponses{
-				&ContractCodeResponse{Address: "0x549c660ce2b988f588769d6ad87be801695b2be3", Code: mocks.EoaCode, Error: nil},
-				&ContractCodeResponse{Address: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", Code: mocks.UsdcCode, Error: nil},
+				&ContractCodeResponse{Address: utils.MustParseAddress("0x549c660ce2b988f588769d6ad87be801695b2be3"), Code: mocks.EoaCode, Error: nil},
+				&ContractCodeResponse{Address: utils.MustParseAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"), Code: mocks.UsdcCode, Error: nil},
 			},
 		},
-		{
-			name:   "Test Wrong Address",
-			fields: fields{client: client},
-			args: args{
-				addresses:      []string{"0x549c660ce2b988f588769d6ad87be80169", "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"},
-				blockNumberOpt: []string{Latest}},
-			wantErr: true},
 		{
 			name:   "Test Wrong Response",
 			fields: fields{client: client},
 			args: args{
-				addresses:      []string{"0x549c660ce2b988f588769d6ad87be801695b2be1", "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"},
+				addresses:      []utils.Address{utils.MustParseAddress("0x549c660ce2b988f588769d6ad87be801695b2be1"), utils.MustParseAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")},
 				blockNumberOpt: []string{Latest}},
 			want: ContractCodeResponses{
-				&ContractCodeResponse{Address: "0x549c660ce2b988f588769d6ad87be801695b2be1", Code: "", Error: fmt.Errorf("-123: wrong Response")},
-				&ContractCodeResponse{Address: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", Code: mocks.UsdcCode, Error: nil},
+				&ContractCodeResponse{Address: utils.MustParseAddress("0x549c660ce2b988f588769d6ad87be801695b2be1"), Code: "", Error: &ResponseError{Code: -123, Message: "wrong Response"}},
+				&ContractCodeResponse{Address: utils.MustParseAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"), Code: mocks.UsdcCode, Error: nil},
 			},
 		},
 	}
@@ -80,7 +73,7 @@ func TestEthClient_GetBalanceBatch(t *testing.T) {
 		client jsonrpc.RPCClient
 	}
 	type args struct {
-		addresses      []string
+		addresses      []utils.Address
 		blockNumberOpt []string
 	}
 
@@ -97,29 +90,13 @@ func TestEthClient_GetBalanceBatch(t *testing.T) {
 			name:   "Test Success",
 			fields: fields{client: client},
 			args: args{
-				addresses:      []string{"0x549c660ce2b988f588769d6ad87be801695b2be3", "0x558FA75074cc7cF045C764aEd47D37776Ea697d2"},
+				addresses:      []utils.Address{utils.MustParseAddress("0x549c660ce2b988f588769d6ad87be801695b2be3"), utils.MustParseAddress("0x558FA75074cc7cF045C764aEd47D37776Ea697d2")},
 				blockNumberOpt: []string{Latest}},
 			want: BalanceResponses{
-				&BalanceResponse{Address: "0x549c660ce2b988f588769d6ad87be801695b2be3", Amount: *big.NewInt(20066469208092992), Error: nil},
-				&BalanceResponse{Address: "0x558FA75074cc7cF045C764aEd47D37776Ea697d2", Amount: *big.NewInt(452046866901000), Error: nil},
+				&BalanceResponse{Address: utils.MustParseAddress("0x549c660ce2b988f588769d6ad87be801695b2be3"), Amount: big.NewInt(20066469208092992), Error: nil},
+				&BalanceResponse{Address: utils.MustParseAddress("0x558FA75074cc7cF045C764aEd47D37776Ea697d2"), Amount: big.NewInt(452046866901000), Error: nil},
 			},
 		},
-		{
-			name:   "Test Error",
-			fields: fields{client: client},
-			args: args{
-				addresses:      []string{"0x549c660ce2b988f588769d6ad87be80169", "0x558FA75074cc7cF045C764aEd47D37776Ea697d2"},
-				blockNumberOpt: []string{Latest}},
-			wantErr: true,
-		},
-		{
-			name:   "Test Remote Error",
-			fields: fields{client: client},
-			args: args{
-				addresses:      []string{"0x549c660ce2b988f588769d6ad87be80169", "0x558FA75074cc7cF045C764aEd47D37776Ea697d1"},
-				blockNumberOpt: []string{Latest}},
-			wantErr: true,
-		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -137,3 +114,64 @@ func TestEthClient_GetBalanceBatch(t *testing.T) {
 		})
 	}
 }
+
+func TestEthClient_GetBalanceBatch_Policy(t *testing.T) {
+	holder := utils.MustParseAddress("0x549c660ce2b988f588769d6ad87be801695b2be3")
+	usdc := utils.MustParseAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+	client := mocks.New().
+		On(EthGetBalance, holder, mocks.Any).Return("0x1").
+		On(EthGetBalance, usdc, mocks.Any).ReturnError(-32000, "header not found")
+
+	tests := []struct {
+		name    string
+		policy  BatchPolicy
+		want    BalanceResponses
+		wantErr string
+	}{
+		{
+			name:   "Collect Partial",
+			policy: CollectPartial,
+			want: BalanceResponses{
+				&BalanceResponse{Address: holder, Amount: big.NewInt(1)},
+				&BalanceResponse{Address: usdc, Error: &ResponseError{Code: -32000, Message: "header not found"}},
+			},
+		},
+		{
+			name:    "Fail Fast",
+			policy:  FailFast,
+			wantErr: "balance of 0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48: -32000: header not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewFromRPCClient(client, WithBatchPolicy(tt.policy))
+			got, err := c.GetBalanceBatch([]utils.Address{holder, usdc})
+			if err != nil || tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("GetBalanceBatch() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetBalanceBatch() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEthClient_GetBalanceBatch_Responses(t *testing.T) {
+	holder := utils.MustParseAddress("0x549c660ce2b988f588769d6ad87be801695b2be3")
+	usdc := utils.MustParseAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+	client := mocks.New().OnBatch().Return(&jsonrpc.RPCResponse{Result: "0x2", ID: 1})
+
+	got, err := NewFromRPCClient(client).GetBalanceBatch([]utils.Address{holder, usdc})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[0].Amount != nil || got[0].Error == nil {
+		t.Errorf("GetBalanceBatch() got[0] = %+v, want missing response error", got[0])
+	}
+	if got[1].Error != nil || got[1].Amount.Int64() != 2 {
+		t.Errorf("GetBalanceBatch() got[1] = %+v, want 2", got[1])
+	}
+}
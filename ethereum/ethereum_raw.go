@@ -11,102 +11,208 @@ import (
 
 const BaseApiUrl = "https://eth-mainnet.g.alchemy.com:443/v2/"
 
+// Network is the subdomain of an Alchemy endpoint, e.g. "eth-sepolia" or "polygon-mainnet".
+type Network string
+
+const (
+  Mainnet Network = "eth-mainnet"
+  Sepolia Network = "eth-sepolia"
+  Holesky Network = "eth-holesky"
+)
+
+// NetworkURL returns the Alchemy endpoint of apiKey on network.
+func NetworkURL(network Network, apiKey string) string {
+  return fmt.Sprintf("https://%v.g.alchemy.com:443/v2/%v", network, apiKey)
+}
+
+const (
+  EthBlockNumber         string = "eth_blockNumber"
+  EthGetCode                    = "eth_getCode"
+  EthGetBalance                 = "eth_getBalance"
+  EthGetLogs                    = "eth_getLogs"
+  EthGasPrice                   = "eth_gasPrice"
+  EthCall                       = "eth_call"
+  EthGetBlockByNumber           = "eth_getBlockByNumber"
+  EthGetBlockByHash             = "eth_getBlockByHash"
+  EthGetTransaction             = "eth_getTransactionByHash"
+  EthGetReceipt                 = "eth_getTransactionReceipt"
+  EthGetStorageAt               = "eth_getStorageAt"
+  EthGetProof                   = "eth_getProof"
+  EthGetTransactionCount        = "eth_getTransactionCount"
+)
+
 const (
-  EthBlockNumber string = "eth_blockNumber"
-  EthGetCode            = "eth_getCode"
-  EthGetBalance         = "eth_getBalance"
-  EthGetLogs            = "eth_getLogs"
-  EthGasPrice           = "eth_gasPrice"
+  TraceTransaction       string = "trace_transaction"
+  TraceBlock                    = "trace_block"
+  TraceFilter                   = "trace_filter"
+  TraceCall                     = "trace_call"
+  TraceReplayTransaction        = "trace_replayTransaction"
+)
+
+const (
+  DebugTraceTransaction   string = "debug_traceTransaction"
+  DebugTraceCall                  = "debug_traceCall"
+  DebugTraceBlockByNumber         = "debug_traceBlockByNumber"
+  DebugTraceBlockByHash           = "debug_traceBlockByHash"
 )
 
 type ETHClientRaw struct {
   client jsonrpc.RPCClient
 }
 
-func getRpcClient(apiKey string) jsonrpc.RPCClient {
+// NewRPCClient returns the JSON-RPC client for the Alchemy endpoint of apiKey.
+func NewRPCClient(apiKey string) jsonrpc.RPCClient {
   url := fmt.Sprintf("%v%v", BaseApiUrl, apiKey)
   return jsonrpc.NewClient(url)
 }
 
+// NewNetworkRPCClient returns the JSON-RPC client for the Alchemy endpoint of apiKey on network.
+func NewNetworkRPCClient(network Network, apiKey string) jsonrpc.RPCClient {
+  return jsonrpc.NewClient(NetworkURL(network, apiKey))
+}
+
 func NewETHClientRaw(apiKey string) *ETHClientRaw {
-  return &ETHClientRaw{getRpcClient(apiKey)}
+  return &ETHClientRaw{NewRPCClient(apiKey)}
 }
 
 func (c ETHClientRaw) GetBlockNumberRaw() (*jsonrpc.RPCResponse, error) {
   return c.client.Call(context.Background(), EthBlockNumber)
 }
 
-func (c ETHClientRaw) GetContractCodeRaw(address string, blockNumberOpt ...string) (*jsonrpc.RPCResponse, error) {
-  isValid := utils.CheckAddress(address)
-  if !isValid {
-    return nil, fmt.Errorf("invalid address %v", address)
-  }
+func (c ETHClientRaw) GetBlockByNumberRaw(blockNumber string) (*jsonrpc.RPCResponse, error) {
+  return c.client.Call(context.Background(), EthGetBlockByNumber, blockNumber, false)
+}
 
+func (c ETHClientRaw) GetBlockByHashRaw(blockHash utils.Hash) (*jsonrpc.RPCResponse, error) {
+  return c.client.Call(context.Background(), EthGetBlockByHash, blockHash.Hex(), false)
+}
+
+func (c ETHClientRaw) GetTransactionReceiptRaw(hash utils.Hash) (*jsonrpc.RPCResponse, error) {
+  return c.client.Call(context.Background(), EthGetReceipt, hash.Hex())
+}
+
+func (c ETHClientRaw) GetContractCodeRaw(address utils.Address, blockNumberOpt ...string) (*jsonrpc.RPCResponse, error) {
   blockNumber := Latest
   if len(blockNumberOpt) > 0 {
     blockNumber = blockNumberOpt[0]
   }
 
-  return c.client.Call(context.Background(), EthGetCode, address, blockNumber)
+  return c.client.Call(context.Background(), EthGetCode, address.Hex(), blockNumber)
+}
+
+func (c ETHClientRaw) GetStorageAtRaw(address utils.Address, slot utils.Hash, blockNumber string) (*jsonrpc.RPCResponse, error) {
+  return c.client.Call(context.Background(), EthGetStorageAt, address.Hex(), slot.Hex(), blockNumber)
+}
+
+func (c ETHClientRaw) GetTransactionCountRaw(address utils.Address, blockNumber string) (*jsonrpc.RPCResponse, error) {
+  return c.client.Call(context.Background(), EthGetTransactionCount, address.Hex(), blockNumber)
 }
 
-func (c ETHClientRaw) GetBalance(address string, blockNumberOpt ...string) (*jsonrpc.RPCResponse, error) {
-  isValid := utils.CheckAddress(address)
-  if !isValid {
-    return nil, fmt.Errorf("invalid address %v", address)
+func (c ETHClientRaw) GetProofRaw(address utils.Address, slots []utils.Hash, blockNumber string) (*jsonrpc.RPCResponse, error) {
+  if slots == nil {
+    slots = []utils.Hash{}
   }
+  return c.client.Call(context.Background(), EthGetProof, address.Hex(), slots, blockNumber)
+}
 
+func (c ETHClientRaw) GetBalance(address utils.Address, blockNumberOpt ...string) (*jsonrpc.RPCResponse, error) {
   blockNumber := Latest
   if len(blockNumberOpt) > 0 {
     blockNumber = blockNumberOpt[0]
   }
-  return c.client.Call(context.Background(), EthGetBalance, address, blockNumber)
+  return c.client.Call(context.Background(), EthGetBalance, address.Hex(), blockNumber)
 }
 
 func (c ETHClientRaw) GetLogs(request LogRequest) (*jsonrpc.RPCResponse, error) {
-  for _, address := range request.Address {
-    isValid := utils.CheckAddress(address)
-    if !isValid {
-      return nil, fmt.Errorf("invalid address %v", address)
-    }
+  if err := request.Validate(); err != nil {
+    return nil, err
   }
   params := make([]interface{}, 1)
   params[0] = request
   return c.client.Call(context.Background(), EthGetLogs, params)
 }
 
-func (c ETHClientRaw) GetContractCodeBatchRaw(addresses []string, blockNumberOpt ...string) (jsonrpc.RPCResponses, error) {
+func (c ETHClientRaw) GetContractCodeBatchRaw(addresses []utils.Address, blockNumberOpt ...string) (jsonrpc.RPCResponses, error) {
+  return c.addressBatch(context.Background(), EthGetCode, addresses, optBlockNumber(blockNumberOpt))
+}
+
+func (c ETHClientRaw) GetBalanceBatch(addresses []utils.Address, blockNumberOpt ...string) (jsonrpc.RPCResponses, error) {
+  return c.addressBatch(context.Background(), EthGetBalance, addresses, optBlockNumber(blockNumberOpt))
+}
+
+// addressBatch calls method for every address at blockNumber in one batch.
+// The response to the address at index i has ID i, see batchResponse.
+func (c ETHClientRaw) addressBatch(ctx context.Context, method string, addresses []utils.Address, blockNumber string) (jsonrpc.RPCResponses, error) {
+  requests := make(jsonrpc.RPCRequests, len(addresses))
+  for i, address := range addresses {
+    requests[i] = newBatchRequest(i, method, address.Hex(), blockNumber)
+  }
+  return batch.DoBatchCallContext(ctx, c.client, requests)
+}
+
+func (c ETHClientRaw) GetGasPrice() (*jsonrpc.RPCResponse, error) {
+  return c.client.Call(context.Background(), EthGasPrice)
+}
+
+func (c ETHClientRaw) Call(request CallRequest, blockNumberOpt ...string) (*jsonrpc.RPCResponse, error) {
   blockNumber := Latest
   if len(blockNumberOpt) > 0 {
     blockNumber = blockNumberOpt[0]
   }
-  requests := make(jsonrpc.RPCRequests, len(addresses))
-  for i, address := range addresses {
-    isValid := utils.CheckAddress(address)
-    if !isValid {
-      return nil, fmt.Errorf("invalid address %v", address)
-    }
-    requests[i] = &jsonrpc.RPCRequest{Method: EthGetCode, Params: jsonrpc.Params(addresses[i], blockNumber), ID: i, JSONRPC: "2.0"}
-  }
-  return batch.DoBatchCall(c.client, requests)
+  return c.client.Call(context.Background(), EthCall, request, blockNumber)
 }
 
-func (c ETHClientRaw) GetBalanceBatch(addresses []string, blockNumberOpt ...string) (jsonrpc.RPCResponses, error) {
+func (c ETHClientRaw) CallBatchRaw(calls []CallRequest, blockNumberOpt ...string) (jsonrpc.RPCResponses, error) {
   blockNumber := Latest
   if len(blockNumberOpt) > 0 {
     blockNumber = blockNumberOpt[0]
   }
-  requests := make(jsonrpc.RPCRequests, len(addresses))
-  for i, address := range addresses {
-    isValid := utils.CheckAddress(address)
-    if !isValid {
-      return nil, fmt.Errorf("invalid address %v", address)
-    }
-    requests[i] = &jsonrpc.RPCRequest{Method: EthGetBalance, Params: jsonrpc.Params(addresses[i], blockNumber), ID: i, JSONRPC: "2.0"}
+  requests := make(jsonrpc.RPCRequests, len(calls))
+  for i, call := range calls {
+    requests[i] = newBatchRequest(i, EthCall, call, blockNumber)
   }
   return batch.DoBatchCall(c.client, requests)
 }
 
-func (c ETHClientRaw) GetGasPrice() (*jsonrpc.RPCResponse, error) {
-  return c.client.Call(context.Background(), EthGasPrice)
+// newBatchRequest returns the request with the given id of a batch.
+func newBatchRequest(id int, method string, params ...interface{}) *jsonrpc.RPCRequest {
+  return &jsonrpc.RPCRequest{Method: method, Params: jsonrpc.Params(params...), ID: id, JSONRPC: "2.0"}
+}
+
+func (c ETHClientRaw) TraceTransactionRaw(hash utils.Hash) (*jsonrpc.RPCResponse, error) {
+  return c.client.Call(context.Background(), TraceTransaction, hash.Hex())
+}
+
+func (c ETHClientRaw) TraceBlockRaw(blockNumber string) (*jsonrpc.RPCResponse, error) {
+  return c.client.Call(context.Background(), TraceBlock, blockNumber)
+}
+
+func (c ETHClientRaw) TraceFilterRaw(request TraceFilterRequest) (*jsonrpc.RPCResponse, error) {
+  params := make([]interface{}, 1)
+  params[0] = request
+  return c.client.Call(context.Background(), TraceFilter, params)
+}
+
+func (c ETHClientRaw) TraceCallRaw(request CallRequest, traceTypes []TraceType, blockNumber string) (*jsonrpc.RPCResponse, error) {
+  return c.client.Call(context.Background(), TraceCall, request, traceTypes, blockNumber)
+}
+
+func (c ETHClientRaw) TraceReplayTransactionRaw(hash utils.Hash, traceTypes []TraceType) (*jsonrpc.RPCResponse, error) {
+  return c.client.Call(context.Background(), TraceReplayTransaction, hash.Hex(), traceTypes)
+}
+
+func (c ETHClientRaw) DebugTraceTransactionRaw(hash utils.Hash, options TraceOptions) (*jsonrpc.RPCResponse, error) {
+  return c.client.Call(context.Background(), DebugTraceTransaction, hash.Hex(), options)
+}
+
+func (c ETHClientRaw) DebugTraceCallRaw(request CallRequest, blockNumber string, options TraceOptions) (*jsonrpc.RPCResponse, error) {
+  return c.client.Call(context.Background(), DebugTraceCall, request, blockNumber, options)
+}
+
+func (c ETHClientRaw) DebugTraceBlockByNumberRaw(blockNumber string, options TraceOptions) (*jsonrpc.RPCResponse, error) {
+  return c.client.Call(context.Background(), DebugTraceBlockByNumber, blockNumber, options)
+}
+
+func (c ETHClientRaw) DebugTraceBlockByHashRaw(blockHash utils.Hash, options TraceOptions) (*jsonrpc.RPCResponse, error) {
+  return c.client.Call(context.Background(), DebugTraceBlockByHash, blockHash.Hex(), options)
 }
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/massigerardi/alchemy-api/mocks"
+	"github.com/massigerardi/alchemy-api/utils"
 
 	"github.com/ybbus/jsonrpc/v3"
 )
@@ -14,7 +15,7 @@ func TestEthClient_GetContractCodeBatchRaw(t *testing.T) {
 		client jsonrpc.RPCClient
 	}
 	type args struct {
-		addresses      []string
+		addresses      []utils.Address
 		blockNumberOpt []string
 	}
 
@@ -26,14 +27,14 @@ func TestEthClient_GetContractCodeBatchRaw(t *testing.T) {
 		wantErr bool
 	}{
 		{name: "Test Success", fields: fields{client: mocks.GetMockClient()}, args: args{
-			addresses:      []string{"0x549c660ce2b988f588769d6ad87be801695b2be3", "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"},
+			addresses:      []utils.Address{utils.MustParseAddress("0x549c660ce2b988f588769d6ad87be801695b2be3"), utils.MustParseAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")},
 			blockNumberOpt: nil,
 		}, want: jsonrpc.RPCResponses{
 			&jsonrpc.RPCResponse{Result: mocks.EoaCode, Error: nil, ID: 0},
 			&jsonrpc.RPCResponse{Result: mocks.UsdcCode, Error: nil, ID: 1},
 		}},
 		{name: "Test Error", fields: fields{client: mocks.GetMockClient()}, args: args{
-			addresses:      []string{"0x549c660ce2b988f588769d6ad87be801695b2be1", "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"},
+			addresses:      []utils.Address{utils.MustParseAddress("0x549c660ce2b988f588769d6ad87be801695b2be1"), utils.MustParseAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")},
 			blockNumberOpt: nil,
 		}, want: jsonrpc.RPCResponses{
 			&jsonrpc.RPCResponse{Result: nil, Error: &jsonrpc.RPCError{Code: -123, Message: "wrong Response", Data: nil}, ID: 0},
@@ -0,0 +1,140 @@
+package ethereum
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/massigerardi/alchemy-api/internal/pipeline"
+	"github.com/massigerardi/alchemy-api/utils"
+)
+
+const (
+	DefaultStreamChunkSize   = 100
+	DefaultStreamConcurrency = 2
+)
+
+type StreamConfig struct {
+	// ChunkSize is the number of addresses per batch, DefaultStreamChunkSize if zero.
+	ChunkSize int
+	// Concurrency bounds the number of batches in flight, DefaultStreamConcurrency if zero.
+	Concurrency int
+	// BlockNumber is the block the state is read at, Latest if empty.
+	BlockNumber string
+}
+
+func (c StreamConfig) withDefaults() StreamConfig {
+	if c.ChunkSize <= 0 {
+		c.ChunkSize = DefaultStreamChunkSize
+	}
+	if c.Concurrency <= 0 {
+		c.Concurrency = DefaultStreamConcurrency
+	}
+	if c.BlockNumber == "" {
+		c.BlockNumber = Latest
+	}
+	return c
+}
+
+// StreamBalances reads addresses until the channel is closed, fetches their
+// balances in batches of config.ChunkSize and sends the responses to results
+// in the order of addresses. A chunk is sent once full or once addresses is
+// closed. At most config.Concurrency batches are in flight, and none is
+// started while results is not drained, so memory stays bounded whatever
+// the number of addresses.
+//
+// Failed addresses are handled according to the batch policy of the client,
+// see GetBalanceBatch. StreamBalances returns nil once every response was
+// sent, or the first batch error or ctx error otherwise. results is not
+// closed.
+func (c EthClient) StreamBalances(ctx context.Context, addresses <-chan utils.Address, results chan<- *BalanceResponse, config StreamConfig) error {
+	config = config.withDefaults()
+	return stream(ctx, addresses, results, config, func(ctx context.Context, chunk []utils.Address) ([]*BalanceResponse, error) {
+		responses, err := c.client.addressBatch(ctx, EthGetBalance, chunk, config.BlockNumber)
+		if err != nil {
+			return nil, err
+		}
+		return c.balanceResponses(chunk, responses)
+	})
+}
+
+// StreamContractCodes is the eth_getCode counterpart of StreamBalances.
+func (c EthClient) StreamContractCodes(ctx context.Context, addresses <-chan utils.Address, results chan<- *ContractCodeResponse, config StreamConfig) error {
+	config = config.withDefaults()
+	return stream(ctx, addresses, results, config, func(ctx context.Context, chunk []utils.Address) ([]*ContractCodeResponse, error) {
+		responses, err := c.client.addressBatch(ctx, EthGetCode, chunk, config.BlockNumber)
+		if err != nil {
+			return nil, err
+		}
+		return c.contractCodeResponses(chunk, responses)
+	})
+}
+
+// IterateAddresses sends the addresses returned by next to the returned
+// channel until next reports false or ctx is done, then closes it. It adapts
+// an iterator, e.g. over a file or a database cursor, to the Stream methods.
+func IterateAddresses(ctx context.Context, next func() (utils.Address, bool)) <-chan utils.Address {
+	addresses := make(chan utils.Address)
+	go func() {
+		defer close(addresses)
+		for {
+			address, ok := next()
+			if !ok {
+				return
+			}
+			select {
+			case addresses <- address:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return addresses
+}
+
+// stream fetches chunks of addresses concurrently and sends their results in order.
+func stream[T any](ctx context.Context, addresses <-chan utils.Address, results chan<- T, config StreamConfig, fetch func(ctx context.Context, chunk []utils.Address) ([]T, error)) error {
+	closed := false
+	next := func(ctx context.Context) ([]utils.Address, bool) {
+		if closed {
+			return nil, false
+		}
+		chunk, more := readChunk(ctx, addresses, config.ChunkSize)
+		closed = !more
+		return chunk, len(chunk) > 0
+	}
+	fetchChunk := func(ctx context.Context, chunk []utils.Address) ([]T, error) {
+		values, err := fetch(ctx, chunk)
+		if err != nil {
+			return nil, fmt.Errorf("batch of %v addresses from %v: %w", len(chunk), chunk[0], err)
+		}
+		return values, nil
+	}
+	return pipeline.Ordered(ctx, config.Concurrency, next, fetchChunk, func(ctx context.Context, _ []utils.Address, values []T) error {
+		for _, value := range values {
+			select {
+			case results <- value:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return nil
+	})
+}
+
+// readChunk reads up to size addresses, reporting false once addresses is
+// closed or ctx is done.
+func readChunk(ctx context.Context, addresses <-chan utils.Address, size int) ([]utils.Address, bool) {
+	chunk := make([]utils.Address, 0, size)
+	for len(chunk) < size {
+		select {
+		case address, ok := <-addresses:
+			if !ok {
+				return chunk, false
+			}
+			chunk = append(chunk, address)
+		case <-ctx.Done():
+			return nil, false
+		}
+	}
+	return chunk, true
+}
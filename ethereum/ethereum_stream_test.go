@@ -0,0 +1,179 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/massigerardi/alchemy-api/mocks"
+	"github.com/massigerardi/alchemy-api/utils"
+	"github.com/ybbus/jsonrpc/v3"
+)
+
+func streamAddresses(n int) []utils.Address {
+	addresses := make([]utils.Address, n)
+	for i := range addresses {
+		addresses[i][18], addresses[i][19] = byte(i>>8), byte(i)
+	}
+	return addresses
+}
+
+func sendAddresses(addresses []utils.Address) <-chan utils.Address {
+	i := 0
+	return IterateAddresses(context.Background(), func() (utils.Address, bool) {
+		if i == len(addresses) {
+			return utils.Address{}, false
+		}
+		i++
+		return addresses[i-1], true
+	})
+}
+
+func batchedRequests(client *mocks.Client) int {
+	requests := 0
+	for _, call := range client.Calls() {
+		if call.Batch {
+			requests++
+		}
+	}
+	return requests
+}
+
+func TestEthClient_StreamBalances(t *testing.T) {
+	client := mocks.New().On(EthGetBalance, mocks.Any, "0x10").Return("0x1")
+	c := NewFromRPCClient(client)
+	addresses := streamAddresses(250)
+
+	results := make(chan *BalanceResponse)
+	done := make(chan error, 1)
+	go func() {
+		done <- c.StreamBalances(context.Background(), sendAddresses(addresses), results, StreamConfig{ChunkSize: 100, BlockNumber: "0x10"})
+		close(results)
+	}()
+	i := 0
+	for response := range results {
+		if response.Address != addresses[i] || response.Error != nil || response.Amount.Int64() != 1 {
+			t.Fatalf("StreamBalances() got[%v] = %+v, want %v", i, response, addresses[i])
+		}
+		i++
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("StreamBalances() error = %v", err)
+	}
+	if i != len(addresses) {
+		t.Errorf("StreamBalances() sent %v responses, want %v", i, len(addresses))
+	}
+	if got := client.CallCount(EthGetBalance); got != len(addresses) {
+		t.Errorf("CallCount() got = %v, want %v", got, len(addresses))
+	}
+}
+
+func TestEthClient_StreamContractCodes(t *testing.T) {
+	client := mocks.New().
+		On(EthGetCode, utils.Address{}, Latest).ReturnError(-32000, "header not found").
+		On(EthGetCode).Return(mocks.UsdcCode)
+	addresses := streamAddresses(5)
+
+	results := make(chan *ContractCodeResponse, len(addresses))
+	err := NewFromRPCClient(client).StreamContractCodes(context.Background(), sendAddresses(addresses), results, StreamConfig{ChunkSize: 2})
+	if err != nil {
+		t.Fatalf("StreamContractCodes() error = %v", err)
+	}
+	close(results)
+	var got []*ContractCodeResponse
+	for response := range results {
+		got = append(got, response)
+	}
+	if len(got) != 5 || got[0].Error == nil || got[1].Code != mocks.UsdcCode || got[4].Address != addresses[4] {
+		t.Errorf("StreamContractCodes() got = %v", got)
+	}
+	if requests := batchedRequests(client); requests != 5 {
+		t.Errorf("StreamContractCodes() sent %v batched requests, want 5", requests)
+	}
+
+	err = NewFromRPCClient(client, WithBatchPolicy(FailFast)).StreamContractCodes(context.Background(), sendAddresses(addresses), make(chan *ContractCodeResponse, 5), StreamConfig{ChunkSize: 2})
+	var responseError *ResponseError
+	if !errors.As(err, &responseError) || responseError.Code != -32000 {
+		t.Errorf("StreamContractCodes() error = %v, want header not found", err)
+	}
+}
+
+func TestEthClient_StreamBalances_BackPressure(t *testing.T) {
+	client := mocks.New().On(EthGetBalance).Return("0x1")
+	ctx, cancel := context.WithCancel(context.Background())
+	addresses := IterateAddresses(ctx, func() (utils.Address, bool) { return utils.Address{}, true })
+	results := make(chan *BalanceResponse)
+	done := make(chan error, 1)
+	go func() {
+		done <- NewFromRPCClient(client).StreamBalances(ctx, addresses, results, StreamConfig{ChunkSize: 10, Concurrency: 2})
+	}()
+	<-results
+	time.Sleep(50 * time.Millisecond)
+	if requests := batchedRequests(client); requests > 20 {
+		t.Errorf("StreamBalances() sent %v requests while results were not drained, want at most 2 batches of 10", requests)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("StreamBalances() error = %v, want %v", err, context.Canceled)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("StreamBalances() did not return after ctx was canceled")
+	}
+}
+
+func TestEthClient_StreamBalances_Error(t *testing.T) {
+	failure := errors.New("connection refused")
+	client := mocks.New().
+		OnBatch().Once().Return().
+		OnBatch().Error(failure)
+
+	results := make(chan *BalanceResponse, 10)
+	err := NewFromRPCClient(client).StreamBalances(context.Background(), sendAddresses(streamAddresses(4)), results, StreamConfig{ChunkSize: 2, Concurrency: 1})
+	if !errors.Is(err, failure) {
+		t.Errorf("StreamBalances() error = %v, want %v", err, failure)
+	}
+	if len(results) != 2 || (<-results).Error == nil {
+		t.Errorf("StreamBalances() sent %v responses, want the 2 missing responses of the first batch", len(results))
+	}
+}
+
+// reversedBatches answers batches with their responses in reverse order.
+type reversedBatches struct {
+	jsonrpc.RPCClient
+}
+
+func (c reversedBatches) CallBatch(ctx context.Context, requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error) {
+	responses, err := c.RPCClient.CallBatch(ctx, requests)
+	slices.Reverse(responses)
+	return responses, err
+}
+
+func TestEthClient_StreamBalances_OutOfOrder(t *testing.T) {
+	addresses := streamAddresses(10)
+	client := mocks.New()
+	for i, address := range addresses {
+		client.On(EthGetBalance, address, Latest).Return(utils.EncodeQuantity(uint64(i)))
+	}
+	c := NewFromRPCClient(reversedBatches{client})
+
+	results := make(chan *BalanceResponse, len(addresses))
+	if err := c.StreamBalances(context.Background(), sendAddresses(addresses), results, StreamConfig{ChunkSize: 4}); err != nil {
+		t.Fatal(err)
+	}
+	close(results)
+	i := 0
+	for response := range results {
+		if response.Address != addresses[i] || response.Error != nil || response.Amount.Int64() != int64(i) {
+			t.Errorf("StreamBalances() got[%v] = %+v, want a balance of %v", i, response, i)
+		}
+		i++
+	}
+	if i != len(addresses) {
+		t.Errorf("StreamBalances() sent %v responses, want %v", i, len(addresses))
+	}
+}
@@ -2,17 +2,89 @@ package ethereum
 
 import (
   "fmt"
+  "log/slog"
   "math/big"
 
+  "github.com/massigerardi/alchemy-api/middleware"
   "github.com/massigerardi/alchemy-api/utils"
+  "github.com/ybbus/jsonrpc/v3"
 )
 
 type EthClient struct {
-  client *ETHClientRaw
+  client      *ETHClientRaw
+  batchPolicy BatchPolicy
 }
 
-func New(apiKey string) *EthClient {
-  return &EthClient{client: NewETHClientRaw(apiKey)}
+type options struct {
+  middlewares []middleware.Middleware
+  logger      *slog.Logger
+  apiKey      string
+  batchPolicy BatchPolicy
+  retry       *middleware.RetryConfig
+}
+
+type Option func(*options)
+
+// WithMiddleware wraps the RPC client in middlewares, the first being the outermost.
+func WithMiddleware(middlewares ...middleware.Middleware) Option {
+  return func(o *options) {
+    o.middlewares = append(o.middlewares, middlewares...)
+  }
+}
+
+// WithLogger logs every request sent to the RPC client, see middleware.Logging.
+// The API key given to New is redacted from the log.
+func WithLogger(logger *slog.Logger) Option {
+  return func(o *options) {
+    o.logger = logger
+  }
+}
+
+// WithRetry retries failed calls, see middleware.Retry. Retries are logged
+// to the logger of WithLogger unless config has its own Logger, the API key
+// given to New being redacted from either.
+func WithRetry(config middleware.RetryConfig) Option {
+  return func(o *options) {
+    o.retry = &config
+  }
+}
+
+// WithAPIKey sets the API key redacted from the logs, e.g. of a client
+// created with NewFromRPCClient around NewRPCClient. New sets it.
+func WithAPIKey(apiKey string) Option {
+  return func(o *options) {
+    o.apiKey = apiKey
+  }
+}
+
+func New(apiKey string, opts ...Option) *EthClient {
+  opts = append([]Option{WithAPIKey(apiKey)}, opts...)
+  return NewFromRPCClient(NewRPCClient(apiKey), opts...)
+}
+
+// NewFromRPCClient returns a client sending its requests through rpcClient,
+// e.g. a client created with NewRPCClient and wrapped in a cache.
+func NewFromRPCClient(rpcClient jsonrpc.RPCClient, opts ...Option) *EthClient {
+  var o options
+  for _, opt := range opts {
+    opt(&o)
+  }
+  middlewares := o.middlewares[:len(o.middlewares):len(o.middlewares)]
+  if o.retry != nil {
+    config := *o.retry
+    if config.Logger == nil {
+      config.Logger = o.logger
+    }
+    if config.Logger != nil {
+      config.Logger = middleware.Redact(config.Logger, o.apiKey)
+    }
+    middlewares = append(middlewares, middleware.Retry(config))
+  }
+  if o.logger != nil {
+    // innermost, so that every retried attempt is logged
+    middlewares = append(middlewares[:len(middlewares):len(middlewares)], middleware.Logging(middleware.Redact(o.logger, o.apiKey)))
+  }
+  return &EthClient{client: &ETHClientRaw{middleware.Chain(rpcClient, middlewares...)}, batchPolicy: o.batchPolicy}
 }
 
 func (c EthClient) GetBlockNumber() (string, error) {
@@ -30,7 +102,63 @@ func (c EthClient) GetBlockNumber() (string, error) {
   return result, nil
 }
 
-func (c EthClient) GetContractCode(address string, blockNumberOpt ...string) (string, error) {
+func (c EthClient) GetBlockByNumber(blockNumber string) (*Block, error) {
+  response, err := c.client.GetBlockByNumberRaw(blockNumber)
+  if err != nil {
+    return nil, err
+  }
+  return getBlock(response, blockNumber)
+}
+
+func (c EthClient) GetBlockByHash(blockHash utils.Hash) (*Block, error) {
+  response, err := c.client.GetBlockByHashRaw(blockHash)
+  if err != nil {
+    return nil, err
+  }
+  return getBlock(response, blockHash.Hex())
+}
+
+func getBlock(response *jsonrpc.RPCResponse, block string) (*Block, error) {
+  if response.Error != nil {
+    return nil, fmt.Errorf("remote Error: %v", response.Error.Error())
+  }
+  if response.Result == nil {
+    return nil, fmt.Errorf("block %v not found", block)
+  }
+  var result Block
+  err := response.GetObject(&result)
+  if err != nil {
+    return nil, err
+  }
+  return &result, nil
+}
+
+// GetTransactionReceipt returns the receipt of the transaction hash, an error
+// if the transaction is unknown or still pending.
+func (c EthClient) GetTransactionReceipt(hash utils.Hash) (*Receipt, error) {
+  response, err := c.client.GetTransactionReceiptRaw(hash)
+  if err != nil {
+    return nil, err
+  }
+  return getReceipt(response, hash.Hex())
+}
+
+func getReceipt(response *jsonrpc.RPCResponse, hash string) (*Receipt, error) {
+  if response.Error != nil {
+    return nil, fmt.Errorf("remote Error: %v", response.Error.Error())
+  }
+  if response.Result == nil {
+    return nil, fmt.Errorf("receipt of %v not found", hash)
+  }
+  var result Receipt
+  err := response.GetObject(&result)
+  if err != nil {
+    return nil, err
+  }
+  return &result, nil
+}
+
+func (c EthClient) GetContractCode(address utils.Address, blockNumberOpt ...string) (string, error) {
   response, err := c.client.GetContractCodeRaw(address, blockNumberOpt...)
   if err != nil {
     return "", err
@@ -38,7 +166,7 @@ func (c EthClient) GetContractCode(address string, blockNumberOpt ...string) (st
   return utils.GetString(response)
 }
 
-func (c EthClient) GetBalance(address string, blockNumberOpt ...string) (*big.Int, error) {
+func (c EthClient) GetBalance(address utils.Address, blockNumberOpt ...string) (*big.Int, error) {
   response, err := c.client.GetBalance(address, blockNumberOpt...)
   if err != nil {
     return nil, err
@@ -62,6 +190,22 @@ func (c EthClient) GetLogs(request LogRequest) (*LogsResponse, error) {
   return &results[0], nil
 }
 
+func (c EthClient) FilterLogs(request LogRequest) (LogsResponses, error) {
+  response, err := c.client.GetLogs(request)
+  if err != nil {
+    return nil, err
+  }
+  if response.Error != nil {
+    return nil, fmt.Errorf("remote Error: %v", response.Error.Error())
+  }
+  var results LogsResponses
+  err = response.GetObject(&results)
+  if err != nil {
+    return nil, err
+  }
+  return results, nil
+}
+
 func (c EthClient) GetGasPrice() (*big.Int, error) {
   response, err := c.client.GetGasPrice()
   if err != nil {
@@ -73,3 +217,11 @@ func (c EthClient) GetGasPrice() (*big.Int, error) {
   }
   return result, nil
 }
+
+func (c EthClient) Call(request CallRequest, blockNumberOpt ...string) (string, error) {
+  response, err := c.client.Call(request, blockNumberOpt...)
+  if err != nil {
+    return "", err
+  }
+  return utils.GetString(response)
+}
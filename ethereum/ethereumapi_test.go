@@ -7,6 +7,7 @@ import (
   "testing"
 
   "github.com/massigerardi/alchemy-api/mocks"
+  "github.com/massigerardi/alchemy-api/utils"
   "github.com/ybbus/jsonrpc/v3"
 )
 
@@ -69,7 +70,7 @@ func TestEthClient_GetContractCode(t *testing.T) {
     client jsonrpc.RPCClient
   }
   type args struct {
-    address        string
+    address        utils.Address
     blockNumberOpt []string
   }
   tests := []struct {
@@ -79,10 +80,9 @@ func TestEthClient_GetContractCode(t *testing.T) {
     want    string
     wantErr bool
   }{
-    {name: "Not Contract Result", fields: fields{client: mocks.GetMockClient()}, args: args{address: "0x549c660ce2b988f588769d6ad87be801695b2be3"}, want: eoaCode, wantErr: false},
-    {name: "USDC Contract Result", fields: fields{client: mocks.GetMockClient()}, args: args{address: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"}, want: usdcCode, wantErr: false},
-    {name: "Not USDC", fields: fields{client: mocks.GetMockClient()}, args: args{address: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB49"}, want: "0x", wantErr: false},
-    {name: "Wrong address", fields: fields{client: mocks.GetMockClient()}, args: args{address: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE360"}, want: "", wantErr: true},
+    {name: "Not Contract Result", fields: fields{client: mocks.GetMockClient()}, args: args{address: utils.MustParseAddress("0x549c660ce2b988f588769d6ad87be801695b2be3")}, want: eoaCode, wantErr: false},
+    {name: "USDC Contract Result", fields: fields{client: mocks.GetMockClient()}, args: args{address: utils.MustParseAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")}, want: usdcCode, wantErr: false},
+    {name: "Not USDC", fields: fields{client: mocks.GetMockClient()}, args: args{address: utils.MustParseAddress("0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb49")}, want: "0x", wantErr: false},
   }
   for _, tt := range tests {
     t.Run(tt.name, func(t *testing.T) {
@@ -106,7 +106,7 @@ func TestEthClient_GetBalance(t *testing.T) {
     client jsonrpc.RPCClient
   }
   type args struct {
-    address        string
+    address        utils.Address
     blockNumberOpt []string
   }
   tests := []struct {
@@ -116,19 +116,11 @@ func TestEthClient_GetBalance(t *testing.T) {
     want    *big.Int
     wantErr bool
   }{
-    {
-      name:   "Wrong Address",
-      fields: fields{client: mocks.GetMockClient()},
-      args: args{
-        address:        "0x549c660ce2b988f588769d6",
-        blockNumberOpt: nil},
-      wantErr: true,
-    },
     {
       name:   "Positive Balance",
       fields: fields{client: mocks.GetMockClient()},
       args: args{
-        address:        "0x549c660ce2b988f588769d6ad87be801695b2be3",
+        address:        utils.MustParseAddress("0x549c660ce2b988f588769d6ad87be801695b2be3"),
         blockNumberOpt: nil},
       want: big.NewInt(20066469208092992),
     },
@@ -136,7 +128,7 @@ func TestEthClient_GetBalance(t *testing.T) {
       name:   "Error Balance",
       fields: fields{client: mocks.GetMockClient()},
       args: args{
-        address:        "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48",
+        address:        utils.MustParseAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"),
         blockNumberOpt: nil},
       wantErr: true,
     },
@@ -144,7 +136,7 @@ func TestEthClient_GetBalance(t *testing.T) {
       name:   "Remote Error",
       fields: fields{client: mocks.GetMockClient()},
       args: args{
-        address:        "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB47",
+        address:        utils.MustParseAddress("0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb47"),
         blockNumberOpt: nil},
       wantErr: true,
     },
@@ -152,7 +144,7 @@ func TestEthClient_GetBalance(t *testing.T) {
       name:   "Zero Balance",
       fields: fields{client: mocks.GetMockClient()},
       args: args{
-        address:        "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB49",
+        address:        utils.MustParseAddress("0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb49"),
         blockNumberOpt: nil},
       want:    big.NewInt(0),
       wantErr: false,
@@ -168,7 +160,7 @@ func TestEthClient_GetBalance(t *testing.T) {
         t.Errorf("GetBalance() error = %v, wantErr %v", err, tt.wantErr)
         return
       }
-      if !reflect.DeepEqual(got, tt.want) {
+      if (got == nil) != (tt.want == nil) || got != nil && got.Cmp(tt.want) != 0 {
         t.Errorf("GetBalance() got = %v, want %v", got, tt.want)
       }
     })
@@ -189,12 +181,12 @@ func TestEthClient_GetLogs(t *testing.T) {
 
   }
 
-  address := make([]string, 1)
-  address[0] = "0xb59f67a8bff5d8cd03f6ac17265c550ed8f33907"
-  topics := make([]string, 3)
-  topics[0] = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
-  topics[1] = "0x00000000000000000000000000b46c2526e227482e2ebb8f4c69e4674d262e75"
-  topics[2] = "0x00000000000000000000000054a2d42a40f51259dedd1978f6c118a0f0eff078"
+  address := make([]utils.Address, 1)
+  address[0] = utils.MustParseAddress("0xb59f67a8bff5d8cd03f6ac17265c550ed8f33907")
+  topics := make([]utils.Hash, 3)
+  topics[0] = utils.MustParseHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")
+  topics[1] = utils.MustParseHash("0x00000000000000000000000000b46c2526e227482e2ebb8f4c69e4674d262e75")
+  topics[2] = utils.MustParseHash("0x00000000000000000000000054a2d42a40f51259dedd1978f6c118a0f0eff078")
 
   tests := []struct {
     name    string
@@ -257,3 +249,42 @@ func TestEthClient_GetGasPrice(t *testing.T) {
     })
   }
 }
+
+func TestEthClient_GetBlockByNumber(t *testing.T) {
+  type fields struct {
+    client jsonrpc.RPCClient
+  }
+
+  var want Block
+  err := json.Unmarshal([]byte(mocks.BlockJS), &want)
+  if err != nil {
+    t.Fatal(err)
+  }
+
+  tests := []struct {
+    name        string
+    fields      fields
+    blockNumber string
+    want        *Block
+    wantErr     bool
+  }{
+    {name: "Test Success", fields: fields{client: mocks.GetMockClient()}, blockNumber: "0x429d3b", want: &want},
+    {name: "Test Not Found", fields: fields{client: mocks.GetMockClient()}, blockNumber: "0x1", wantErr: true},
+    {name: "Test Remote Error", fields: fields{client: mocks.GetMockClient()}, blockNumber: Pending, wantErr: true},
+  }
+  for _, tt := range tests {
+    t.Run(tt.name, func(t *testing.T) {
+      c := EthClient{
+        client: &ETHClientRaw{tt.fields.client},
+      }
+      got, err := c.GetBlockByNumber(tt.blockNumber)
+      if (err != nil) != tt.wantErr {
+        t.Errorf("GetBlockByNumber() error = %v, wantErr %v", err, tt.wantErr)
+        return
+      }
+      if !reflect.DeepEqual(got, tt.want) {
+        t.Errorf("GetBlockByNumber() got = %v, want %v", got, tt.want)
+      }
+    })
+  }
+}
@@ -0,0 +1,172 @@
+package ethereum
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/massigerardi/alchemy-api/trie"
+	"github.com/massigerardi/alchemy-api/utils"
+	"github.com/ybbus/jsonrpc/v3"
+)
+
+// AccountProof is the result of eth_getProof: the state of an account and
+// of some of its storage slots, as claimed by the node, with the trie nodes
+// proving it. Use Verify to check the claims against a trusted state root.
+type AccountProof struct {
+	Address      utils.Address   `json:"address"`
+	AccountProof []string        `json:"accountProof"`
+	Balance      string          `json:"balance"`
+	Nonce        string          `json:"nonce"`
+	CodeHash     utils.Hash      `json:"codeHash"`
+	StorageHash  utils.Hash      `json:"storageHash"`
+	StorageProof []*StorageProof `json:"storageProof"`
+}
+
+// StorageProof proves the value of a storage slot, Key being the slot as
+// requested and Value the word stored there as a quantity.
+type StorageProof struct {
+	Key   string   `json:"key"`
+	Value string   `json:"value"`
+	Proof []string `json:"proof"`
+}
+
+// VerifiedAccount is the state of an account proven by an AccountProof.
+type VerifiedAccount struct {
+	Address     utils.Address
+	Balance     *big.Int
+	Nonce       uint64
+	CodeHash    utils.Hash
+	StorageHash utils.Hash
+	Storage     map[utils.Hash]utils.Hash
+}
+
+// GetProof returns the proof of the account at address and of its storage
+// at slots.
+func (c EthClient) GetProof(address utils.Address, slots []utils.Hash, blockNumberOpt ...string) (*AccountProof, error) {
+	response, err := c.client.GetProofRaw(address, slots, optBlockNumber(blockNumberOpt))
+	if err != nil {
+		return nil, err
+	}
+	return getProof(response)
+}
+
+// GetProof adds an eth_getProof call to the batch.
+func (b *Batch) GetProof(address utils.Address, slots []utils.Hash, blockNumberOpt ...string) *Future[*AccountProof] {
+	if slots == nil {
+		slots = []utils.Hash{}
+	}
+	return Add(b, getProof, EthGetProof, address.Hex(), slots, optBlockNumber(blockNumberOpt))
+}
+
+func getProof(response *jsonrpc.RPCResponse) (*AccountProof, error) {
+	proof, err := decodeObject[*AccountProof](response)
+	if err == nil && proof == nil {
+		err = fmt.Errorf("no proof returned")
+	}
+	return proof, err
+}
+
+// GetVerifiedAccount returns the state of the account at address and of its
+// storage at slots, verified against the state root of the block rather
+// than trusted from the node. The proof is read at the number of the block
+// so that block tags such as Latest refer to a single block.
+func (c EthClient) GetVerifiedAccount(address utils.Address, slots []utils.Hash, blockNumber string) (*VerifiedAccount, error) {
+	block, err := c.GetBlockByNumber(blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	stateRoot, err := utils.ParseHash(block.StateRoot)
+	if err != nil {
+		return nil, fmt.Errorf("state root of block %v: %w", blockNumber, err)
+	}
+	proof, err := c.GetProof(address, slots, block.Number)
+	if err != nil {
+		return nil, err
+	}
+	return proof.Verify(stateRoot)
+}
+
+// Verify checks the proof against stateRoot, the state root of the block it
+// was read at, and returns the proven state. An error wrapping
+// trie.ErrInvalidProof is returned if a proof is invalid or if a value
+// claimed by the node differs from the proven one.
+func (p *AccountProof) Verify(stateRoot utils.Hash) (*VerifiedAccount, error) {
+	nodes, err := decodeProofNodes(p.AccountProof)
+	if err != nil {
+		return nil, fmt.Errorf("account proof of %v: %w", p.Address, err)
+	}
+	account, err := trie.VerifyAccount(stateRoot, p.Address, nodes)
+	if err != nil {
+		return nil, fmt.Errorf("account proof of %v: %w", p.Address, err)
+	}
+	balance, err := utils.DecodeBigQuantity(p.Balance)
+	if err != nil || balance.Cmp(account.Balance) != 0 {
+		return nil, proofMismatch(p.Address, "balance", p.Balance, account.Balance)
+	}
+	nonce, err := utils.DecodeQuantity(p.Nonce)
+	if err != nil || nonce != account.Nonce {
+		return nil, proofMismatch(p.Address, "nonce", p.Nonce, account.Nonce)
+	}
+	if p.CodeHash != account.CodeHash {
+		return nil, proofMismatch(p.Address, "code hash", p.CodeHash, account.CodeHash)
+	}
+	if p.StorageHash != account.StorageRoot {
+		return nil, proofMismatch(p.Address, "storage hash", p.StorageHash, account.StorageRoot)
+	}
+
+	verified := &VerifiedAccount{
+		Address:     p.Address,
+		Balance:     account.Balance,
+		Nonce:       account.Nonce,
+		CodeHash:    account.CodeHash,
+		StorageHash: account.StorageRoot,
+		Storage:     make(map[utils.Hash]utils.Hash, len(p.StorageProof)),
+	}
+	for _, storage := range p.StorageProof {
+		slot, word, err := storage.verify(account.StorageRoot)
+		if err != nil {
+			return nil, fmt.Errorf("storage proof of %v at %v: %w", p.Address, storage.Key, err)
+		}
+		verified.Storage[slot] = word
+	}
+	return verified, nil
+}
+
+func (s *StorageProof) verify(storageRoot utils.Hash) (utils.Hash, utils.Hash, error) {
+	key, err := utils.DecodeBigQuantity(s.Key)
+	if err != nil || key.BitLen() > 256 {
+		return utils.Hash{}, utils.Hash{}, fmt.Errorf("invalid slot %v", s.Key)
+	}
+	slot := utils.BigToHash(key)
+	nodes, err := decodeProofNodes(s.Proof)
+	if err != nil {
+		return utils.Hash{}, utils.Hash{}, err
+	}
+	word, err := trie.VerifyStorage(storageRoot, slot, nodes)
+	if err != nil {
+		return utils.Hash{}, utils.Hash{}, err
+	}
+	value, err := utils.DecodeBigQuantity(s.Value)
+	if err != nil || value.BitLen() > 256 || utils.BigToHash(value) != word {
+		return utils.Hash{}, utils.Hash{}, fmt.Errorf("%w: value %v, proven %v", trie.ErrInvalidProof, s.Value, word)
+	}
+	return slot, word, nil
+}
+
+func decodeProofNodes(proof []string) ([][]byte, error) {
+	nodes := make([][]byte, len(proof))
+	for i, node := range proof {
+		data, err := hex.DecodeString(strings.TrimPrefix(node, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("%w: node %v is not hex", trie.ErrInvalidProof, i)
+		}
+		nodes[i] = data
+	}
+	return nodes, nil
+}
+
+func proofMismatch(address utils.Address, field string, claimed interface{}, proven interface{}) error {
+	return fmt.Errorf("account proof of %v: %w: %v %v, proven %v", address, trie.ErrInvalidProof, field, claimed, proven)
+}
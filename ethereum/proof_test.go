@@ -0,0 +1,108 @@
+package ethereum
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/massigerardi/alchemy-api/mocks"
+	"github.com/massigerardi/alchemy-api/trie"
+	"github.com/massigerardi/alchemy-api/utils"
+)
+
+// The nodes of newTestProof: a state trie whose only leaf is batchUsdc, with
+// a nonce of 1, a balance of 1500000000 and the code 0x6080, and its storage
+// trie whose only leaf is 0x2a at slot 1.
+const (
+	testStateNode   = "0xf86ea1207b5855bb92cd7f3f78137497df02f6ccb9badda93d9782e0f230c807ba728be0b84af848018459682f00a0fcbdb9e7191a6bc6efbe2e1903a50bd3c79312366db1e46acf7e94788c2b4c3ea01a578b7a4b0b5755db6d121b4118d4bc68fe170dca840c59bc922f14175a76b0"
+	testStorageNode = "0xe3a120b10e2d527612073b26eecdfd717e6a320cf44b4afac2b0732d9fcbe2b7fa0cf62a"
+)
+
+// newTestProof returns a valid proof of batchUsdc holding 0x2a at slot 1,
+// and the state root it is valid for.
+func newTestProof() (*AccountProof, utils.Hash) {
+	proof := &AccountProof{
+		Address:      batchUsdc,
+		AccountProof: []string{testStateNode},
+		Balance:      "0x59682f00",
+		Nonce:        "0x1",
+		CodeHash:     utils.Keccak256Hash([]byte{0x60, 0x80}),
+		StorageHash:  utils.MustParseHash("0xfcbdb9e7191a6bc6efbe2e1903a50bd3c79312366db1e46acf7e94788c2b4c3e"),
+		StorageProof: []*StorageProof{
+			{Key: "0x1", Value: "0x2a", Proof: []string{testStorageNode}},
+			{Key: utils.Slot(2).Hex(), Value: "0x0", Proof: []string{testStorageNode}},
+		},
+	}
+	return proof, utils.MustParseHash("0xe29c9ac3ac6fa0371bd893eefe7433f46420a99a1365f7591a864b2415eb4cc6")
+}
+
+func TestEthClient_GetVerifiedAccount(t *testing.T) {
+	proof, stateRoot := newTestProof()
+	slots := []utils.Hash{utils.Slot(1), utils.Slot(2)}
+	client := mocks.New().
+		On(EthGetBlockByNumber, Latest, false).ReturnJSON(`{"number": "0x429d3b", "stateRoot": "`+stateRoot.Hex()+`"}`).
+		On(EthGetProof, batchUsdc, slots, "0x429d3b").Return(proof).
+		On(EthGetProof, batchUsdc, []utils.Hash{}, Latest).Return(nil).
+		On(EthGetProof).ReturnError(-32000, "header not found")
+	c := NewFromRPCClient(client)
+
+	got, err := c.GetVerifiedAccount(batchUsdc, slots, Latest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &VerifiedAccount{
+		Address:     batchUsdc,
+		Balance:     big.NewInt(1500000000),
+		Nonce:       1,
+		CodeHash:    proof.CodeHash,
+		StorageHash: proof.StorageHash,
+		Storage:     map[utils.Hash]utils.Hash{utils.Slot(1): utils.Slot(42), utils.Slot(2): {}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetVerifiedAccount() got = %+v, want %+v", got, want)
+	}
+
+	if _, err := c.GetProof(batchUsdc, nil); err == nil || err.Error() != "no proof returned" {
+		t.Errorf("GetProof() error = %v, want no proof returned", err)
+	}
+	if _, err := c.GetProof(batchHolder, slots, "0x10"); err == nil || err.Error() != "remote Error: -32000: header not found" {
+		t.Errorf("GetProof() error = %v, want header not found", err)
+	}
+
+	b := c.NewBatch()
+	future := b.GetProof(batchUsdc, slots, "0x429d3b")
+	if err := b.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := future.Get(); err != nil || !reflect.DeepEqual(got, proof) {
+		t.Errorf("Batch.GetProof() got = %+v, %v, want %+v", got, err, proof)
+	}
+}
+
+func TestAccountProof_Verify(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(p *AccountProof, stateRoot *utils.Hash)
+	}{
+		{name: "Wrong State Root", tamper: func(p *AccountProof, stateRoot *utils.Hash) { *stateRoot = utils.Keccak256Hash(nil) }},
+		{name: "Balance", tamper: func(p *AccountProof, _ *utils.Hash) { p.Balance = "0x59682f01" }},
+		{name: "Nonce", tamper: func(p *AccountProof, _ *utils.Hash) { p.Nonce = "0x2" }},
+		{name: "Code Hash", tamper: func(p *AccountProof, _ *utils.Hash) { p.CodeHash = trie.EmptyCodeHash }},
+		{name: "Storage Hash", tamper: func(p *AccountProof, _ *utils.Hash) { p.StorageHash = trie.EmptyRoot }},
+		{name: "Storage Value", tamper: func(p *AccountProof, _ *utils.Hash) { p.StorageProof[0].Value = "0x2b" }},
+		{name: "Absent Storage Value", tamper: func(p *AccountProof, _ *utils.Hash) { p.StorageProof[1].Value = "0x1" }},
+		{name: "Missing Node", tamper: func(p *AccountProof, _ *utils.Hash) { p.StorageProof[0].Proof = nil }},
+		{name: "Not Hex", tamper: func(p *AccountProof, _ *utils.Hash) { p.AccountProof[0] = "0xzz" }},
+		{name: "Other Account", tamper: func(p *AccountProof, _ *utils.Hash) { p.Address = batchHolder }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proof, stateRoot := newTestProof()
+			tt.tamper(proof, &stateRoot)
+			if got, err := proof.Verify(stateRoot); !errors.Is(err, trie.ErrInvalidProof) {
+				t.Errorf("Verify() got = %+v, %v, want %v", got, err, trie.ErrInvalidProof)
+			}
+		})
+	}
+}
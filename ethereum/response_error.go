@@ -0,0 +1,165 @@
+package ethereum
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/massigerardi/alchemy-api/utils"
+	"github.com/ybbus/jsonrpc/v3"
+)
+
+// ResponseError is the error of an item of a batch. Unlike an arbitrary
+// error it marshals to JSON, keeping the code of remote errors, so batch
+// responses can be shipped over APIs and queues and decoded back.
+type ResponseError struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message"`
+}
+
+// Error formats the error like jsonrpc.RPCError when it has a code.
+func (e *ResponseError) Error() string {
+	if e.Code == 0 {
+		return e.Message
+	}
+	return fmt.Sprintf("%v: %v", e.Code, e.Message)
+}
+
+// newResponseError returns the details of err, nil if err is nil.
+func newResponseError(err error) *ResponseError {
+	var responseError *ResponseError
+	var rpcError *jsonrpc.RPCError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &responseError):
+		return responseError
+	case errors.As(err, &rpcError):
+		return &ResponseError{Code: rpcError.Code, Message: rpcError.Message}
+	}
+	return &ResponseError{Message: err.Error()}
+}
+
+// asError returns err as an error, keeping nil untyped.
+func (e *ResponseError) asError() error {
+	if e == nil {
+		return nil
+	}
+	return e
+}
+
+type contractCodeResponseJSON struct {
+	Address utils.Address  `json:"address"`
+	Code    string         `json:"code"`
+	Error   *ResponseError `json:"error,omitempty"`
+}
+
+func (r ContractCodeResponse) MarshalJSON() ([]byte, error) {
+	return json.Marshal(contractCodeResponseJSON{Address: r.Address, Code: r.Code, Error: newResponseError(r.Error)})
+}
+
+// UnmarshalJSON decodes a response marshalled by MarshalJSON, its error
+// being a *ResponseError.
+func (r *ContractCodeResponse) UnmarshalJSON(data []byte) error {
+	var decoded contractCodeResponseJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*r = ContractCodeResponse{Address: decoded.Address, Code: decoded.Code, Error: decoded.Error.asError()}
+	return nil
+}
+
+type balanceResponseJSON struct {
+	Address utils.Address  `json:"address"`
+	Amount  *string        `json:"amount,omitempty"`
+	Error   *ResponseError `json:"error,omitempty"`
+}
+
+// MarshalJSON encodes the amount as a decimal string, which unlike a JSON
+// number survives decoders using float64.
+func (r BalanceResponse) MarshalJSON() ([]byte, error) {
+	decoded := balanceResponseJSON{Address: r.Address, Error: newResponseError(r.Error)}
+	if r.Amount != nil {
+		amount := r.Amount.String()
+		decoded.Amount = &amount
+	}
+	return json.Marshal(decoded)
+}
+
+// UnmarshalJSON decodes a response marshalled by MarshalJSON, its error
+// being a *ResponseError.
+func (r *BalanceResponse) UnmarshalJSON(data []byte) error {
+	var decoded balanceResponseJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*r = BalanceResponse{Address: decoded.Address, Error: decoded.Error.asError()}
+	if decoded.Amount != nil {
+		amount, ok := new(big.Int).SetString(*decoded.Amount, 10)
+		if !ok {
+			return fmt.Errorf("invalid amount %q", *decoded.Amount)
+		}
+		r.Amount = amount
+	}
+	return nil
+}
+
+type ensNameResponseJSON struct {
+	Address utils.Address  `json:"address"`
+	Name    string         `json:"name"`
+	Error   *ResponseError `json:"error,omitempty"`
+}
+
+func (r ENSNameResponse) MarshalJSON() ([]byte, error) {
+	return json.Marshal(ensNameResponseJSON{Address: r.Address, Name: r.Name, Error: newResponseError(r.Error)})
+}
+
+// UnmarshalJSON decodes a response marshalled by MarshalJSON, its error
+// being a *ResponseError.
+func (r *ENSNameResponse) UnmarshalJSON(data []byte) error {
+	var decoded ensNameResponseJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*r = ENSNameResponse{Address: decoded.Address, Name: decoded.Name, Error: decoded.Error.asError()}
+	return nil
+}
+
+type accountStateJSON struct {
+	Address utils.Address             `json:"address"`
+	Block   string                    `json:"block"`
+	Balance *string                   `json:"balance,omitempty"`
+	Nonce   uint64                    `json:"nonce"`
+	Code    string                    `json:"code,omitempty"`
+	Storage map[utils.Hash]utils.Hash `json:"storage,omitempty"`
+	Error   *ResponseError            `json:"error,omitempty"`
+}
+
+// MarshalJSON encodes the balance as a decimal string like BalanceResponse.
+func (s AccountState) MarshalJSON() ([]byte, error) {
+	decoded := accountStateJSON{Address: s.Address, Block: s.Block, Nonce: s.Nonce, Code: s.Code, Storage: s.Storage, Error: newResponseError(s.Error)}
+	if s.Balance != nil {
+		balance := s.Balance.String()
+		decoded.Balance = &balance
+	}
+	return json.Marshal(decoded)
+}
+
+// UnmarshalJSON decodes a state marshalled by MarshalJSON, its error being
+// a *ResponseError.
+func (s *AccountState) UnmarshalJSON(data []byte) error {
+	var decoded accountStateJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*s = AccountState{Address: decoded.Address, Block: decoded.Block, Nonce: decoded.Nonce, Code: decoded.Code, Storage: decoded.Storage, Error: decoded.Error.asError()}
+	if decoded.Balance != nil {
+		balance, ok := new(big.Int).SetString(*decoded.Balance, 10)
+		if !ok {
+			return fmt.Errorf("invalid balance %q", *decoded.Balance)
+		}
+		s.Balance = balance
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package ethereum
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/massigerardi/alchemy-api/utils"
+	"github.com/ybbus/jsonrpc/v3"
+)
+
+func TestBalanceResponses_JSON(t *testing.T) {
+	holder := utils.MustParseAddress("0x549c660ce2b988f588769d6ad87be801695b2be3")
+	amount, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	responses := BalanceResponses{
+		&BalanceResponse{Address: holder, Amount: amount},
+		&BalanceResponse{Address: holder, Error: &ResponseError{Code: -32000, Message: "header not found"}},
+	}
+	data, err := json.Marshal(responses)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"address":"0x549c660ce2B988F588769d6AD87BE801695b2be3","amount":"123456789012345678901234567890"},` +
+		`{"address":"0x549c660ce2B988F588769d6AD87BE801695b2be3","error":{"code":-32000,"message":"header not found"}}]`
+	if string(data) != want {
+		t.Errorf("Marshal() got = %s, want %s", data, want)
+	}
+	var got BalanceResponses
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, responses) {
+		t.Errorf("Unmarshal() got = %v, want %v", got, responses)
+	}
+
+	if err := json.Unmarshal([]byte(`{"amount":"1.5"}`), &BalanceResponse{}); err == nil {
+		t.Errorf("Unmarshal() error = nil for a fractional amount")
+	}
+}
+
+func TestContractCodeResponses_JSON(t *testing.T) {
+	usdc := utils.MustParseAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+	tests := []struct {
+		name     string
+		response ContractCodeResponse
+		want     ContractCodeResponse
+	}{
+		{name: "Code", response: ContractCodeResponse{Address: usdc, Code: "0x6080"}, want: ContractCodeResponse{Address: usdc, Code: "0x6080"}},
+		{name: "Response Error", response: ContractCodeResponse{Address: usdc, Error: &ResponseError{Code: -123, Message: "wrong Response"}},
+			want: ContractCodeResponse{Address: usdc, Error: &ResponseError{Code: -123, Message: "wrong Response"}}},
+		{name: "RPC Error", response: ContractCodeResponse{Address: usdc, Error: &jsonrpc.RPCError{Code: 429, Message: "too many requests"}},
+			want: ContractCodeResponse{Address: usdc, Error: &ResponseError{Code: 429, Message: "too many requests"}}},
+		{name: "Other Error", response: ContractCodeResponse{Address: usdc, Error: errors.New("missing response 1 in batch")},
+			want: ContractCodeResponse{Address: usdc, Error: &ResponseError{Message: "missing response 1 in batch"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.response)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got ContractCodeResponse
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Unmarshal() got = %+v, want %+v from %s", got, tt.want, data)
+			}
+		})
+	}
+}
+
+func TestENSNameResponse_JSON(t *testing.T) {
+	response := ENSNameResponse{Address: utils.MustParseAddress("0x549c660ce2b988f588769d6ad87be801695b2be3"), Error: ErrENSNotFound}
+	data, err := json.Marshal(&response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got ENSNameResponse
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Address != response.Address || got.Error == nil || got.Error.Error() != ErrENSNotFound.Error() {
+		t.Errorf("Unmarshal() got = %+v from %s", got, data)
+	}
+}
@@ -0,0 +1,113 @@
+package ethereum
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/massigerardi/alchemy-api/utils"
+	"github.com/ybbus/jsonrpc/v3"
+)
+
+// GetStorageAt returns the 32 byte word at slot in the storage of address,
+// see utils.MappingSlot and utils.ArraySlot to locate state variables and
+// utils.UnpackUint and friends to decode packed values.
+func (c EthClient) GetStorageAt(address utils.Address, slot utils.Hash, blockNumberOpt ...string) (utils.Hash, error) {
+	response, err := c.client.GetStorageAtRaw(address, slot, optBlockNumber(blockNumberOpt))
+	if err != nil {
+		return utils.Hash{}, err
+	}
+	return getStorageWord(response)
+}
+
+// GetStorageAt adds an eth_getStorageAt call to the batch.
+func (b *Batch) GetStorageAt(address utils.Address, slot utils.Hash, blockNumberOpt ...string) *Future[utils.Hash] {
+	return Add(b, getStorageWord, EthGetStorageAt, address.Hex(), slot.Hex(), optBlockNumber(blockNumberOpt))
+}
+
+func getStorageWord(response *jsonrpc.RPCResponse) (utils.Hash, error) {
+	result, err := utils.GetString(response)
+	if err != nil {
+		return utils.Hash{}, err
+	}
+	digits := strings.TrimPrefix(result, "0x")
+	if len(digits)%2 == 1 {
+		digits = "0" + digits
+	}
+	data, err := hex.DecodeString(digits)
+	if err != nil || len(data) > utils.HashLength {
+		return utils.Hash{}, fmt.Errorf("invalid storage word %v", result)
+	}
+	return utils.BytesToHash(data), nil
+}
+
+// MaxStorageStringLength bounds the length of the values read by
+// GetStorageString, which is taken from storage and could be any number.
+const MaxStorageStringLength = 64 * 1024
+
+// GetStorageString returns the value of a string or bytes state variable
+// declared at slot, reading the data of long values in batches of
+// MaxBatchCalls words. Values longer than MaxStorageStringLength are rejected.
+func (c EthClient) GetStorageString(address utils.Address, slot utils.Hash, blockNumberOpt ...string) ([]byte, error) {
+	blockNumber := optBlockNumber(blockNumberOpt)
+	word, err := c.GetStorageAt(address, slot, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	value, length, short := utils.DecodeStringWord(word)
+	if short {
+		return value, nil
+	}
+	// long values are at least a word long, a shorter length is either not
+	// representable or not a string
+	if length < utils.HashLength {
+		return nil, fmt.Errorf("invalid string word %v at slot %v", word, slot)
+	}
+	if length > MaxStorageStringLength {
+		return nil, fmt.Errorf("string of %v bytes at slot %v exceeds %v", length, slot, MaxStorageStringLength)
+	}
+	data := utils.DataSlot(slot)
+	count := int((length + utils.HashLength - 1) / utils.HashLength)
+	value = make([]byte, 0, count*utils.HashLength)
+	for start := 0; start < count; start += MaxBatchCalls {
+		end := min(start+MaxBatchCalls, count)
+		b := c.NewBatch()
+		words := make([]*Future[utils.Hash], end-start)
+		for i := range words {
+			words[i] = b.GetStorageAt(address, utils.SlotAdd(data, int64(start+i)), blockNumber)
+		}
+		if err := b.Execute(); err != nil {
+			return nil, err
+		}
+		for _, future := range words {
+			word, err := future.Get()
+			if err != nil {
+				return nil, err
+			}
+			value = append(value, word[:]...)
+		}
+	}
+	return value[:length], nil
+}
+
+// GetProxyImplementation returns the implementation of an EIP-1967 proxy,
+// or of an EIP-1822 proxy if the EIP-1967 slot is empty. The zero address
+// is returned for contracts that are neither.
+func (c EthClient) GetProxyImplementation(address utils.Address, blockNumberOpt ...string) (utils.Address, error) {
+	b := c.NewBatch()
+	eip1967 := b.GetStorageAt(address, utils.EIP1967ImplementationSlot, blockNumberOpt...)
+	eip1822 := b.GetStorageAt(address, utils.EIP1822ProxiableSlot, blockNumberOpt...)
+	if err := b.Execute(); err != nil {
+		return utils.Address{}, err
+	}
+	for _, future := range []*Future[utils.Hash]{eip1967, eip1822} {
+		word, err := future.Get()
+		if err != nil {
+			return utils.Address{}, err
+		}
+		if !word.IsZero() {
+			return utils.UnpackAddress(word, 0), nil
+		}
+	}
+	return utils.Address{}, nil
+}
@@ -0,0 +1,152 @@
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/massigerardi/alchemy-api/mocks"
+	"github.com/massigerardi/alchemy-api/utils"
+	"github.com/ybbus/jsonrpc/v3"
+)
+
+func TestEthClient_GetStorageAt(t *testing.T) {
+	slot := utils.MappingSlot(utils.Slot(9), utils.AddressKey(batchHolder))
+	client := mocks.New().
+		On(EthGetStorageAt, batchUsdc, slot, Latest).Return("0x00000000000000000000000000000000000000000000000000000000000f4240").
+		On(EthGetStorageAt, batchUsdc, utils.Slot(1), "0x10").Return("0x2a").
+		On(EthGetStorageAt, batchUsdc, utils.Slot(2), Latest).Return("0xzz").
+		On(EthGetStorageAt).ReturnError(-32000, "header not found")
+	c := NewFromRPCClient(client)
+
+	tests := []struct {
+		name        string
+		slot        utils.Hash
+		blockNumber []string
+		want        int64
+		wantErr     string
+	}{
+		{name: "Mapping", slot: slot, want: 1000000},
+		{name: "Short Word", slot: utils.Slot(1), blockNumber: []string{"0x10"}, want: 42},
+		{name: "Invalid Word", slot: utils.Slot(2), wantErr: "invalid storage word 0xzz"},
+		{name: "Remote Error", slot: utils.Slot(3), wantErr: "-32000: header not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.GetStorageAt(batchUsdc, tt.slot, tt.blockNumber...)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("GetStorageAt() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || utils.UnpackUint(got, 0, 32).Int64() != tt.want {
+				t.Errorf("GetStorageAt() got = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestEthClient_GetStorageString(t *testing.T) {
+	long := "Circle USD Coin bridged from a sidechain"
+	data := utils.DataSlot(utils.Slot(4))
+	var first, second utils.Hash
+	copy(first[:], long[:32])
+	copy(second[:], long[32:])
+	client := mocks.New().
+		On(EthGetStorageAt, batchUsdc, utils.Slot(3), Latest).Return("0x55534420436f696e000000000000000000000000000000000000000000000010").
+		On(EthGetStorageAt, batchUsdc, utils.Slot(4), Latest).Return("0x51").
+		On(EthGetStorageAt, batchUsdc, data, Latest).Return(first.Hex()).
+		On(EthGetStorageAt, batchUsdc, utils.SlotAdd(data, 1), Latest).Return(second.Hex())
+	c := NewFromRPCClient(client)
+
+	if got, err := c.GetStorageString(batchUsdc, utils.Slot(3)); err != nil || string(got) != "USD Coin" {
+		t.Errorf("GetStorageString() got = %q, %v, want USD Coin", got, err)
+	}
+	got, err := c.GetStorageString(batchUsdc, utils.Slot(4))
+	if err != nil || string(got) != long {
+		t.Errorf("GetStorageString() got = %q, %v, want %v", got, err, long)
+	}
+	if calls := client.Calls(); len(calls) != 4 || !calls[2].Batch || !calls[3].Batch {
+		t.Errorf("GetStorageString() sent %+v, want the data in one batch", calls)
+	}
+}
+
+// batchCounter counts the batches sent to its client.
+type batchCounter struct {
+	jsonrpc.RPCClient
+	batches int
+}
+
+func (c *batchCounter) CallBatch(ctx context.Context, requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error) {
+	c.batches++
+	return c.RPCClient.CallBatch(ctx, requests)
+}
+
+func TestEthClient_GetStorageString_Length(t *testing.T) {
+	var word utils.Hash
+	copy(word[:], "abcdefghijklmnopqrstuvwxyz012345")
+	tooLong := new(big.Int).SetUint64(2*(MaxStorageStringLength+1) + 1)
+	client := &batchCounter{RPCClient: mocks.New().
+		On(EthGetStorageAt, batchUsdc, utils.Slot(1), Latest).Return("0x"+strings.Repeat("f", 64)).
+		On(EthGetStorageAt, batchUsdc, utils.Slot(2), Latest).Return(lengthWord(tooLong)).
+		On(EthGetStorageAt, batchUsdc, utils.Slot(3), Latest).Return(lengthWord(big.NewInt(2*250*utils.HashLength + 1))).
+		On(EthGetStorageAt).Return(word.Hex())}
+	c := NewFromRPCClient(client)
+
+	if got, err := c.GetStorageString(batchUsdc, utils.Slot(1)); err == nil || !strings.Contains(err.Error(), "invalid string word") {
+		t.Errorf("GetStorageString() got = %q, %v, want an invalid string word", got, err)
+	}
+	if got, err := c.GetStorageString(batchUsdc, utils.Slot(2)); err == nil || !strings.Contains(err.Error(), "exceeds") {
+		t.Errorf("GetStorageString() got = %q, %v, want a too long string", got, err)
+	}
+	if client.batches != 0 {
+		t.Errorf("GetStorageString() sent %v batches for invalid lengths, want 0", client.batches)
+	}
+	got, err := c.GetStorageString(batchUsdc, utils.Slot(3))
+	if err != nil || string(got) != strings.Repeat(string(word[:]), 250) {
+		t.Errorf("GetStorageString() got = %q, %v, want 250 words", got, err)
+	}
+	if client.batches != 3 {
+		t.Errorf("GetStorageString() sent %v batches, want 3", client.batches)
+	}
+}
+
+func lengthWord(n *big.Int) string {
+	return fmt.Sprintf("0x%064x", n)
+}
+
+func TestEthClient_GetProxyImplementation(t *testing.T) {
+	word := "0x000000000000000000000000" + strings.ToLower(traceContract.Hex()[2:])
+	zero := utils.Hash{}.Hex()
+	client := mocks.New().
+		On(EthGetStorageAt, batchUsdc, utils.EIP1967ImplementationSlot, Latest).Return(word).
+		On(EthGetStorageAt, batchHolder, utils.EIP1967ImplementationSlot, Latest).Return(zero).
+		On(EthGetStorageAt, batchHolder, utils.EIP1822ProxiableSlot, Latest).Return(word).
+		On(EthGetStorageAt, mocks.Any, mocks.Any, Latest).Return(zero).
+		On(EthGetStorageAt).ReturnError(-32000, "header not found")
+	c := NewFromRPCClient(client)
+
+	tests := []struct {
+		name    string
+		address utils.Address
+		block   []string
+		want    utils.Address
+		wantErr bool
+	}{
+		{name: "EIP-1967", address: batchUsdc, want: traceContract},
+		{name: "EIP-1822", address: batchHolder, want: traceContract},
+		{name: "Not A Proxy", address: traceContract},
+		{name: "Remote Error", address: batchUsdc, block: []string{"0x10"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.GetProxyImplementation(tt.address, tt.block...)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("GetProxyImplementation() got = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,11 @@
 package ethereum
 
-import "math/big"
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/massigerardi/alchemy-api/utils"
+)
 
 type BlockIdentifier string
 
@@ -11,34 +16,83 @@ const (
 	Earliest        = "earliest"
 )
 
+// LogRequest is the filter of eth_getLogs. Either FromBlock and ToBlock or,
+// as defined by EIP-234, BlockHash can be set, but not both.
 type LogRequest struct {
-	Address   []string `json:"address"`
-	FromBlock string   `json:"fromBlock"`
-	ToBlock   string   `json:"toBlock"`
-	Topics    []string `json:"topics"`
+	Address   []utils.Address `json:"address"`
+	FromBlock string          `json:"fromBlock,omitempty"`
+	ToBlock   string          `json:"toBlock,omitempty"`
+	BlockHash *utils.Hash     `json:"blockHash,omitempty"`
+	Topics    TopicFilter     `json:"topics"`
 }
 
-func NewLogRequest(address []string, fromBlock string, toBlock string, topics ...string) LogRequest {
+func NewLogRequest(address []utils.Address, fromBlock string, toBlock string, topics ...utils.Hash) LogRequest {
 	return LogRequest{
 		Address:   address,
 		FromBlock: fromBlock,
 		ToBlock:   toBlock,
+		Topics:    ExactTopics(topics...),
+	}
+}
+
+// NewBlockHashLogRequest returns an EIP-234 request for the logs of a single block.
+func NewBlockHashLogRequest(address []utils.Address, blockHash utils.Hash, topics TopicFilter) LogRequest {
+	return LogRequest{
+		Address:   address,
+		BlockHash: &blockHash,
 		Topics:    topics,
 	}
 }
 
+// WithTopics returns a copy of the request using the given topic filter.
+func (r LogRequest) WithTopics(topics TopicFilter) LogRequest {
+	r.Topics = topics
+	return r
+}
+
+// Validate checks that the request does not mix a block hash with a block range.
+func (r LogRequest) Validate() error {
+	if r.BlockHash != nil && (r.FromBlock != "" || r.ToBlock != "") {
+		return fmt.Errorf("blockHash cannot be combined with fromBlock or toBlock")
+	}
+	return nil
+}
+
+type CallRequest struct {
+	From     *utils.Address `json:"from,omitempty"`
+	To       utils.Address  `json:"to"`
+	Gas      string         `json:"gas,omitempty"`
+	GasPrice string         `json:"gasPrice,omitempty"`
+	Value    string         `json:"value,omitempty"`
+	Data     string         `json:"data,omitempty"`
+}
+
+func NewCallRequest(to utils.Address, data string) CallRequest {
+	return CallRequest{
+		To:   to,
+		Data: data,
+	}
+}
+
 type ContractCodeResponses []*ContractCodeResponse
 type ContractCodeResponse struct {
-	Address string `json:"address"`
-	Code    string `json:"code"`
-	Error   error  `json:"error"`
+	Address utils.Address `json:"address"`
+	Code    string        `json:"code"`
+	Error   error         `json:"error"`
 }
 
 type BalanceResponses []*BalanceResponse
 type BalanceResponse struct {
-	Address string  `json:"address"`
-	Amount  big.Int `json:"amount"`
-	Error   error   `json:"error"`
+	Address utils.Address `json:"address"`
+	Amount  *big.Int      `json:"amount"`
+	Error   error         `json:"error"`
+}
+
+type ENSNameResponses []*ENSNameResponse
+type ENSNameResponse struct {
+	Address utils.Address `json:"address"`
+	Name    string        `json:"name"`
+	Error   error         `json:"error"`
 }
 
 type LogsResponses []*LogsResponse
@@ -54,3 +108,46 @@ type LogsResponse struct {
 	TransactionHash  string   `json:"transactionHash"`
 	TransactionIndex string   `json:"transactionIndex"`
 }
+
+// Block is a block as returned by eth_getBlockByNumber and eth_getBlockByHash
+// without full transaction objects.
+type Block struct {
+	Number           string   `json:"number"`
+	Hash             string   `json:"hash"`
+	ParentHash       string   `json:"parentHash"`
+	Nonce            string   `json:"nonce"`
+	Sha3Uncles       string   `json:"sha3Uncles"`
+	LogsBloom        string   `json:"logsBloom"`
+	TransactionsRoot string   `json:"transactionsRoot"`
+	StateRoot        string   `json:"stateRoot"`
+	ReceiptsRoot     string   `json:"receiptsRoot"`
+	Miner            string   `json:"miner"`
+	Difficulty       string   `json:"difficulty"`
+	TotalDifficulty  string   `json:"totalDifficulty"`
+	ExtraData        string   `json:"extraData"`
+	Size             string   `json:"size"`
+	GasLimit         string   `json:"gasLimit"`
+	GasUsed          string   `json:"gasUsed"`
+	Timestamp        string   `json:"timestamp"`
+	BaseFeePerGas    string   `json:"baseFeePerGas,omitempty"`
+	Transactions     []string `json:"transactions"`
+	Uncles           []string `json:"uncles"`
+}
+
+// Receipt is a transaction receipt as returned by eth_getTransactionReceipt.
+type Receipt struct {
+	TransactionHash   string        `json:"transactionHash"`
+	TransactionIndex  string        `json:"transactionIndex"`
+	BlockHash         string        `json:"blockHash"`
+	BlockNumber       string        `json:"blockNumber"`
+	From              string        `json:"from"`
+	To                string        `json:"to"`
+	CumulativeGasUsed string        `json:"cumulativeGasUsed"`
+	GasUsed           string        `json:"gasUsed"`
+	EffectiveGasPrice string        `json:"effectiveGasPrice"`
+	ContractAddress   string        `json:"contractAddress"`
+	Logs              LogsResponses `json:"logs"`
+	LogsBloom         string        `json:"logsBloom"`
+	Type              string        `json:"type"`
+	Status            string        `json:"status"`
+}
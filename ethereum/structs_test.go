@@ -3,21 +3,23 @@ package ethereum
 import (
 	"reflect"
 	"testing"
+
+	"github.com/massigerardi/alchemy-api/utils"
 )
 
 func TestNewLogRequest(t *testing.T) {
 	type args struct {
-		address   []string
+		address   []utils.Address
 		fromBlock string
 		toBlock   string
-		topics    []string
+		topics    []utils.Hash
 	}
-	address := make([]string, 1)
-	address[0] = "0xb59f67a8bff5d8cd03f6ac17265c550ed8f33907"
-	topics := make([]string, 3)
-	topics[0] = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
-	topics[1] = "0x00000000000000000000000000b46c2526e227482e2ebb8f4c69e4674d262e75"
-	topics[2] = "0x00000000000000000000000054a2d42a40f51259dedd1978f6c118a0f0eff078"
+	address := make([]utils.Address, 1)
+	address[0] = utils.MustParseAddress("0xb59f67a8bff5d8cd03f6ac17265c550ed8f33907")
+	topics := make([]utils.Hash, 3)
+	topics[0] = utils.MustParseHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")
+	topics[1] = utils.MustParseHash("0x00000000000000000000000000b46c2526e227482e2ebb8f4c69e4674d262e75")
+	topics[2] = utils.MustParseHash("0x00000000000000000000000054a2d42a40f51259dedd1978f6c118a0f0eff078")
 
 	params := args{
 		address:   address,
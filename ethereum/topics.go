@@ -0,0 +1,96 @@
+package ethereum
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/massigerardi/alchemy-api/utils"
+)
+
+// TopicFilter is the topics parameter of eth_getLogs. Every position holds
+// the alternative topics accepted there: an empty position is a wildcard,
+// a single topic must match exactly and several topics are OR-ed.
+//
+// For example [[A, B], nil, [C]] matches logs whose first topic is A or B,
+// with any second topic and C as third topic.
+type TopicFilter [][]utils.Hash
+
+// Topics starts an empty TopicFilter, to be extended with Exact, OneOf and Any.
+func Topics() TopicFilter {
+	return TopicFilter{}
+}
+
+// ExactTopics returns a filter matching every topic position exactly.
+func ExactTopics(topics ...utils.Hash) TopicFilter {
+	filter := Topics()
+	for _, topic := range topics {
+		filter = filter.Exact(topic)
+	}
+	return filter
+}
+
+// Exact appends a position that must match topic.
+func (f TopicFilter) Exact(topic utils.Hash) TopicFilter {
+	return f.OneOf(topic)
+}
+
+// OneOf appends a position matching any of the given topics. Without topics the position is a wildcard.
+func (f TopicFilter) OneOf(topics ...utils.Hash) TopicFilter {
+	position := append([]utils.Hash(nil), topics...)
+	return append(f[:len(f):len(f)], position)
+}
+
+// Any appends a wildcard position.
+func (f TopicFilter) Any() TopicFilter {
+	return f.OneOf()
+}
+
+// MarshalJSON encodes wildcards as null, single topics as strings and
+// alternatives as arrays. Trailing wildcards are dropped.
+func (f TopicFilter) MarshalJSON() ([]byte, error) {
+	last := len(f)
+	for last > 0 && len(f[last-1]) == 0 {
+		last--
+	}
+	positions := make([]interface{}, last)
+	for i, position := range f[:last] {
+		switch len(position) {
+		case 0:
+			positions[i] = nil
+		case 1:
+			positions[i] = position[0]
+		default:
+			positions[i] = position
+		}
+	}
+	return json.Marshal(positions)
+}
+
+// UnmarshalJSON decodes the eth_getLogs topics encoding produced by MarshalJSON.
+func (f *TopicFilter) UnmarshalJSON(data []byte) error {
+	var positions []json.RawMessage
+	if err := json.Unmarshal(data, &positions); err != nil {
+		return err
+	}
+	filter := make(TopicFilter, len(positions))
+	for i, position := range positions {
+		position = bytes.TrimSpace(position)
+		switch {
+		case bytes.Equal(position, []byte("null")):
+			filter[i] = nil
+		case len(position) > 0 && position[0] == '[':
+			if err := json.Unmarshal(position, &filter[i]); err != nil {
+				return err
+			}
+		default:
+			var topic utils.Hash
+			if err := json.Unmarshal(position, &topic); err != nil {
+				return fmt.Errorf("invalid topic at position %v: %w", i, err)
+			}
+			filter[i] = []utils.Hash{topic}
+		}
+	}
+	*f = filter
+	return nil
+}
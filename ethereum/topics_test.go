@@ -0,0 +1,90 @@
+package ethereum
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/massigerardi/alchemy-api/mocks"
+	"github.com/massigerardi/alchemy-api/utils"
+)
+
+var (
+	transferTopic = utils.EventTopic("Transfer(address,address,uint256)")
+	approvalTopic = utils.EventTopic("Approval(address,address,uint256)")
+	receiverTopic = utils.MustParseHash("0x00000000000000000000000054a2d42a40f51259dedd1978f6c118a0f0eff078")
+)
+
+func TestTopicFilter_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter TopicFilter
+		want   string
+	}{
+		{name: "Empty", filter: Topics(), want: `[]`},
+		{name: "Exact", filter: ExactTopics(transferTopic), want: `["` + transferTopic.Hex() + `"]`},
+		{
+			name:   "Or Wildcard Exact",
+			filter: Topics().OneOf(transferTopic, approvalTopic).Any().Exact(receiverTopic),
+			want:   `[["` + transferTopic.Hex() + `","` + approvalTopic.Hex() + `"],null,"` + receiverTopic.Hex() + `"]`,
+		},
+		{name: "Trailing Wildcards", filter: Topics().Exact(transferTopic).Any().Any(), want: `["` + transferTopic.Hex() + `"]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.filter)
+			if err != nil {
+				t.Fatalf("MarshalJSON() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("MarshalJSON() got = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTopicFilter_UnmarshalJSON(t *testing.T) {
+	want := Topics().OneOf(transferTopic, approvalTopic).Any().Exact(receiverTopic)
+	js, err := json.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got TopicFilter
+	if err := json.Unmarshal(js, &got); err != nil {
+		t.Fatalf("UnmarshalJSON() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("UnmarshalJSON() got = %v, want %v", got, want)
+	}
+	if err := json.Unmarshal([]byte(`["0x1234"]`), &got); err == nil {
+		t.Errorf("UnmarshalJSON() accepted an invalid topic")
+	}
+}
+
+func TestTopicFilter_DoesNotAlias(t *testing.T) {
+	base := make(TopicFilter, 0, 4).Exact(transferTopic)
+	first := base.Exact(approvalTopic)
+	second := base.Exact(receiverTopic)
+	if first[1][0] != approvalTopic || second[1][0] != receiverTopic {
+		t.Errorf("builders share storage: %v %v", first, second)
+	}
+}
+
+func TestLogRequest_BlockHash(t *testing.T) {
+	blockHash := utils.MustParseHash("0x8243343df08b9751f5ca0c5f8c9c0460d8a9b6351066fae0acbd4d3e776de8bb")
+	request := NewBlockHashLogRequest(nil, blockHash, ExactTopics(transferTopic))
+	js, err := json.Marshal(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"address":null,"blockHash":"` + blockHash.Hex() + `","topics":["` + transferTopic.Hex() + `"]}`
+	if string(js) != want {
+		t.Errorf("Marshal() got = %s, want %s", js, want)
+	}
+
+	c := EthClient{client: &ETHClientRaw{mocks.GetMockClient()}}
+	request.FromBlock = "0x429d3b"
+	if _, err := c.GetLogs(request); err == nil {
+		t.Errorf("GetLogs() accepted blockHash together with fromBlock")
+	}
+}
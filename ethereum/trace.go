@@ -0,0 +1,302 @@
+package ethereum
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/massigerardi/alchemy-api/utils"
+)
+
+// TraceType selects the outputs of trace_call and trace_replayTransaction.
+type TraceType string
+
+const (
+	TraceTypeTrace     TraceType = "trace"
+	TraceTypeStateDiff TraceType = "stateDiff"
+	TraceTypeVMTrace   TraceType = "vmTrace"
+)
+
+// TraceKind is the type of a trace, telling the types of its Action and Result.
+type TraceKind string
+
+const (
+	// TraceKindCall traces have a *CallAction and a *CallResult.
+	TraceKindCall TraceKind = "call"
+	// TraceKindCreate traces have a *CreateAction and a *CreateResult.
+	TraceKindCreate TraceKind = "create"
+	// TraceKindSuicide traces have a *SuicideAction and no result.
+	TraceKindSuicide TraceKind = "suicide"
+	// TraceKindReward traces have a *RewardAction and no result.
+	TraceKindReward TraceKind = "reward"
+)
+
+// TraceAction is one of *CallAction, *CreateAction, *SuicideAction and *RewardAction.
+type TraceAction interface {
+	traceAction()
+}
+
+// TraceResult is one of *CallResult and *CreateResult.
+type TraceResult interface {
+	traceResult()
+}
+
+// CallAction is a message call, CallType being call, delegatecall, staticcall or callcode.
+type CallAction struct {
+	CallType string        `json:"callType"`
+	From     utils.Address `json:"from"`
+	To       utils.Address `json:"to"`
+	Gas      string        `json:"gas"`
+	Input    string        `json:"input"`
+	Value    string        `json:"value"`
+}
+
+type CallResult struct {
+	GasUsed string `json:"gasUsed"`
+	Output  string `json:"output"`
+}
+
+// CreateAction is a contract creation, Init being the creation code.
+type CreateAction struct {
+	From  utils.Address `json:"from"`
+	Gas   string        `json:"gas"`
+	Init  string        `json:"init"`
+	Value string        `json:"value"`
+}
+
+type CreateResult struct {
+	Address utils.Address `json:"address"`
+	Code    string        `json:"code"`
+	GasUsed string        `json:"gasUsed"`
+}
+
+// SuicideAction is a self destruct sending Balance to RefundAddress.
+type SuicideAction struct {
+	Address       utils.Address `json:"address"`
+	RefundAddress utils.Address `json:"refundAddress"`
+	Balance       string        `json:"balance"`
+}
+
+// RewardAction is a block or uncle reward, RewardType being block or uncle.
+type RewardAction struct {
+	Author     utils.Address `json:"author"`
+	RewardType string        `json:"rewardType"`
+	Value      string        `json:"value"`
+}
+
+func (*CallAction) traceAction()    {}
+func (*CreateAction) traceAction()  {}
+func (*SuicideAction) traceAction() {}
+func (*RewardAction) traceAction()  {}
+func (*CallResult) traceResult()    {}
+func (*CreateResult) traceResult()  {}
+
+type Traces []*Trace
+
+// Trace is a node of the call tree of a transaction, in the format of the
+// trace namespace. TraceAddress is the path of the node from the root call.
+// Failed calls have an Error, e.g. "Reverted", and no Result. The block and
+// transaction fields are not set in the traces of TraceResults.
+type Trace struct {
+	Type                TraceKind   `json:"type"`
+	Action              TraceAction `json:"action"`
+	Result              TraceResult `json:"result"`
+	Error               string      `json:"error,omitempty"`
+	Subtraces           int         `json:"subtraces"`
+	TraceAddress        []int       `json:"traceAddress"`
+	BlockHash           string      `json:"blockHash,omitempty"`
+	BlockNumber         uint64      `json:"blockNumber,omitempty"`
+	TransactionHash     string      `json:"transactionHash,omitempty"`
+	TransactionPosition *int        `json:"transactionPosition,omitempty"`
+}
+
+// UnmarshalJSON decodes the action and result into the types of the trace kind.
+func (t *Trace) UnmarshalJSON(data []byte) error {
+	type plain Trace
+	var decoded struct {
+		plain
+		Action json.RawMessage `json:"action"`
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*t = Trace(decoded.plain)
+	var result TraceResult
+	switch t.Type {
+	case TraceKindCall:
+		t.Action, result = &CallAction{}, &CallResult{}
+	case TraceKindCreate:
+		t.Action, result = &CreateAction{}, &CreateResult{}
+	case TraceKindSuicide:
+		t.Action = &SuicideAction{}
+	case TraceKindReward:
+		t.Action = &RewardAction{}
+	default:
+		return fmt.Errorf("unknown trace type %q", t.Type)
+	}
+	if err := json.Unmarshal(decoded.Action, t.Action); err != nil {
+		return fmt.Errorf("decoding %v action: %w", t.Type, err)
+	}
+	if result != nil && isJSONValue(decoded.Result) {
+		if err := json.Unmarshal(decoded.Result, result); err != nil {
+			return fmt.Errorf("decoding %v result: %w", t.Type, err)
+		}
+		t.Result = result
+	}
+	return nil
+}
+
+func isJSONValue(data json.RawMessage) bool {
+	return len(data) > 0 && string(data) != "null"
+}
+
+// TraceFilterRequest selects the traces returned by TraceFilter. Addresses
+// match the from and to of actions, After and Count paginate the result.
+type TraceFilterRequest struct {
+	FromBlock   string          `json:"fromBlock,omitempty"`
+	ToBlock     string          `json:"toBlock,omitempty"`
+	FromAddress []utils.Address `json:"fromAddress,omitempty"`
+	ToAddress   []utils.Address `json:"toAddress,omitempty"`
+	After       uint64          `json:"after,omitempty"`
+	Count       uint64          `json:"count,omitempty"`
+}
+
+// TraceResults is the result of trace_call and trace_replayTransaction, with
+// the outputs of the requested trace types set.
+type TraceResults struct {
+	Output    string          `json:"output"`
+	Trace     Traces          `json:"trace"`
+	StateDiff StateDiff       `json:"stateDiff"`
+	VMTrace   json.RawMessage `json:"vmTrace"`
+}
+
+// StateDiff holds the changes of a transaction to the accounts it touched.
+type StateDiff map[utils.Address]*AccountDiff
+
+type AccountDiff struct {
+	Balance Diff                `json:"balance"`
+	Code    Diff                `json:"code"`
+	Nonce   Diff                `json:"nonce"`
+	Storage map[utils.Hash]Diff `json:"storage"`
+}
+
+// DiffKind tells how a value of a StateDiff changed.
+type DiffKind string
+
+const (
+	DiffUnchanged DiffKind = "="
+	DiffAdded     DiffKind = "+"
+	DiffRemoved   DiffKind = "-"
+	DiffChanged   DiffKind = "*"
+)
+
+// Diff is the change of a value: From is set unless it was added, To unless
+// it was removed, and neither if it is unchanged.
+type Diff struct {
+	Kind DiffKind
+	From string
+	To   string
+}
+
+// UnmarshalJSON decodes "=", {"+": to}, {"-": from} and {"*": {"from": from, "to": to}}.
+func (d *Diff) UnmarshalJSON(data []byte) error {
+	var unchanged string
+	if err := json.Unmarshal(data, &unchanged); err == nil {
+		if DiffKind(unchanged) != DiffUnchanged {
+			return fmt.Errorf("invalid diff %s", data)
+		}
+		*d = Diff{Kind: DiffUnchanged}
+		return nil
+	}
+	var changes struct {
+		Added   *string `json:"+"`
+		Removed *string `json:"-,"`
+		Changed *struct {
+			From string `json:"from"`
+			To   string `json:"to"`
+		} `json:"*"`
+	}
+	if err := json.Unmarshal(data, &changes); err != nil {
+		return fmt.Errorf("invalid diff %s", data)
+	}
+	switch {
+	case changes.Added != nil:
+		*d = Diff{Kind: DiffAdded, To: *changes.Added}
+	case changes.Removed != nil:
+		*d = Diff{Kind: DiffRemoved, From: *changes.Removed}
+	case changes.Changed != nil:
+		*d = Diff{Kind: DiffChanged, From: changes.Changed.From, To: changes.Changed.To}
+	default:
+		return fmt.Errorf("invalid diff %s", data)
+	}
+	return nil
+}
+
+// MarshalJSON encodes the diff in the format decoded by UnmarshalJSON.
+func (d Diff) MarshalJSON() ([]byte, error) {
+	switch d.Kind {
+	case DiffAdded:
+		return json.Marshal(map[string]string{"+": d.To})
+	case DiffRemoved:
+		return json.Marshal(map[string]string{"-": d.From})
+	case DiffChanged:
+		return json.Marshal(map[string]map[string]string{"*": {"from": d.From, "to": d.To}})
+	}
+	return json.Marshal(DiffUnchanged)
+}
+
+// TraceTransaction returns the call tree of a transaction, root call first.
+func (c EthClient) TraceTransaction(hash utils.Hash) (Traces, error) {
+	response, err := c.client.TraceTransactionRaw(hash)
+	if err != nil {
+		return nil, err
+	}
+	return decodeObject[Traces](response)
+}
+
+// TraceBlock returns the traces of every transaction of a block followed by
+// its reward traces.
+func (c EthClient) TraceBlock(blockNumber string) (Traces, error) {
+	response, err := c.client.TraceBlockRaw(blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	return decodeObject[Traces](response)
+}
+
+// TraceFilter returns the traces matching request, e.g. every call to an
+// address over a block range.
+func (c EthClient) TraceFilter(request TraceFilterRequest) (Traces, error) {
+	response, err := c.client.TraceFilterRaw(request)
+	if err != nil {
+		return nil, err
+	}
+	return decodeObject[Traces](response)
+}
+
+// TraceCall executes request on top of a block without creating a
+// transaction and returns the outputs of traceTypes, TraceTypeTrace if none.
+func (c EthClient) TraceCall(request CallRequest, traceTypes []TraceType, blockNumberOpt ...string) (*TraceResults, error) {
+	response, err := c.client.TraceCallRaw(request, defaultTraceTypes(traceTypes), optBlockNumber(blockNumberOpt))
+	if err != nil {
+		return nil, err
+	}
+	return decodeObject[*TraceResults](response)
+}
+
+// TraceReplayTransaction replays a mined transaction and returns the outputs
+// of traceTypes, TraceTypeTrace if none.
+func (c EthClient) TraceReplayTransaction(hash utils.Hash, traceTypes ...TraceType) (*TraceResults, error) {
+	response, err := c.client.TraceReplayTransactionRaw(hash, defaultTraceTypes(traceTypes))
+	if err != nil {
+		return nil, err
+	}
+	return decodeObject[*TraceResults](response)
+}
+
+func defaultTraceTypes(traceTypes []TraceType) []TraceType {
+	if len(traceTypes) == 0 {
+		return []TraceType{TraceTypeTrace}
+	}
+	return traceTypes
+}
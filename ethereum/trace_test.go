@@ -0,0 +1,225 @@
+package ethereum
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/massigerardi/alchemy-api/mocks"
+	"github.com/massigerardi/alchemy-api/utils"
+)
+
+const tracesJS = `[
+  {
+    "action": {"callType": "call", "from": "0x549c660ce2b988f588769d6ad87be801695b2be3", "gas": "0x1d4c0", "input": "0xa9059cbb", "to": "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", "value": "0xde0b6b3a7640000"},
+    "blockHash": "0x8243343df08b9751f5ca0c5f8c9c0460d8a9b6351066fae0acbd4d3e776de8bb",
+    "blockNumber": 4365627,
+    "result": {"gasUsed": "0x5208", "output": "0x"},
+    "subtraces": 2,
+    "traceAddress": [],
+    "transactionHash": "0x46e9e6ecb2c6bbf8d1a7e7d2d1d6e0a0b2f5b1e6f2c0e7b0c2d4f6a8b0c2e4f6",
+    "transactionPosition": 3,
+    "type": "call"
+  },
+  {
+    "action": {"from": "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", "gas": "0x10000", "init": "0x6080", "value": "0x0"},
+    "result": {"address": "0x558fa75074cc7cf045c764aed47d37776ea697d2", "code": "0x60806040", "gasUsed": "0x1000"},
+    "subtraces": 0,
+    "traceAddress": [0],
+    "type": "create"
+  },
+  {
+    "action": {"callType": "delegatecall", "from": "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", "gas": "0x100", "input": "0x", "to": "0x558fa75074cc7cf045c764aed47d37776ea697d2", "value": "0x0"},
+    "error": "Reverted",
+    "result": null,
+    "subtraces": 0,
+    "traceAddress": [1],
+    "type": "call"
+  },
+  {
+    "action": {"address": "0x558fa75074cc7cf045c764aed47d37776ea697d2", "refundAddress": "0x549c660ce2b988f588769d6ad87be801695b2be3", "balance": "0x1"},
+    "subtraces": 0,
+    "traceAddress": [2],
+    "type": "suicide"
+  },
+  {
+    "action": {"author": "0x549c660ce2b988f588769d6ad87be801695b2be3", "rewardType": "block", "value": "0x1bc16d674ec80000"},
+    "blockNumber": 4365627,
+    "result": null,
+    "subtraces": 0,
+    "traceAddress": [],
+    "type": "reward"
+  }
+]`
+
+const traceResultsJS = `{
+  "output": "0x0000000000000000000000000000000000000000000000000000000000000001",
+  "stateDiff": {
+    "0x549c660ce2b988f588769d6ad87be801695b2be3": {
+      "balance": {"*": {"from": "0x2", "to": "0x1"}},
+      "code": "=",
+      "nonce": {"*": {"from": "0x0", "to": "0x1"}},
+      "storage": {}
+    },
+    "0x558fa75074cc7cf045c764aed47d37776ea697d2": {
+      "balance": {"+": "0x0"},
+      "code": {"+": "0x60806040"},
+      "nonce": {"+": "0x1"},
+      "storage": {
+        "0x0000000000000000000000000000000000000000000000000000000000000000": {"-": "0x1"}
+      }
+    }
+  },
+  "trace": [
+    {
+      "action": {"callType": "call", "from": "0x549c660ce2b988f588769d6ad87be801695b2be3", "gas": "0x1d4c0", "input": "0x", "to": "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", "value": "0x1"},
+      "result": {"gasUsed": "0x5208", "output": "0x"},
+      "subtraces": 0,
+      "traceAddress": [],
+      "type": "call"
+    }
+  ],
+  "vmTrace": null
+}`
+
+var (
+	traceHolder   = utils.MustParseAddress("0x549c660ce2b988f588769d6ad87be801695b2be3")
+	traceUsdc     = utils.MustParseAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+	traceContract = utils.MustParseAddress("0x558FA75074cc7cF045C764aEd47D37776Ea697d2")
+	traceTx       = utils.MustParseHash("0x46e9e6ecb2c6bbf8d1a7e7d2d1d6e0a0b2f5b1e6f2c0e7b0c2d4f6a8b0c2e4f6")
+)
+
+func TestEthClient_TraceTransaction(t *testing.T) {
+	c := NewFromRPCClient(mocks.New().On(TraceTransaction, traceTx).ReturnJSON(tracesJS))
+	traces, err := c.TraceTransaction(traceTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	position := 3
+	want := Traces{
+		{
+			Type:                TraceKindCall,
+			Action:              &CallAction{CallType: "call", From: traceHolder, To: traceUsdc, Gas: "0x1d4c0", Input: "0xa9059cbb", Value: "0xde0b6b3a7640000"},
+			Result:              &CallResult{GasUsed: "0x5208", Output: "0x"},
+			Subtraces:           2,
+			TraceAddress:        []int{},
+			BlockHash:           "0x8243343df08b9751f5ca0c5f8c9c0460d8a9b6351066fae0acbd4d3e776de8bb",
+			BlockNumber:         4365627,
+			TransactionHash:     traceTx.Hex(),
+			TransactionPosition: &position,
+		},
+		{
+			Type:         TraceKindCreate,
+			Action:       &CreateAction{From: traceUsdc, Gas: "0x10000", Init: "0x6080", Value: "0x0"},
+			Result:       &CreateResult{Address: traceContract, Code: "0x60806040", GasUsed: "0x1000"},
+			TraceAddress: []int{0},
+		},
+		{
+			Type:         TraceKindCall,
+			Action:       &CallAction{CallType: "delegatecall", From: traceUsdc, To: traceContract, Gas: "0x100", Input: "0x", Value: "0x0"},
+			Error:        "Reverted",
+			TraceAddress: []int{1},
+		},
+		{
+			Type:         TraceKindSuicide,
+			Action:       &SuicideAction{Address: traceContract, RefundAddress: traceHolder, Balance: "0x1"},
+			TraceAddress: []int{2},
+		},
+		{
+			Type:         TraceKindReward,
+			Action:       &RewardAction{Author: traceHolder, RewardType: "block", Value: "0x1bc16d674ec80000"},
+			BlockNumber:  4365627,
+			TraceAddress: []int{},
+		},
+	}
+	if !reflect.DeepEqual(traces, want) {
+		for i := range traces {
+			t.Errorf("TraceTransaction() got[%v] = %+v, want %+v", i, traces[i], want[i])
+		}
+	}
+
+	data, err := json.Marshal(traces)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Traces
+	if err := json.Unmarshal(data, &decoded); err != nil || !reflect.DeepEqual(decoded, traces) {
+		t.Errorf("Unmarshal() got = %v, %v after Marshal", decoded, err)
+	}
+}
+
+func TestEthClient_Traces(t *testing.T) {
+	client := mocks.New().
+		On(TraceBlock, "0x429d3b").ReturnJSON(tracesJS).
+		On(TraceFilter, map[string]interface{}{"fromBlock": "0x1", "toBlock": "0x2", "toAddress": []string{traceUsdc.Hex()}, "count": 10}).ReturnJSON(tracesJS).
+		On(TraceBlock).ReturnError(-32000, "header not found").
+		On(TraceTransaction).ReturnJSON(`[{"type": "selfdestruct", "action": {}}]`)
+	c := NewFromRPCClient(client)
+
+	if traces, err := c.TraceBlock("0x429d3b"); err != nil || len(traces) != 5 {
+		t.Errorf("TraceBlock() got = %v, %v", traces, err)
+	}
+	if _, err := c.TraceBlock(Latest); err == nil || err.Error() != "remote Error: -32000: header not found" {
+		t.Errorf("TraceBlock() error = %v, want header not found", err)
+	}
+	traces, err := c.TraceFilter(TraceFilterRequest{FromBlock: "0x1", ToBlock: "0x2", ToAddress: []utils.Address{traceUsdc}, Count: 10})
+	if err != nil || len(traces) != 5 {
+		t.Errorf("TraceFilter() got = %v, %v", traces, err)
+	}
+	if _, err := c.TraceTransaction(traceTx); err == nil {
+		t.Errorf("TraceTransaction() error = nil for an unknown trace type")
+	}
+}
+
+func TestEthClient_TraceReplayTransaction(t *testing.T) {
+	client := mocks.New().
+		On(TraceReplayTransaction, traceTx, []string{"trace", "stateDiff"}).ReturnJSON(traceResultsJS).
+		On(TraceCall, NewCallRequest(traceUsdc, "0x"), []string{"trace"}, "0x10").ReturnJSON(traceResultsJS)
+	c := NewFromRPCClient(client)
+
+	results, err := c.TraceReplayTransaction(traceTx, TraceTypeTrace, TraceTypeStateDiff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results.Trace) != 1 || results.Trace[0].Action.(*CallAction).Value != "0x1" {
+		t.Errorf("TraceReplayTransaction() trace = %v", results.Trace)
+	}
+	want := StateDiff{
+		traceHolder: {
+			Balance: Diff{Kind: DiffChanged, From: "0x2", To: "0x1"},
+			Code:    Diff{Kind: DiffUnchanged},
+			Nonce:   Diff{Kind: DiffChanged, From: "0x0", To: "0x1"},
+			Storage: map[utils.Hash]Diff{},
+		},
+		traceContract: {
+			Balance: Diff{Kind: DiffAdded, To: "0x0"},
+			Code:    Diff{Kind: DiffAdded, To: "0x60806040"},
+			Nonce:   Diff{Kind: DiffAdded, To: "0x1"},
+			Storage: map[utils.Hash]Diff{{}: {Kind: DiffRemoved, From: "0x1"}},
+		},
+	}
+	if !reflect.DeepEqual(results.StateDiff, want) {
+		t.Errorf("TraceReplayTransaction() stateDiff = %+v, want %+v", results.StateDiff, want)
+	}
+	data, err := json.Marshal(results.StateDiff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded StateDiff
+	if err := json.Unmarshal(data, &decoded); err != nil || !reflect.DeepEqual(decoded, want) {
+		t.Errorf("Unmarshal() got = %v, %v after Marshal", decoded, err)
+	}
+
+	if _, err := c.TraceCall(NewCallRequest(traceUsdc, "0x"), nil, "0x10"); err != nil {
+		t.Errorf("TraceCall() error = %v", err)
+	}
+}
+
+func TestDiff_UnmarshalJSON(t *testing.T) {
+	for _, data := range []string{`"~"`, `{}`, `1`, `{"*": "0x1"}`} {
+		var diff Diff
+		if err := json.Unmarshal([]byte(data), &diff); err == nil {
+			t.Errorf("UnmarshalJSON(%v) got = %+v, want error", data, diff)
+		}
+	}
+}
@@ -1,5 +1,5 @@
 module github.com/massigerardi/alchemy-api
 
-go 1.20
+go 1.21
 
 require github.com/ybbus/jsonrpc/v3 v3.1.5
@@ -0,0 +1,69 @@
+// Package pipeline fetches jobs concurrently and handles their results in
+// the order of the jobs.
+package pipeline
+
+import "context"
+
+type result[J, R any] struct {
+	job   J
+	value R
+	err   error
+}
+
+// Ordered fetches the jobs returned by next until it reports false, with at
+// most concurrency fetches in flight, and passes their results to handle in
+// the order of the jobs. A concurrency below 1 counts as 1. It returns the
+// first fetch or handle error, or ctx.Err() once every result was handled;
+// next is not called again after an error.
+func Ordered[J, R any](ctx context.Context, concurrency int, next func(ctx context.Context) (J, bool), fetch func(ctx context.Context, job J) (R, error), handle func(ctx context.Context, job J, value R) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	concurrency = max(concurrency, 1)
+
+	// The consumer holds one job and the queue up to concurrency-1 more,
+	// and a job is only read and fetched once queued.
+	queue := make(chan chan result[J, R], concurrency-1)
+	go produce(ctx, queue, next, fetch)
+
+	for pending := range queue {
+		r, ok := <-pending
+		if !ok {
+			break
+		}
+		if r.err != nil {
+			return r.err
+		}
+		if err := handle(ctx, r.job, r.value); err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+// produce queues a pending result before reading the next job, so that no
+// job is read once the consumer stopped. The pending result is closed if
+// there is no job.
+func produce[J, R any](ctx context.Context, queue chan<- chan result[J, R], next func(ctx context.Context) (J, bool), fetch func(ctx context.Context, job J) (R, error)) {
+	defer close(queue)
+	for {
+		pending := make(chan result[J, R], 1)
+		select {
+		case queue <- pending:
+		case <-ctx.Done():
+			return
+		}
+		if ctx.Err() != nil {
+			close(pending)
+			return
+		}
+		job, ok := next(ctx)
+		if !ok {
+			close(pending)
+			return
+		}
+		go func() {
+			value, err := fetch(ctx, job)
+			pending <- result[J, R]{job: job, value: value, err: err}
+		}()
+	}
+}
@@ -0,0 +1,93 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func jobs(n int) func(context.Context) (int, bool) {
+	i := 0
+	return func(context.Context) (int, bool) {
+		if i == n {
+			return 0, false
+		}
+		i++
+		return i - 1, true
+	}
+}
+
+func TestOrdered(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	fetch := func(_ context.Context, job int) (int, error) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		// later jobs finish first
+		time.Sleep(time.Duration(10-job) * time.Millisecond)
+		return job * job, nil
+	}
+	var got []int
+	err := Ordered(context.Background(), 3, jobs(10), fetch, func(_ context.Context, job int, value int) error {
+		if value != job*job {
+			t.Errorf("handle(%v) got = %v, want %v", job, value, job*job)
+		}
+		got = append(got, job)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, job := range got {
+		if job != i {
+			t.Fatalf("handled %v, want jobs in order", got)
+		}
+	}
+	if len(got) != 10 {
+		t.Errorf("handled %v jobs, want 10", len(got))
+	}
+	if m := maxInFlight.Load(); m > 3 {
+		t.Errorf("%v fetches in flight, want at most 3", m)
+	}
+}
+
+func TestOrdered_Error(t *testing.T) {
+	failure := errors.New("connection refused")
+	var handled int
+	err := Ordered(context.Background(), 2, jobs(10), func(_ context.Context, job int) (int, error) {
+		if job == 3 {
+			return 0, failure
+		}
+		return job, nil
+	}, func(context.Context, int, int) error {
+		handled++
+		return nil
+	})
+	if !errors.Is(err, failure) || handled != 3 {
+		t.Errorf("Ordered() error = %v after %v jobs, want %v after 3", err, handled, failure)
+	}
+}
+
+func TestOrdered_NoReadAfterError(t *testing.T) {
+	failure := errors.New("connection refused")
+	reads := 0
+	next := func(context.Context) (int, bool) {
+		reads++
+		return reads, true
+	}
+	err := Ordered(context.Background(), 0, next, func(context.Context, int) (int, error) {
+		return 0, failure
+	}, func(context.Context, int, int) error {
+		return nil
+	})
+	if !errors.Is(err, failure) || reads != 1 {
+		t.Errorf("Ordered() error = %v after %v reads, want %v after 1", err, reads, failure)
+	}
+}
@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/ybbus/jsonrpc/v3"
+)
+
+// Logging logs every call and batch to logger with its method, duration and,
+// for batches, size. Successful requests are logged at debug level, requests
+// answered with RPC errors at warn level with their error codes, and failed
+// requests at error level.
+func Logging(logger *slog.Logger) Middleware {
+	return func(next Invoker) Invoker {
+		return InvokerFuncs{
+			CallFunc: func(ctx context.Context, method string, params ...interface{}) (*jsonrpc.RPCResponse, error) {
+				start := time.Now()
+				response, err := next.Call(ctx, method, params...)
+				attrs := []slog.Attr{slog.String("method", method), slog.Duration("duration", time.Since(start))}
+				switch {
+				case err != nil:
+					logger.LogAttrs(ctx, slog.LevelError, "rpc call failed", append(attrs, slog.String("error", err.Error()))...)
+				case response != nil && response.Error != nil:
+					attrs = append(attrs, slog.Int("code", response.Error.Code), slog.String("error", response.Error.Message))
+					logger.LogAttrs(ctx, slog.LevelWarn, "rpc call returned error", attrs...)
+				default:
+					logger.LogAttrs(ctx, slog.LevelDebug, "rpc call", attrs...)
+				}
+				return response, err
+			},
+			CallBatchFunc: func(ctx context.Context, requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error) {
+				start := time.Now()
+				responses, err := next.CallBatch(ctx, requests)
+				attrs := []slog.Attr{slog.String("method", batchMethods(requests)), slog.Int("size", len(requests)), slog.Duration("duration", time.Since(start))}
+				if err != nil {
+					logger.LogAttrs(ctx, slog.LevelError, "rpc batch failed", append(attrs, slog.String("error", err.Error()))...)
+					return responses, err
+				}
+				var codes []int
+				for _, response := range responses {
+					if response != nil && response.Error != nil {
+						codes = append(codes, response.Error.Code)
+					}
+				}
+				if len(codes) > 0 {
+					logger.LogAttrs(ctx, slog.LevelWarn, "rpc batch returned errors", append(attrs, slog.Any("codes", codes))...)
+				} else {
+					logger.LogAttrs(ctx, slog.LevelDebug, "rpc batch", attrs...)
+				}
+				return responses, err
+			},
+		}
+	}
+}
+
+// batchMethods lists the distinct methods of a batch in order of appearance.
+func batchMethods(requests jsonrpc.RPCRequests) string {
+	var methods []string
+	seen := make(map[string]bool)
+	for _, request := range requests {
+		if request != nil && !seen[request.Method] {
+			seen[request.Method] = true
+			methods = append(methods, request.Method)
+		}
+	}
+	return strings.Join(methods, ",")
+}
+
+// Redact returns a logger replacing every occurrence of secrets in string
+// and error attributes with "[REDACTED]". The errors of jsonrpc.RPCClient
+// contain the endpoint URL, and with it the API key.
+func Redact(logger *slog.Logger, secrets ...string) *slog.Logger {
+	var replacements []string
+	for _, secret := range secrets {
+		if secret != "" {
+			replacements = append(replacements, secret, "[REDACTED]")
+		}
+	}
+	if len(replacements) == 0 {
+		return logger
+	}
+	return slog.New(redactHandler{next: logger.Handler(), replacer: strings.NewReplacer(replacements...)})
+}
+
+type redactHandler struct {
+	next     slog.Handler
+	replacer *strings.Replacer
+}
+
+func (h redactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h redactHandler) Handle(ctx context.Context, record slog.Record) error {
+	redacted := slog.NewRecord(record.Time, record.Level, h.replacer.Replace(record.Message), record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		redacted.AddAttrs(h.redact(attr))
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+func (h redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		redacted[i] = h.redact(attr)
+	}
+	return redactHandler{next: h.next.WithAttrs(redacted), replacer: h.replacer}
+}
+
+func (h redactHandler) WithGroup(name string) slog.Handler {
+	return redactHandler{next: h.next.WithGroup(name), replacer: h.replacer}
+}
+
+func (h redactHandler) redact(attr slog.Attr) slog.Attr {
+	value := attr.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, h.replacer.Replace(value.String()))
+	case slog.KindGroup:
+		group := value.Group()
+		redacted := make([]any, len(group))
+		for i, a := range group {
+			redacted[i] = h.redact(a)
+		}
+		return slog.Group(attr.Key, redacted...)
+	case slog.KindAny:
+		if err, ok := value.Any().(error); ok {
+			return slog.String(attr.Key, h.replacer.Replace(err.Error()))
+		}
+	}
+	return slog.Attr{Key: attr.Key, Value: value}
+}
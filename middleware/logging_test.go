@@ -0,0 +1,171 @@
+package middleware_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/massigerardi/alchemy-api/ethereum"
+	"github.com/massigerardi/alchemy-api/middleware"
+	"github.com/massigerardi/alchemy-api/mocks"
+	"github.com/massigerardi/alchemy-api/utils"
+	"github.com/ybbus/jsonrpc/v3"
+)
+
+func newTestLogger() (*slog.Logger, *bytes.Buffer) {
+	var buf bytes.Buffer
+	return slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})), &buf
+}
+
+func logEntries(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestLogging(t *testing.T) {
+	tests := []struct {
+		name      string
+		client    jsonrpc.RPCClient
+		call      func(c *ethereum.EthClient)
+		wantLevel string
+		wantAttrs map[string]interface{}
+	}{
+		{
+			name:      "Call",
+			client:    mocks.GetMockClient(),
+			call:      func(c *ethereum.EthClient) { _, _ = c.GetBlockNumber() },
+			wantLevel: "DEBUG",
+			wantAttrs: map[string]interface{}{"method": "eth_blockNumber"},
+		},
+		{
+			name:      "RPC Error",
+			client:    mocks.GetMockClient(true),
+			call:      func(c *ethereum.EthClient) { _, _ = c.GetBlockNumber() },
+			wantLevel: "WARN",
+			wantAttrs: map[string]interface{}{"method": "eth_blockNumber", "code": float64(-123)},
+		},
+		{
+			name:      "Failure",
+			client:    middleware.Client(&flakyInvoker{failures: 1, err: errors.New("connection reset")}),
+			call:      func(c *ethereum.EthClient) { _, _ = c.GetBlockNumber() },
+			wantLevel: "ERROR",
+			wantAttrs: map[string]interface{}{"method": "eth_blockNumber", "error": "connection reset"},
+		},
+		{
+			name:   "Batch",
+			client: mocks.GetMockClient(),
+			call: func(c *ethereum.EthClient) {
+				_, _ = c.GetContractCodeBatch([]utils.Address{
+					utils.MustParseAddress("0x549c660ce2b988f588769d6ad87be801695b2be3"),
+					utils.MustParseAddress("0x549c660ce2b988f588769d6ad87be801695b2be1"),
+				})
+			},
+			wantLevel: "WARN",
+			wantAttrs: map[string]interface{}{"method": "eth_getCode", "size": float64(2), "codes": []interface{}{float64(-123)}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, buf := newTestLogger()
+			tt.call(ethereum.NewFromRPCClient(tt.client, ethereum.WithLogger(logger)))
+			entries := logEntries(t, buf)
+			if len(entries) != 1 {
+				t.Fatalf("log entries got = %v, want 1", entries)
+			}
+			entry := entries[0]
+			if entry["level"] != tt.wantLevel {
+				t.Errorf("level got = %v, want %v", entry["level"], tt.wantLevel)
+			}
+			if _, ok := entry["duration"]; !ok {
+				t.Errorf("duration missing in %v", entry)
+			}
+			for key, want := range tt.wantAttrs {
+				if got, _ := json.Marshal(entry[key]); string(got) != mustMarshal(t, want) {
+					t.Errorf("%v got = %s, want %v", key, got, want)
+				}
+			}
+		})
+	}
+}
+
+func mustMarshal(t *testing.T, v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestRedact(t *testing.T) {
+	const apiKey = "s3cr3t-key"
+	logger, buf := newTestLogger()
+	logger = middleware.Redact(logger, apiKey).With("endpoint", ethereum.BaseApiUrl+apiKey)
+	err := errors.New("rpc call eth_blockNumber() on " + ethereum.BaseApiUrl + apiKey + ": connection reset")
+	logger.Error("failed", "error", err, slog.Group("request", "url", ethereum.BaseApiUrl+apiKey))
+
+	if strings.Contains(buf.String(), apiKey) {
+		t.Errorf("log contains API key: %v", buf.String())
+	}
+	if got := strings.Count(buf.String(), "[REDACTED]"); got != 3 {
+		t.Errorf("redactions got = %v, want 3 in %v", got, buf.String())
+	}
+}
+
+func TestRetry_Logging(t *testing.T) {
+	logger, buf := newTestLogger()
+	invoker := &flakyInvoker{RPCClient: mocks.GetMockClient(), failures: 2, err: errors.New("connection reset")}
+	client := middleware.Chain(middleware.Client(invoker), middleware.Retry(middleware.RetryConfig{Backoff: time.Millisecond, Logger: logger}))
+	if _, err := client.Call(context.Background(), ethereum.EthBlockNumber); err != nil {
+		t.Fatal(err)
+	}
+	entries := logEntries(t, buf)
+	if len(entries) != 2 {
+		t.Fatalf("log entries got = %v, want 2", entries)
+	}
+	for i, entry := range entries {
+		if entry["level"] != "WARN" || entry["attempt"] != float64(i+1) || entry["method"] != ethereum.EthBlockNumber {
+			t.Errorf("entry %v got = %v", i, entry)
+		}
+	}
+}
+
+func TestWithRetry_Logging(t *testing.T) {
+	const apiKey = "s3cr3t-key"
+	logger, buf := newTestLogger()
+	invoker := &flakyInvoker{RPCClient: mocks.GetMockClient(), failures: 1, err: errors.New("Post " + ethereum.BaseApiUrl + apiKey + ": connection reset")}
+	c := ethereum.NewFromRPCClient(middleware.Client(invoker), ethereum.WithAPIKey(apiKey), ethereum.WithLogger(logger),
+		ethereum.WithRetry(middleware.RetryConfig{Backoff: time.Millisecond}))
+	if _, err := c.GetBlockNumber(); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), apiKey) {
+		t.Errorf("log contains API key: %v", buf.String())
+	}
+	var retries, calls int
+	for _, entry := range logEntries(t, buf) {
+		switch entry["msg"] {
+		case "retrying rpc call":
+			retries++
+		default:
+			calls++
+		}
+	}
+	if retries != 1 || calls != 2 {
+		t.Errorf("log got %v retries and %v calls, want 1 and 2 in %v", retries, calls, buf.String())
+	}
+}
@@ -0,0 +1,81 @@
+// Package middleware defines the hook point between the Ethereum clients and
+// the underlying jsonrpc.RPCClient. A Middleware wraps an Invoker, seeing
+// both single calls and batches, so concerns such as retries, caching, rate
+// limiting, logging or fault injection compose instead of being hard-coded.
+package middleware
+
+import (
+	"context"
+
+	"github.com/ybbus/jsonrpc/v3"
+)
+
+// Invoker sends single and batch requests. Every jsonrpc.RPCClient is an Invoker.
+type Invoker interface {
+	Call(ctx context.Context, method string, params ...interface{}) (*jsonrpc.RPCResponse, error)
+	CallBatch(ctx context.Context, requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error)
+}
+
+// Middleware wraps an Invoker with additional behaviour.
+type Middleware func(next Invoker) Invoker
+
+// InvokerFuncs adapts a pair of functions to an Invoker.
+type InvokerFuncs struct {
+	CallFunc      func(ctx context.Context, method string, params ...interface{}) (*jsonrpc.RPCResponse, error)
+	CallBatchFunc func(ctx context.Context, requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error)
+}
+
+func (f InvokerFuncs) Call(ctx context.Context, method string, params ...interface{}) (*jsonrpc.RPCResponse, error) {
+	return f.CallFunc(ctx, method, params...)
+}
+
+func (f InvokerFuncs) CallBatch(ctx context.Context, requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error) {
+	return f.CallBatchFunc(ctx, requests)
+}
+
+// Chain wraps client in middlewares. The first middleware is the outermost:
+// it sees requests first and responses last.
+func Chain(client jsonrpc.RPCClient, middlewares ...Middleware) jsonrpc.RPCClient {
+	if len(middlewares) == 0 {
+		return client
+	}
+	var invoker Invoker = client
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		invoker = middlewares[i](invoker)
+	}
+	return Client(invoker)
+}
+
+// Client turns an Invoker into a jsonrpc.RPCClient. CallRaw and
+// CallBatchRaw are sent through Call and CallBatch, so the request ID of a
+// single call and the IDs of a batch are assigned by the underlying client.
+func Client(invoker Invoker) jsonrpc.RPCClient {
+	if client, ok := invoker.(jsonrpc.RPCClient); ok {
+		return client
+	}
+	return invokerClient{invoker}
+}
+
+type invokerClient struct {
+	Invoker
+}
+
+func (c invokerClient) CallRaw(ctx context.Context, request *jsonrpc.RPCRequest) (*jsonrpc.RPCResponse, error) {
+	// jsonrpc.Params leaves already normalized params unchanged
+	return c.Call(ctx, request.Method, request.Params)
+}
+
+func (c invokerClient) CallFor(ctx context.Context, out interface{}, method string, params ...interface{}) error {
+	response, err := c.Call(ctx, method, params...)
+	if err != nil {
+		return err
+	}
+	if response.Error != nil {
+		return response.Error
+	}
+	return response.GetObject(out)
+}
+
+func (c invokerClient) CallBatchRaw(ctx context.Context, requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error) {
+	return c.CallBatch(ctx, requests)
+}
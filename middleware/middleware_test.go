@@ -0,0 +1,194 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/massigerardi/alchemy-api/cache"
+	"github.com/massigerardi/alchemy-api/ethereum"
+	"github.com/massigerardi/alchemy-api/middleware"
+	"github.com/massigerardi/alchemy-api/mocks"
+	"github.com/massigerardi/alchemy-api/utils"
+	"github.com/ybbus/jsonrpc/v3"
+)
+
+// recorder appends name to log on the way in and "/"+name on the way out.
+func recorder(name string, log *[]string) middleware.Middleware {
+	return func(next middleware.Invoker) middleware.Invoker {
+		return middleware.InvokerFuncs{
+			CallFunc: func(ctx context.Context, method string, params ...interface{}) (*jsonrpc.RPCResponse, error) {
+				*log = append(*log, name)
+				defer func() { *log = append(*log, "/"+name) }()
+				return next.Call(ctx, method, params...)
+			},
+			CallBatchFunc: func(ctx context.Context, requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error) {
+				*log = append(*log, name+"[]")
+				defer func() { *log = append(*log, "/"+name+"[]") }()
+				return next.CallBatch(ctx, requests)
+			},
+		}
+	}
+}
+
+// flakyInvoker fails the first failures calls and batches with err or response, then delegates to the mock client.
+type flakyInvoker struct {
+	jsonrpc.RPCClient
+	failures int32
+	err      error
+	response *jsonrpc.RPCResponse
+	calls    atomic.Int32
+}
+
+func (f *flakyInvoker) Call(ctx context.Context, method string, params ...interface{}) (*jsonrpc.RPCResponse, error) {
+	if f.calls.Add(1) <= f.failures {
+		return f.response, f.err
+	}
+	return f.RPCClient.Call(ctx, method, params...)
+}
+
+func (f *flakyInvoker) CallBatch(ctx context.Context, requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error) {
+	if f.calls.Add(1) <= f.failures {
+		if f.err != nil {
+			return nil, f.err
+		}
+		return jsonrpc.RPCResponses{f.response}, nil
+	}
+	return f.RPCClient.CallBatch(ctx, requests)
+}
+
+func TestChain_Order(t *testing.T) {
+	var log []string
+	c := ethereum.NewFromRPCClient(mocks.GetMockClient(), ethereum.WithMiddleware(recorder("a", &log), recorder("b", &log)))
+
+	if got, err := c.GetBlockNumber(); err != nil || got != "0x1234" {
+		t.Fatalf("GetBlockNumber() = %v, %v", got, err)
+	}
+	want := []string{"a", "b", "/b", "/a"}
+	if !reflect.DeepEqual(log, want) {
+		t.Errorf("Call order got = %v, want %v", log, want)
+	}
+
+	log = nil
+	addresses := []utils.Address{utils.MustParseAddress("0x549c660ce2b988f588769d6ad87be801695b2be3")}
+	if _, err := c.GetContractCodeBatch(addresses); err != nil {
+		t.Fatal(err)
+	}
+	want = []string{"a[]", "b[]", "/b[]", "/a[]"}
+	if !reflect.DeepEqual(log, want) {
+		t.Errorf("CallBatch order got = %v, want %v", log, want)
+	}
+}
+
+func TestChain_NoMiddleware(t *testing.T) {
+	client := mocks.GetMockClient()
+	if got := middleware.Chain(client); got != client {
+		t.Errorf("Chain() got = %v, want %v", got, client)
+	}
+}
+
+func TestChain_WithCache(t *testing.T) {
+	var log []string
+	c := ethereum.NewFromRPCClient(mocks.GetMockClient(), ethereum.WithMiddleware(
+		cache.Middleware(cache.Config{}),
+		recorder("upstream", &log),
+	))
+	address := utils.MustParseAddress("0x549c660ce2b988f588769d6ad87be801695b2be3")
+	for i := 0; i < 3; i++ {
+		if _, err := c.GetContractCode(address, "0x1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the first call fetches the head to decide finality, the second the code
+	if len(log) != 4 {
+		t.Errorf("upstream calls got = %v, want 2", log)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	transient := errors.New("connection reset")
+	tests := []struct {
+		name      string
+		invoker   *flakyInvoker
+		attempts  int
+		wantErr   bool
+		wantCalls int32
+	}{
+		{name: "Transport Error", invoker: &flakyInvoker{failures: 2, err: transient}, wantCalls: 3},
+		{name: "Too Many Requests", invoker: &flakyInvoker{failures: 1, err: &jsonrpc.HTTPError{Code: http.StatusTooManyRequests}}, wantCalls: 2},
+		{name: "Rate Limited Response", invoker: &flakyInvoker{failures: 1, response: &jsonrpc.RPCResponse{Error: &jsonrpc.RPCError{Code: 429}}}, wantCalls: 2},
+		{name: "Bad Request", invoker: &flakyInvoker{failures: 1, err: &jsonrpc.HTTPError{Code: http.StatusBadRequest}}, wantErr: true, wantCalls: 1},
+		{name: "Exhausted", invoker: &flakyInvoker{failures: 5, err: transient}, attempts: 2, wantErr: true, wantCalls: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.invoker.RPCClient = mocks.GetMockClient()
+			retry := middleware.Retry(middleware.RetryConfig{Attempts: tt.attempts, Backoff: time.Millisecond})
+			c := ethereum.NewFromRPCClient(middleware.Client(tt.invoker), ethereum.WithMiddleware(retry))
+			_, err := c.GetBlockNumber()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetBlockNumber() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := tt.invoker.calls.Load(); got != tt.wantCalls {
+				t.Errorf("calls got = %v, want %v", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetry_Batch(t *testing.T) {
+	invoker := &flakyInvoker{RPCClient: mocks.GetMockClient(), failures: 1, response: &jsonrpc.RPCResponse{Error: &jsonrpc.RPCError{Code: 429}}}
+	c := ethereum.NewFromRPCClient(middleware.Client(invoker), ethereum.WithMiddleware(middleware.Retry(middleware.RetryConfig{Backoff: time.Millisecond})))
+	addresses := []utils.Address{utils.MustParseAddress("0x549c660ce2b988f588769d6ad87be801695b2be3")}
+	got, err := c.GetContractCodeBatch(addresses)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Error != nil || got[0].Code != mocks.EoaCode {
+		t.Errorf("GetContractCodeBatch() got = %+v", got)
+	}
+	if calls := invoker.calls.Load(); calls != 2 {
+		t.Errorf("calls got = %v, want 2", calls)
+	}
+}
+
+func TestRetry_ContextDone(t *testing.T) {
+	invoker := &flakyInvoker{RPCClient: mocks.GetMockClient(), failures: 5, err: errors.New("connection reset")}
+	client := middleware.Chain(middleware.Client(invoker), middleware.Retry(middleware.RetryConfig{Backoff: time.Hour}))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := client.Call(ctx, ethereum.EthBlockNumber); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Call() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	client := middleware.Chain(mocks.GetMockClient(), middleware.RateLimit(100))
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if _, err := client.Call(context.Background(), ethereum.EthBlockNumber); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the first call is immediate, the other four wait 10ms each
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("5 calls took %v, want at least 40ms", elapsed)
+	}
+}
+
+func TestRateLimit_NoLimit(t *testing.T) {
+	for _, perSecond := range []float64{0, -1} {
+		client := middleware.Chain(mocks.GetMockClient(), middleware.RateLimit(perSecond))
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		for i := 0; i < 5; i++ {
+			if _, err := client.Call(ctx, ethereum.EthBlockNumber); err != nil {
+				t.Fatalf("RateLimit(%v) Call() error = %v", perSecond, err)
+			}
+		}
+		cancel()
+	}
+}
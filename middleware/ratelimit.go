@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ybbus/jsonrpc/v3"
+)
+
+// RateLimit spaces requests so that at most perSecond are sent every
+// second. A batch counts as a single request. A perSecond of zero or less
+// sets no limit.
+func RateLimit(perSecond float64) Middleware {
+	if perSecond <= 0 {
+		return func(next Invoker) Invoker { return next }
+	}
+	limiter := &limiter{interval: time.Duration(float64(time.Second) / perSecond)}
+	return func(next Invoker) Invoker {
+		return InvokerFuncs{
+			CallFunc: func(ctx context.Context, method string, params ...interface{}) (*jsonrpc.RPCResponse, error) {
+				if err := limiter.wait(ctx); err != nil {
+					return nil, err
+				}
+				return next.Call(ctx, method, params...)
+			},
+			CallBatchFunc: func(ctx context.Context, requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error) {
+				if err := limiter.wait(ctx); err != nil {
+					return nil, err
+				}
+				return next.CallBatch(ctx, requests)
+			},
+		}
+	}
+}
+
+type limiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// wait reserves the next free slot and sleeps until it starts.
+func (l *limiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	slot := l.next
+	l.next = l.next.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := time.Until(slot)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/ybbus/jsonrpc/v3"
+)
+
+const (
+	DefaultRetryAttempts = 3
+	DefaultRetryBackoff  = 100 * time.Millisecond
+)
+
+// rateLimitedCode is the JSON-RPC error code Alchemy returns when the request rate is exceeded.
+const rateLimitedCode = 429
+
+type RetryConfig struct {
+	// Attempts is the total number of attempts, DefaultRetryAttempts if zero.
+	Attempts int
+	// Backoff is the delay before the first retry, doubled for every further
+	// one, DefaultRetryBackoff if zero.
+	Backoff time.Duration
+	// Retryable decides whether a failed attempt is retried, IsRetryable if nil.
+	Retryable func(response *jsonrpc.RPCResponse, err error) bool
+	// Logger receives a warning for every retry, nothing is logged if nil.
+	// Use Redact to keep the API key out of the logged errors, as
+	// ethereum.WithRetry does with the logger of the client.
+	Logger *slog.Logger
+}
+
+// IsRetryable reports whether a call failed because of rate limiting, a
+// server error or the network, rather than because the request was invalid.
+func IsRetryable(response *jsonrpc.RPCResponse, err error) bool {
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return false
+		}
+		var httpError *jsonrpc.HTTPError
+		if errors.As(err, &httpError) {
+			return httpError.Code == http.StatusTooManyRequests || httpError.Code >= http.StatusInternalServerError
+		}
+		return true
+	}
+	return response != nil && response.Error != nil && response.Error.Code == rateLimitedCode
+}
+
+// Retry retries failed single calls and batches with exponential backoff.
+// A batch is retried as a whole when the batch itself or any of its responses is retryable.
+func Retry(config RetryConfig) Middleware {
+	if config.Attempts <= 0 {
+		config.Attempts = DefaultRetryAttempts
+	}
+	if config.Backoff <= 0 {
+		config.Backoff = DefaultRetryBackoff
+	}
+	if config.Retryable == nil {
+		config.Retryable = IsRetryable
+	}
+	return func(next Invoker) Invoker {
+		return InvokerFuncs{
+			CallFunc: func(ctx context.Context, method string, params ...interface{}) (*jsonrpc.RPCResponse, error) {
+				var response *jsonrpc.RPCResponse
+				err := retry(ctx, config, method, func() (string, error) {
+					var err error
+					response, err = next.Call(ctx, method, params...)
+					if !config.Retryable(response, err) {
+						return "", err
+					}
+					return retryCause(response, err), err
+				})
+				return response, err
+			},
+			CallBatchFunc: func(ctx context.Context, requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error) {
+				var responses jsonrpc.RPCResponses
+				err := retry(ctx, config, batchMethods(requests), func() (string, error) {
+					var err error
+					responses, err = next.CallBatch(ctx, requests)
+					if err != nil {
+						if !config.Retryable(nil, err) {
+							return "", err
+						}
+						return retryCause(nil, err), err
+					}
+					for _, response := range responses {
+						if config.Retryable(response, nil) {
+							return retryCause(response, nil), nil
+						}
+					}
+					return "", nil
+				})
+				return responses, err
+			},
+		}
+	}
+}
+
+// retry runs attempt until it returns no cause for a retry, the attempts
+// are exhausted or ctx is done, and returns the last error.
+func retry(ctx context.Context, config RetryConfig, method string, attempt func() (string, error)) error {
+	backoff := config.Backoff
+	for i := 1; ; i++ {
+		cause, err := attempt()
+		if cause == "" || i == config.Attempts {
+			return err
+		}
+		if config.Logger != nil {
+			config.Logger.LogAttrs(ctx, slog.LevelWarn, "retrying rpc call",
+				slog.String("method", method), slog.Int("attempt", i), slog.Duration("backoff", backoff), slog.String("error", cause))
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+		backoff *= 2
+	}
+}
+
+// retryCause describes why an attempt is retried.
+func retryCause(response *jsonrpc.RPCResponse, err error) string {
+	var httpError *jsonrpc.HTTPError
+	switch {
+	case errors.As(err, &httpError):
+		return fmt.Sprintf("http status %v", httpError.Code)
+	case err != nil:
+		return err.Error()
+	case response != nil && response.Error != nil:
+		return response.Error.Error()
+	}
+	return "retryable response"
+}
@@ -0,0 +1,382 @@
+package mocks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ybbus/jsonrpc/v3"
+)
+
+// ErrUnexpectedCall is returned for requests matching no expectation.
+var ErrUnexpectedCall = errors.New("mocks: unexpected call")
+
+// Any matches any value at its position in the params of On.
+var Any = anyParam{}
+
+type anyParam struct{}
+
+// Client is a jsonrpc.RPCClient answering from expectations set up with a
+// fluent builder and recording every request for assertions:
+//
+//	client := mocks.New().
+//		On("eth_getBalance", address, "latest").Return("0x1").
+//		On("eth_blockNumber").Once().Return("0x1").
+//		On("eth_blockNumber").Return("0x2").
+//		OnBatch("eth_getCode", "eth_getCode").Error(err)
+//
+// Requests are matched against the expectations in the order they were
+// added. Params are compared as JSON ignoring the case of strings, so an
+// address matches both in checksum and in lower case. Requests of a batch
+// are answered one by one from the On expectations unless an OnBatch
+// expectation matches the whole batch. It is safe for concurrent use.
+type Client struct {
+	mu           sync.Mutex
+	expectations []*Expectation
+	batches      []*BatchExpectation
+	calls        []Call
+}
+
+// Call is a request received by the Client.
+type Call struct {
+	Method string
+	// Params holds the params as JSON.
+	Params json.RawMessage
+	// Batch tells whether the request was part of a batch.
+	Batch bool
+}
+
+func New() *Client {
+	return &Client{}
+}
+
+// Expectation answers the requests matching a method and params.
+type Expectation struct {
+	client   *Client
+	method   string
+	params   []interface{}
+	match    func(params []json.RawMessage) bool
+	times    int
+	result   interface{}
+	rpcError *jsonrpc.RPCError
+	err      error
+}
+
+// On adds an expectation for method. Without params, it matches the method
+// with any params; otherwise params must match the request params one by
+// one, Any matching every value.
+func (c *Client) On(method string, params ...interface{}) *Expectation {
+	expectation := &Expectation{client: c, method: method, params: params}
+	c.mu.Lock()
+	c.expectations = append(c.expectations, expectation)
+	c.mu.Unlock()
+	return expectation
+}
+
+// OnMatch adds an expectation for method with params accepted by match,
+// which receives each param as JSON.
+func (c *Client) OnMatch(method string, match func(params []json.RawMessage) bool) *Expectation {
+	expectation := c.On(method)
+	expectation.match = match
+	return expectation
+}
+
+// Once limits the expectation to a single request.
+func (e *Expectation) Once() *Expectation {
+	return e.Times(1)
+}
+
+// Times limits the expectation to n requests, after which the next matching expectation is used.
+func (e *Expectation) Times(n int) *Expectation {
+	e.client.mu.Lock()
+	defer e.client.mu.Unlock()
+	e.times = n
+	return e
+}
+
+// Return answers with result, which is decoded by the caller like a result
+// sent by Alchemy: use strings for quantities and data, maps, slices or
+// structs for objects.
+func (e *Expectation) Return(result interface{}) *Client {
+	e.client.mu.Lock()
+	defer e.client.mu.Unlock()
+	e.result = result
+	return e.client
+}
+
+// ReturnJSON answers with result given as JSON, e.g. a response captured from Alchemy.
+func (e *Expectation) ReturnJSON(result string) *Client {
+	decoded, err := decodeJSON([]byte(result))
+	if err != nil {
+		panic(fmt.Sprintf("mocks: invalid JSON result for %v: %v", e.method, err))
+	}
+	return e.Return(decoded)
+}
+
+// ReturnError answers with an RPC error.
+func (e *Expectation) ReturnError(code int, message string) *Client {
+	e.client.mu.Lock()
+	defer e.client.mu.Unlock()
+	e.rpcError = &jsonrpc.RPCError{Code: code, Message: message}
+	return e.client
+}
+
+// Error fails the request with err, as the client does for transport errors.
+func (e *Expectation) Error(err error) *Client {
+	e.client.mu.Lock()
+	defer e.client.mu.Unlock()
+	e.err = err
+	return e.client
+}
+
+// BatchExpectation answers a whole batch.
+type BatchExpectation struct {
+	client    *Client
+	methods   []string
+	times     int
+	responses jsonrpc.RPCResponses
+	err       error
+}
+
+// OnBatch adds an expectation for batches made of requests for methods, in
+// order. Without methods, it matches any batch.
+func (c *Client) OnBatch(methods ...string) *BatchExpectation {
+	expectation := &BatchExpectation{client: c, methods: methods}
+	c.mu.Lock()
+	c.batches = append(c.batches, expectation)
+	c.mu.Unlock()
+	return expectation
+}
+
+// Once limits the expectation to a single batch.
+func (e *BatchExpectation) Once() *BatchExpectation {
+	e.client.mu.Lock()
+	defer e.client.mu.Unlock()
+	e.times = 1
+	return e
+}
+
+// Return answers the batch with responses. Responses without an ID get the
+// ID of the request at the same position.
+func (e *BatchExpectation) Return(responses ...*jsonrpc.RPCResponse) *Client {
+	e.client.mu.Lock()
+	defer e.client.mu.Unlock()
+	e.responses = responses
+	return e.client
+}
+
+// Error fails the batch with err.
+func (e *BatchExpectation) Error(err error) *Client {
+	e.client.mu.Lock()
+	defer e.client.mu.Unlock()
+	e.err = err
+	return e.client
+}
+
+// Calls returns the requests received so far in order.
+func (c *Client) Calls() []Call {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Call(nil), c.calls...)
+}
+
+// CallCount returns the number of requests received for method.
+func (c *Client) CallCount(method string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	count := 0
+	for _, call := range c.calls {
+		if call.Method == method {
+			count++
+		}
+	}
+	return count
+}
+
+// Called tells whether a request for method with params matching those
+// given, as in On, was received.
+func (c *Client) Called(method string, params ...interface{}) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, call := range c.calls {
+		if call.Method == method && (len(params) == 0 || matchParams(params, call.Params)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Client) Call(_ context.Context, method string, params ...interface{}) (*jsonrpc.RPCResponse, error) {
+	return c.call(method, jsonrpc.Params(params...), 0, false)
+}
+
+func (c *Client) CallRaw(_ context.Context, request *jsonrpc.RPCRequest) (*jsonrpc.RPCResponse, error) {
+	return c.call(request.Method, request.Params, request.ID, false)
+}
+
+func (c *Client) CallFor(ctx context.Context, out interface{}, method string, params ...interface{}) error {
+	response, err := c.Call(ctx, method, params...)
+	if err != nil {
+		return err
+	}
+	if response.Error != nil {
+		return response.Error
+	}
+	return response.GetObject(out)
+}
+
+// CallBatch answers the batch from a matching OnBatch expectation or else
+// request by request. A request matching no expectation fails the batch.
+func (c *Client) CallBatch(_ context.Context, requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error) {
+	if responses, ok, err := c.callBatch(requests); ok {
+		return responses, err
+	}
+	responses := make(jsonrpc.RPCResponses, len(requests))
+	for i, request := range requests {
+		response, err := c.call(request.Method, request.Params, request.ID, true)
+		if err != nil {
+			return nil, err
+		}
+		responses[i] = response
+	}
+	return responses, nil
+}
+
+func (c *Client) CallBatchRaw(ctx context.Context, requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error) {
+	return c.CallBatch(ctx, requests)
+}
+
+func (c *Client) callBatch(requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, expectation := range c.batches {
+		if expectation.times < 0 || !matchMethods(expectation.methods, requests) {
+			continue
+		}
+		if expectation.times > 0 {
+			expectation.times--
+			if expectation.times == 0 {
+				expectation.times = -1
+			}
+		}
+		for _, request := range requests {
+			encoded, _ := json.Marshal(request.Params)
+			c.calls = append(c.calls, Call{Method: request.Method, Params: encoded, Batch: true})
+		}
+		if expectation.err != nil {
+			return nil, true, expectation.err
+		}
+		responses := make(jsonrpc.RPCResponses, len(expectation.responses))
+		for i, response := range expectation.responses {
+			copied := *response
+			if copied.ID == 0 && i < len(requests) {
+				copied.ID = requests[i].ID
+			}
+			responses[i] = &copied
+		}
+		return responses, true, nil
+	}
+	return nil, false, nil
+}
+
+func (c *Client) call(method string, params interface{}, id int, batch bool) (*jsonrpc.RPCResponse, error) {
+	encoded, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls = append(c.calls, Call{Method: method, Params: encoded, Batch: batch})
+	for _, expectation := range c.expectations {
+		if expectation.times < 0 || expectation.method != method || !expectation.matches(encoded) {
+			continue
+		}
+		if expectation.times > 0 {
+			expectation.times--
+			if expectation.times == 0 {
+				expectation.times = -1
+			}
+		}
+		if expectation.err != nil {
+			return nil, expectation.err
+		}
+		return &jsonrpc.RPCResponse{ID: id, Result: expectation.result, Error: copyError(expectation.rpcError)}, nil
+	}
+	return nil, fmt.Errorf("%w: %v %s", ErrUnexpectedCall, method, encoded)
+}
+
+func (e *Expectation) matches(encoded json.RawMessage) bool {
+	if e.match != nil {
+		var params []json.RawMessage
+		if json.Unmarshal(encoded, &params) != nil {
+			return false
+		}
+		return e.match(params)
+	}
+	return len(e.params) == 0 || matchParams(e.params, encoded)
+}
+
+// matchParams compares expected params with the JSON encoded params of a request.
+func matchParams(expected []interface{}, encoded json.RawMessage) bool {
+	var params []json.RawMessage
+	if json.Unmarshal(encoded, &params) != nil || len(params) != len(expected) {
+		return false
+	}
+	for i, want := range expected {
+		if _, ok := want.(anyParam); ok {
+			continue
+		}
+		wantJSON, err := json.Marshal(want)
+		if err != nil || !equalJSON(wantJSON, params[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// equalJSON compares two JSON values ignoring the case of strings.
+func equalJSON(a, b []byte) bool {
+	var decodedA, decodedB interface{}
+	if json.Unmarshal(bytes.ToLower(a), &decodedA) != nil || json.Unmarshal(bytes.ToLower(b), &decodedB) != nil {
+		return false
+	}
+	normalizedA, _ := json.Marshal(decodedA)
+	normalizedB, _ := json.Marshal(decodedB)
+	return bytes.Equal(normalizedA, normalizedB)
+}
+
+func matchMethods(methods []string, requests jsonrpc.RPCRequests) bool {
+	if len(methods) == 0 {
+		return true
+	}
+	if len(methods) != len(requests) {
+		return false
+	}
+	for i, request := range requests {
+		if methods[i] != request.Method {
+			return false
+		}
+	}
+	return true
+}
+
+func copyError(rpcError *jsonrpc.RPCError) *jsonrpc.RPCError {
+	if rpcError == nil {
+		return nil
+	}
+	copied := *rpcError
+	return &copied
+}
+
+func decodeJSON(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var decoded interface{}
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
@@ -0,0 +1,135 @@
+package mocks_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/massigerardi/alchemy-api/ethereum"
+	"github.com/massigerardi/alchemy-api/mocks"
+	"github.com/massigerardi/alchemy-api/utils"
+	"github.com/ybbus/jsonrpc/v3"
+)
+
+var (
+	holder = utils.MustParseAddress("0x549c660ce2b988f588769d6ad87be801695b2be3")
+	usdc   = utils.MustParseAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+)
+
+func TestClient_On(t *testing.T) {
+	client := mocks.New().
+		On(ethereum.EthGetBalance, holder, ethereum.Latest).Return("0x1").
+		On(ethereum.EthGetBalance, "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", mocks.Any).Return("0x2").
+		On(ethereum.EthGetCode, holder).ReturnError(-32000, "header not found")
+	c := ethereum.NewFromRPCClient(client)
+
+	tests := []struct {
+		name    string
+		address utils.Address
+		block   string
+		want    int64
+		wantErr bool
+	}{
+		{name: "Exact Params", address: holder, block: ethereum.Latest, want: 1},
+		{name: "Other Block", address: holder, block: "0x1", wantErr: true},
+		{name: "Any Param", address: usdc, block: "0x1", want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.GetBalance(tt.address, tt.block)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetBalance() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.Int64() != tt.want {
+				t.Errorf("GetBalance() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := c.GetContractCode(usdc); !errors.Is(err, mocks.ErrUnexpectedCall) {
+		t.Errorf("GetContractCode() error = %v, want %v", err, mocks.ErrUnexpectedCall)
+	}
+}
+
+func TestClient_Once(t *testing.T) {
+	c := ethereum.NewFromRPCClient(mocks.New().
+		On(ethereum.EthBlockNumber).Once().Return("0x1").
+		On(ethereum.EthBlockNumber).Times(2).Return("0x2").
+		On(ethereum.EthBlockNumber).Return("0x3"))
+	for _, want := range []string{"0x1", "0x2", "0x2", "0x3", "0x3"} {
+		if got, err := c.GetBlockNumber(); err != nil || got != want {
+			t.Errorf("GetBlockNumber() = %v, %v, want %v", got, err, want)
+		}
+	}
+}
+
+func TestClient_Error(t *testing.T) {
+	failure := errors.New("connection refused")
+	c := ethereum.NewFromRPCClient(mocks.New().On(ethereum.EthGasPrice).Error(failure))
+	if _, err := c.GetGasPrice(); !errors.Is(err, failure) {
+		t.Errorf("GetGasPrice() error = %v, want %v", err, failure)
+	}
+}
+
+func TestClient_ReturnJSON(t *testing.T) {
+	c := ethereum.NewFromRPCClient(mocks.New().On(ethereum.EthGetBlockByNumber, "0x429d3b", false).ReturnJSON(mocks.BlockJS))
+	block, err := c.GetBlockByNumber("0x429d3b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block.Hash != "0x8243343df08b9751f5ca0c5f8c9c0460d8a9b6351066fae0acbd4d3e776de8bb" {
+		t.Errorf("GetBlockByNumber() got = %+v", block)
+	}
+}
+
+func TestClient_Batch(t *testing.T) {
+	client := mocks.New().
+		On(ethereum.EthGetCode, holder, mocks.Any).Return(mocks.EoaCode).
+		On(ethereum.EthGetCode, usdc, mocks.Any).Return(mocks.UsdcCode)
+	c := ethereum.NewFromRPCClient(client)
+
+	codes, err := c.GetContractCodeBatch([]utils.Address{holder, usdc})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if codes[0].Code != mocks.EoaCode || codes[1].Code != mocks.UsdcCode {
+		t.Errorf("GetContractCodeBatch() got = %v, %v", codes[0], codes[1])
+	}
+
+	failure := errors.New("batch too large")
+	client.OnBatch(ethereum.EthGetCode, ethereum.EthGetCode).Once().Error(failure)
+	if _, err := c.GetContractCodeBatch([]utils.Address{holder, usdc}); !errors.Is(err, failure) {
+		t.Errorf("GetContractCodeBatch() error = %v, want %v", err, failure)
+	}
+	if _, err := c.GetContractCodeBatch([]utils.Address{holder, usdc}); err != nil {
+		t.Errorf("GetContractCodeBatch() error = %v after the batch expectation was used", err)
+	}
+
+	client.OnBatch().Return(&jsonrpc.RPCResponse{Error: &jsonrpc.RPCError{Code: -32005, Message: "limit exceeded"}})
+	codes, err = c.GetContractCodeBatch([]utils.Address{holder})
+	if err != nil || codes[0].Error == nil {
+		t.Errorf("GetContractCodeBatch() = %v, %v, want error response", codes, err)
+	}
+}
+
+func TestClient_Calls(t *testing.T) {
+	client := mocks.New().On(ethereum.EthGetCode).Return("0x")
+	c := ethereum.NewFromRPCClient(client)
+	_, _ = c.GetContractCode(holder)
+	_, _ = c.GetContractCodeBatch([]utils.Address{usdc})
+	_, _ = client.Call(context.Background(), ethereum.EthBlockNumber)
+
+	if got := client.CallCount(ethereum.EthGetCode); got != 2 {
+		t.Errorf("CallCount() got = %v, want 2", got)
+	}
+	if !client.Called(ethereum.EthGetCode, "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", ethereum.Latest) {
+		t.Errorf("Called() got = false, want true")
+	}
+	if client.Called(ethereum.EthGetCode, usdc, "0x1") {
+		t.Errorf("Called() got = true for other params")
+	}
+	calls := client.Calls()
+	if len(calls) != 3 || calls[0].Batch || !calls[1].Batch || calls[2].Method != ethereum.EthBlockNumber {
+		t.Errorf("Calls() got = %+v", calls)
+	}
+}
@@ -1,9 +1,7 @@
 package mocks
 
 import (
-  "context"
   "encoding/json"
-  "fmt"
 
   "github.com/ybbus/jsonrpc/v3"
 )
@@ -26,161 +24,61 @@ const JS = `[
         }
     ]`
 
+const BlockJS = `{
+        "number": "0x429d3b",
+        "hash": "0x8243343df08b9751f5ca0c5f8c9c0460d8a9b6351066fae0acbd4d3e776de8bb",
+        "parentHash": "0xe99e022112df268087ea7eafaf4790497fd21dbeeb6bd7a1721df161a6657a54",
+        "stateRoot": "0xddc8b0234c2e0cad087c8b389aa7ef01f7d79b2570bccb77ce48648aa61c904d",
+        "timestamp": "0x55ba467c",
+        "gasUsed": "0x0",
+        "transactions": [],
+        "uncles": []
+    }`
+
 const UsdcCode = "0x608060405260043610"
 const EoaCode = "0x"
 
-func getMap(obj interface{}) (map[string]interface{}, error) {
-  js, err := json.Marshal(obj)
-  if err != nil {
-    return nil, err
-  }
-  result := make(map[string]interface{})
-  err = json.Unmarshal(js, &result)
-  if err != nil {
-    return nil, err
+// GetMockClient returns a client answering the fixtures used across the
+// package tests. With wantErr, eth_blockNumber and eth_gasPrice return an RPC error.
+func GetMockClient(wantErr ...bool) jsonrpc.RPCClient {
+  client := New()
+  if len(wantErr) > 0 && wantErr[0] {
+    client.On("eth_blockNumber").ReturnError(-123, "wrong Response")
+    client.On("eth_gasPrice").ReturnError(-123, "wrong Response")
   }
-  return result, nil
-}
 
-type mockClient struct {
-  jsonrpc.RPCClient
-  wantErr bool
-}
+  client.On("eth_blockNumber").Return("0x1234")
+  client.On("eth_gasPrice").Return("0x2c138aa7c")
 
-func (m mockClient) Call(_ context.Context, method string, params ...interface{}) (*jsonrpc.RPCResponse, error) {
-  if method == "eth_blockNumber" {
-    if m.wantErr {
-      return &jsonrpc.RPCResponse{Error: &jsonrpc.RPCError{
-        Code:    -123,
-        Message: "wrong Response",
-        Data:    nil,
-      }}, nil
-    }
-    return &jsonrpc.RPCResponse{Result: "0x1234"}, nil
-  }
-  if method == "eth_getCode" {
-    address := params[0].(string)
-    switch address {
-    case "0x549c660ce2b988f588769d6ad87be801695b2be3":
-      return &jsonrpc.RPCResponse{Result: EoaCode}, nil
-    case "0x549c660ce2b988f588769d6ad87be801695b2be1":
-      return &jsonrpc.RPCResponse{Error: &jsonrpc.RPCError{
-        Code:    -123,
-        Message: "wrong Response",
-        Data:    nil,
-      }}, nil
-    case "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB49":
-      return &jsonrpc.RPCResponse{Result: EoaCode}, nil
-    case "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48":
-      return &jsonrpc.RPCResponse{Result: UsdcCode}, nil
-    default:
-      return &jsonrpc.RPCResponse{Result: ""}, nil
-    }
-  }
-  if method == "eth_getBalance" {
-    address := params[0].(string)
-    switch address {
-    case "0x549c660ce2b988f588769d6ad87be801695b2be3":
-      return &jsonrpc.RPCResponse{Result: "0x474a58f10b7140"}, nil
-    case "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48":
-      return &jsonrpc.RPCResponse{Result: ""}, nil
-    case "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB47":
-      return &jsonrpc.RPCResponse{Result: "", Error: &jsonrpc.RPCError{
-        Code:    -1234,
-        Message: "Test Error",
-        Data:    nil,
-      }}, nil
-    default:
-      return &jsonrpc.RPCResponse{Result: "0x0"}, nil
-    }
-  }
-  if method == "eth_getLogs" {
-    received := params[0].([]interface{})
-    request, _ := getMap(received[0])
-    toBlock := request["toBlock"]
-    switch toBlock {
-    case "pending":
-      return &jsonrpc.RPCResponse{Result: nil, Error: &jsonrpc.RPCError{
-        Code:    -1234,
-        Message: "Test Error",
-        Data:    nil,
-      }}, nil
-    case "latest":
+  client.On("eth_getCode", "0x549c660ce2b988f588769d6ad87be801695b2be3", Any).Return(EoaCode)
+  client.On("eth_getCode", "0x549c660ce2b988f588769d6ad87be801695b2be1", Any).ReturnError(-123, "wrong Response")
+  client.On("eth_getCode", "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb49", Any).Return(EoaCode)
+  client.On("eth_getCode", "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", Any).Return(UsdcCode)
+  client.On("eth_getCode").Return("")
 
-      result := make([]map[string]interface{}, 1)
-      err := json.Unmarshal([]byte(JS), &result)
-      if err != nil {
-        return nil, err
-      }
-      return &jsonrpc.RPCResponse{
-        Result: result,
-        Error:  nil}, nil
-    default:
-      return &jsonrpc.RPCResponse{Result: "0x474a58f10b7140"}, nil
-    }
-  }
-  if method == "eth_gasPrice" {
-    if m.wantErr {
-      return &jsonrpc.RPCResponse{Error: &jsonrpc.RPCError{
-        Code:    -123,
-        Message: "wrong Response",
-        Data:    nil,
-      }}, nil
-    }
-    return &jsonrpc.RPCResponse{Result: "0x2c138aa7c"}, nil
-  }
+  client.On("eth_getBalance", "0x549c660ce2b988f588769d6ad87be801695b2be3", Any).Return("0x474a58f10b7140")
+  client.On("eth_getBalance", "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", Any).Return("")
+  client.On("eth_getBalance", "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb47", Any).ReturnError(-1234, "Test Error")
+  client.On("eth_getBalance", "0x558fa75074cc7cf045c764aed47d37776ea697d1", Any).ReturnError(-123, "wrong Response")
+  client.On("eth_getBalance", "0x558fa75074cc7cf045c764aed47d37776ea697d2", Any).Return("0x19B225CEC6808")
+  client.On("eth_getBalance").Return("0x0")
 
-  return nil, fmt.Errorf("method not supported")
-}
+  client.OnMatch("eth_getLogs", toBlock("pending")).ReturnError(-1234, "Test Error")
+  client.OnMatch("eth_getLogs", toBlock("latest")).ReturnJSON(JS)
+  client.On("eth_getLogs").Return("0x474a58f10b7140")
 
-func (m mockClient) CallBatch(_ context.Context, requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error) {
-  responses := make(jsonrpc.RPCResponses, len(requests))
-  for i, request := range requests {
-    id := request.ID
-    method := request.Method
-    if method == "eth_getCode" {
-      address := request.Params.([]interface{})[0].(string)
-      switch address {
-      case "0x549c660ce2b988f588769d6ad87be801695b2be3":
-        responses[i] = &jsonrpc.RPCResponse{ID: id, Result: EoaCode}
-      case "0x549c660ce2b988f588769d6ad87be801695b2be1":
-        responses[i] = &jsonrpc.RPCResponse{ID: id, Error: &jsonrpc.RPCError{
-          Code:    -123,
-          Message: "wrong Response",
-          Data:    nil,
-        }}
-      case "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB49":
-        responses[i] = &jsonrpc.RPCResponse{ID: id, Result: EoaCode}
-      case "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48":
-        responses[i] = &jsonrpc.RPCResponse{ID: id, Result: UsdcCode}
-      default:
-        responses[i] = &jsonrpc.RPCResponse{ID: id, Result: ""}
-      }
-    }
-    if method == "eth_getBalance" {
-      address := request.Params.([]interface{})[0].(string)
-      switch address {
-      case "0x549c660ce2b988f588769d6ad87be801695b2be3":
-        responses[i] = &jsonrpc.RPCResponse{Result: "0x474a58f10b7140"}
-      case "0x558FA75074cc7cF045C764aEd47D37776Ea697d1":
-        responses[i] = &jsonrpc.RPCResponse{ID: id, Error: &jsonrpc.RPCError{
-          Code:    -123,
-          Message: "wrong Response",
-          Data:    nil,
-        }}
-      case "0x558FA75074cc7cF045C764aEd47D37776Ea697d2":
-        responses[i] = &jsonrpc.RPCResponse{Result: "0x19B225CEC6808"}
-      default:
-        responses[i] = &jsonrpc.RPCResponse{Result: "0x0"}
-      }
-    }
-  }
-  return responses, nil
+  client.On("eth_getBlockByNumber", "0x429d3b", Any).ReturnJSON(BlockJS)
+  client.On("eth_getBlockByNumber", "pending", Any).ReturnError(-123, "wrong Response")
+  client.On("eth_getBlockByNumber").Return(nil)
+  return client
 }
 
-func GetMockClient(wantErr ...bool) jsonrpc.RPCClient {
-  if len(wantErr) > 0 {
-    return mockClient{wantErr: wantErr[0]}
+// toBlock matches eth_getLogs filters ending at block.
+func toBlock(block string) func(params []json.RawMessage) bool {
+  return func(params []json.RawMessage) bool {
+    var filter struct {
+      ToBlock string `json:"toBlock"`
+    }
+    return len(params) == 1 && json.Unmarshal(params[0], &filter) == nil && filter.ToBlock == block
   }
-  return mockClient{wantErr: false}
 }
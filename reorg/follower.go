@@ -0,0 +1,278 @@
+// Package reorg follows the chain head while tracking recent block hashes,
+// so that chain reorganizations are detected and reported as rollbacks
+// followed by the blocks, and their logs, of the new canonical chain.
+package reorg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/massigerardi/alchemy-api/ethereum"
+	"github.com/massigerardi/alchemy-api/utils"
+)
+
+const (
+	DefaultDepth        = 64
+	DefaultPollInterval = 12 * time.Second
+)
+
+// ErrReorgTooDeep is returned when no block of the tracked window is still canonical.
+var ErrReorgTooDeep = errors.New("reorg deeper than the tracked window")
+
+// Client is the part of ethereum.EthClient used by the Follower.
+type Client interface {
+	GetBlockNumber() (string, error)
+	GetBlockByNumber(blockNumber string) (*ethereum.Block, error)
+	FilterLogs(request ethereum.LogRequest) (ethereum.LogsResponses, error)
+}
+
+type EventType int
+
+const (
+	// NewBlock delivers a block that extends the canonical chain, with its logs.
+	NewBlock EventType = iota
+	// Rollback reports the range of blocks orphaned by a reorganization.
+	Rollback
+)
+
+func (t EventType) String() string {
+	switch t {
+	case NewBlock:
+		return "NewBlock"
+	case Rollback:
+		return "Rollback"
+	default:
+		return fmt.Sprintf("EventType(%d)", int(t))
+	}
+}
+
+type Event struct {
+	Type EventType
+	// Block and Logs are set for NewBlock events. Logs is nil without Config.Logs.
+	Block *ethereum.Block
+	Logs  ethereum.LogsResponses
+	// From and To are the inclusive range of orphaned blocks of Rollback events,
+	// and Orphaned their hashes in block order.
+	From     uint64
+	To       uint64
+	Orphaned []string
+}
+
+type Config struct {
+	// Depth is the number of recent blocks tracked, DefaultDepth if zero.
+	// Reorganizations deeper than Depth fail with ErrReorgTooDeep.
+	Depth int
+	// PollInterval is the delay between polls in Run, DefaultPollInterval if zero.
+	PollInterval time.Duration
+	// Start is the first block delivered. If nil the follower starts at the current head.
+	Start *uint64
+	// Logs, if set, is used to fetch the logs of every delivered block. Its
+	// block range is replaced by the hash of the block.
+	Logs *ethereum.LogRequest
+}
+
+type trackedBlock struct {
+	number     uint64
+	hash       string
+	parentHash string
+	// anchor marks the parent of an orphaned window, which was never delivered.
+	anchor bool
+}
+
+// Follower tracks the canonical chain. It is not safe for concurrent use.
+type Follower struct {
+	client Client
+	config Config
+	window []trackedBlock
+}
+
+func New(client Client, config Config) *Follower {
+	if config.Depth <= 0 {
+		config.Depth = DefaultDepth
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = DefaultPollInterval
+	}
+	return &Follower{client: client, config: config}
+}
+
+// Head returns the number and hash of the latest block delivered, or false
+// before the first poll and after a rollback of the whole window until a new
+// block is delivered.
+func (f *Follower) Head() (uint64, string, bool) {
+	if len(f.window) == 0 {
+		return 0, "", false
+	}
+	head := f.window[len(f.window)-1]
+	if head.anchor {
+		return 0, "", false
+	}
+	return head.number, head.hash, true
+}
+
+// Run polls the chain until ctx is done, sending every event to events.
+func (f *Follower) Run(ctx context.Context, events chan<- Event) error {
+	ticker := time.NewTicker(f.config.PollInterval)
+	defer ticker.Stop()
+	for {
+		// Poll returns the events produced before an error, which the
+		// window already moved past: they are delivered before failing.
+		polled, err := f.Poll()
+		for _, event := range polled {
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		if err != nil {
+			return err
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Poll catches up with the current head and returns the resulting events in order.
+func (f *Follower) Poll() ([]Event, error) {
+	headNumber, err := f.client.GetBlockNumber()
+	if err != nil {
+		return nil, err
+	}
+	head, err := utils.DecodeQuantity(headNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	if len(f.window) == 0 {
+		start := head
+		if f.config.Start != nil {
+			start = *f.config.Start
+		}
+		if start > head {
+			return nil, nil
+		}
+		block, err := f.fetch(start)
+		if err != nil {
+			return nil, err
+		}
+		event, err := f.deliver(start, block)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	} else {
+		// A reorg can replace the tip without the head advancing.
+		tip := f.window[len(f.window)-1]
+		block, err := f.fetch(tip.number)
+		if err != nil {
+			return nil, err
+		}
+		if block.Hash != tip.hash {
+			rollback, err := f.rollback()
+			if err != nil {
+				return nil, err
+			}
+			events = append(events, rollback)
+		}
+	}
+
+	for {
+		tip := f.window[len(f.window)-1]
+		next := tip.number + 1
+		if next > head {
+			return events, nil
+		}
+		block, err := f.fetch(next)
+		if err != nil {
+			return events, err
+		}
+		if block.ParentHash != tip.hash {
+			rollback, err := f.rollback()
+			if err != nil {
+				return events, err
+			}
+			events = append(events, rollback)
+			continue
+		}
+		event, err := f.deliver(next, block)
+		if err != nil {
+			return events, err
+		}
+		events = append(events, event)
+	}
+}
+
+// rollback walks the window back to the most recent block that is still
+// canonical and drops everything after it. When the whole window was
+// orphaned, the parent of its first block becomes the tip if it is still
+// the parent of the canonical block at that height, e.g. when the only
+// block tracked after the first poll is replaced.
+func (f *Follower) rollback() (Event, error) {
+	tip := f.window[len(f.window)-1]
+	for i := len(f.window) - 2; i >= 0; i-- {
+		canonical, err := f.fetch(f.window[i].number)
+		if err != nil {
+			return Event{}, err
+		}
+		if canonical.Hash == f.window[i].hash {
+			return f.orphan(i+1, tip), nil
+		}
+	}
+	first := f.window[0]
+	canonical, err := f.fetch(first.number)
+	if err != nil {
+		return Event{}, err
+	}
+	if first.number == 0 || canonical.ParentHash != first.parentHash {
+		return Event{}, fmt.Errorf("%w: no common ancestor within %v blocks of %v", ErrReorgTooDeep, len(f.window), tip.number)
+	}
+	event := f.orphan(0, tip)
+	f.window = []trackedBlock{{number: first.number - 1, hash: first.parentHash, anchor: true}}
+	return event, nil
+}
+
+// orphan drops the blocks of the window from index i on.
+func (f *Follower) orphan(i int, tip trackedBlock) Event {
+	orphaned := make([]string, 0, len(f.window)-i)
+	for _, block := range f.window[i:] {
+		orphaned = append(orphaned, block.hash)
+	}
+	from := f.window[i].number
+	f.window = f.window[:i]
+	return Event{Type: Rollback, From: from, To: tip.number, Orphaned: orphaned}
+}
+
+func (f *Follower) deliver(number uint64, block *ethereum.Block) (Event, error) {
+	event := Event{Type: NewBlock, Block: block}
+	if f.config.Logs != nil {
+		hash, err := utils.ParseHash(block.Hash)
+		if err != nil {
+			return Event{}, err
+		}
+		request := *f.config.Logs
+		request.FromBlock = ""
+		request.ToBlock = ""
+		request.BlockHash = &hash
+		logs, err := f.client.FilterLogs(request)
+		if err != nil {
+			return Event{}, err
+		}
+		event.Logs = logs
+	}
+	f.window = append(f.window, trackedBlock{number: number, hash: block.Hash, parentHash: block.ParentHash})
+	if len(f.window) > f.config.Depth {
+		f.window = f.window[len(f.window)-f.config.Depth:]
+	}
+	return event, nil
+}
+
+func (f *Follower) fetch(number uint64) (*ethereum.Block, error) {
+	return f.client.GetBlockByNumber(utils.EncodeQuantity(number))
+}
@@ -0,0 +1,310 @@
+package reorg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/massigerardi/alchemy-api/ethereum"
+	"github.com/massigerardi/alchemy-api/utils"
+)
+
+// chainMock is a canonical chain whose blocks can be replaced to simulate reorganizations.
+type chainMock struct {
+	blocks []*ethereum.Block
+	logs   []ethereum.LogRequest
+}
+
+func blockHash(number uint64, fork string) string {
+	return utils.Keccak256Hash([]byte(fmt.Sprintf("%v-%v", fork, number))).Hex()
+}
+
+func newChainMock(length uint64) *chainMock {
+	c := &chainMock{}
+	c.extend(length, "a")
+	return c
+}
+
+// extend appends blocks until the chain has length blocks.
+func (c *chainMock) extend(length uint64, fork string) {
+	for n := uint64(len(c.blocks)); n < length; n++ {
+		parent := ""
+		if n > 0 {
+			parent = c.blocks[n-1].Hash
+		}
+		c.blocks = append(c.blocks, &ethereum.Block{Number: utils.EncodeQuantity(n), Hash: blockHash(n, fork), ParentHash: parent})
+	}
+}
+
+// fork replaces every block from number on with blocks of a new fork, up to length blocks.
+func (c *chainMock) fork(number uint64, length uint64, fork string) {
+	c.blocks = c.blocks[:number]
+	c.extend(length, fork)
+}
+
+func (c *chainMock) GetBlockNumber() (string, error) {
+	return utils.EncodeQuantity(uint64(len(c.blocks) - 1)), nil
+}
+
+func (c *chainMock) GetBlockByNumber(blockNumber string) (*ethereum.Block, error) {
+	n, err := utils.DecodeQuantity(blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	if n >= uint64(len(c.blocks)) {
+		return nil, fmt.Errorf("block %v not found", blockNumber)
+	}
+	return c.blocks[n], nil
+}
+
+func (c *chainMock) FilterLogs(request ethereum.LogRequest) (ethereum.LogsResponses, error) {
+	c.logs = append(c.logs, request)
+	return ethereum.LogsResponses{{BlockHash: request.BlockHash.Hex()}}, nil
+}
+
+type eventSummary struct {
+	Type     EventType
+	Number   string
+	From, To uint64
+}
+
+func summarize(events []Event) []eventSummary {
+	summaries := make([]eventSummary, len(events))
+	for i, event := range events {
+		summaries[i] = eventSummary{Type: event.Type, From: event.From, To: event.To}
+		if event.Block != nil {
+			summaries[i].Number = event.Block.Number
+		}
+	}
+	return summaries
+}
+
+func TestFollower_Poll(t *testing.T) {
+	chain := newChainMock(10)
+	start := uint64(7)
+	f := New(chain, Config{Depth: 8, Start: &start})
+
+	events, err := f.Poll()
+	if err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+	want := []eventSummary{{Type: NewBlock, Number: "0x7"}, {Type: NewBlock, Number: "0x8"}, {Type: NewBlock, Number: "0x9"}}
+	if got := summarize(events); !reflect.DeepEqual(got, want) {
+		t.Errorf("Poll() got = %v, want %v", got, want)
+	}
+
+	events, err = f.Poll()
+	if err != nil || len(events) != 0 {
+		t.Errorf("Poll() without new blocks = %v, %v", events, err)
+	}
+
+	// blocks 8 and 9 are replaced and the new fork is one block longer
+	chain.fork(8, 11, "b")
+	events, err = f.Poll()
+	if err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+	want = []eventSummary{
+		{Type: Rollback, From: 8, To: 9},
+		{Type: NewBlock, Number: "0x8"},
+		{Type: NewBlock, Number: "0x9"},
+		{Type: NewBlock, Number: "0xa"},
+	}
+	if got := summarize(events); !reflect.DeepEqual(got, want) {
+		t.Errorf("Poll() got = %v, want %v", got, want)
+	}
+	if orphaned := events[0].Orphaned; !reflect.DeepEqual(orphaned, []string{blockHash(8, "a"), blockHash(9, "a")}) {
+		t.Errorf("Rollback orphaned = %v", orphaned)
+	}
+	if events[1].Block.Hash != blockHash(8, "b") {
+		t.Errorf("NewBlock after rollback = %v, want the new fork", events[1].Block.Hash)
+	}
+	if number, hash, _ := f.Head(); number != 10 || hash != blockHash(10, "b") {
+		t.Errorf("Head() = %v, %v", number, hash)
+	}
+}
+
+func TestFollower_TipReplaced(t *testing.T) {
+	chain := newChainMock(5)
+	f := New(chain, Config{})
+	if _, err := f.Poll(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Poll(); err != nil {
+		t.Fatal(err)
+	}
+	chain.extend(6, "a")
+	if _, err := f.Poll(); err != nil {
+		t.Fatal(err)
+	}
+
+	// same height, different tip
+	chain.fork(5, 6, "b")
+	events, err := f.Poll()
+	if err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+	want := []eventSummary{{Type: Rollback, From: 5, To: 5}, {Type: NewBlock, Number: "0x5"}}
+	if got := summarize(events); !reflect.DeepEqual(got, want) {
+		t.Errorf("Poll() got = %v, want %v", got, want)
+	}
+}
+
+func TestFollower_SingleBlockReplaced(t *testing.T) {
+	chain := newChainMock(5)
+	f := New(chain, Config{})
+	if _, err := f.Poll(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the only block tracked is replaced, then the new fork grows
+	chain.fork(4, 6, "b")
+	events, err := f.Poll()
+	if err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+	want := []eventSummary{{Type: Rollback, From: 4, To: 4}, {Type: NewBlock, Number: "0x4"}, {Type: NewBlock, Number: "0x5"}}
+	if got := summarize(events); !reflect.DeepEqual(got, want) {
+		t.Errorf("Poll() got = %v, want %v", got, want)
+	}
+	if orphaned := events[0].Orphaned; !reflect.DeepEqual(orphaned, []string{blockHash(4, "a")}) {
+		t.Errorf("Rollback orphaned = %v", orphaned)
+	}
+	if number, hash, _ := f.Head(); number != 5 || hash != blockHash(5, "b") {
+		t.Errorf("Head() = %v, %v", number, hash)
+	}
+}
+
+// flakyChain fails the failAt-th fetch of a block of the chain.
+type flakyChain struct {
+	*chainMock
+	fail    string
+	failAt  int
+	fetched int
+}
+
+func (c *flakyChain) GetBlockByNumber(blockNumber string) (*ethereum.Block, error) {
+	if blockNumber == c.fail {
+		c.fetched++
+		if c.fetched == c.failAt {
+			return nil, errors.New("connection reset")
+		}
+	}
+	return c.chainMock.GetBlockByNumber(blockNumber)
+}
+
+func TestFollower_HeadAfterRollback(t *testing.T) {
+	chain := &flakyChain{chainMock: newChainMock(5), fail: "0x4", failAt: 3}
+	f := New(chain, Config{})
+	if _, err := f.Poll(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the only block tracked is replaced, and its replacement fails to load
+	// after the tip check and the rollback fetched it
+	chain.fork(4, 5, "b")
+	chain.fetched = 0
+	events, err := f.Poll()
+	if err == nil {
+		t.Fatalf("Poll() error = nil, want connection reset")
+	}
+	if got := summarize(events); !reflect.DeepEqual(got, []eventSummary{{Type: Rollback, From: 4, To: 4}}) {
+		t.Errorf("Poll() got = %v, want the rollback", got)
+	}
+	if number, hash, ok := f.Head(); ok {
+		t.Errorf("Head() = %v, %v after the rollback, want no head", number, hash)
+	}
+
+	if _, err := f.Poll(); err != nil {
+		t.Fatal(err)
+	}
+	if number, hash, ok := f.Head(); !ok || number != 4 || hash != blockHash(4, "b") {
+		t.Errorf("Head() = %v, %v, %v", number, hash, ok)
+	}
+}
+
+func TestFollower_TooDeep(t *testing.T) {
+	chain := newChainMock(10)
+	start := uint64(5)
+	f := New(chain, Config{Depth: 3, Start: &start})
+	if _, err := f.Poll(); err != nil {
+		t.Fatal(err)
+	}
+	chain.fork(3, 11, "b")
+	if _, err := f.Poll(); !errors.Is(err, ErrReorgTooDeep) {
+		t.Errorf("Poll() error = %v, want %v", err, ErrReorgTooDeep)
+	}
+}
+
+func TestFollower_Logs(t *testing.T) {
+	chain := newChainMock(3)
+	start := uint64(1)
+	request := ethereum.NewLogRequest(nil, ethereum.Earliest, ethereum.Latest, utils.EventTopic("Transfer(address,address,uint256)"))
+	f := New(chain, Config{Start: &start, Logs: &request})
+	events, err := f.Poll()
+	if err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+	for i, event := range events {
+		if len(event.Logs) != 1 || event.Logs[0].BlockHash != event.Block.Hash {
+			t.Errorf("event %v logs = %v, want logs of block %v", i, event.Logs, event.Block.Hash)
+		}
+		if r := chain.logs[i]; r.FromBlock != "" || r.ToBlock != "" || len(r.Topics) != 1 {
+			t.Errorf("log request %v = %+v", i, r)
+		}
+	}
+}
+
+func TestFollower_Run(t *testing.T) {
+	chain := newChainMock(3)
+	f := New(chain, Config{PollInterval: time.Millisecond})
+	ctx, cancel := context.WithCancel(context.Background())
+	events := make(chan Event)
+	done := make(chan error)
+	go func() {
+		done <- f.Run(ctx, events)
+	}()
+	event := <-events
+	if event.Type != NewBlock || event.Block.Number != "0x2" {
+		t.Errorf("Run() first event = %v", event)
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() error = %v", err)
+	}
+}
+
+// failingChain fails to fetch one block of the chain.
+type failingChain struct {
+	*chainMock
+	fail string
+}
+
+func (c failingChain) GetBlockByNumber(blockNumber string) (*ethereum.Block, error) {
+	if blockNumber == c.fail {
+		return nil, errors.New("connection reset")
+	}
+	return c.chainMock.GetBlockByNumber(blockNumber)
+}
+
+func TestFollower_RunDeliversBeforeError(t *testing.T) {
+	start := uint64(0)
+	f := New(failingChain{chainMock: newChainMock(3), fail: "0x2"}, Config{PollInterval: time.Millisecond, Start: &start})
+	events := make(chan Event, 3)
+	err := f.Run(context.Background(), events)
+	if err == nil || err.Error() != "connection reset" {
+		t.Errorf("Run() error = %v, want connection reset", err)
+	}
+	close(events)
+	var got []Event
+	for event := range events {
+		got = append(got, event)
+	}
+	want := []eventSummary{{Type: NewBlock, Number: "0x0"}, {Type: NewBlock, Number: "0x1"}}
+	if summary := summarize(got); !reflect.DeepEqual(summary, want) {
+		t.Errorf("Run() delivered %v, want %v", summary, want)
+	}
+}
@@ -0,0 +1,226 @@
+// Package scanner walks a block range in fixed size windows, fetching
+// windows concurrently while handling them strictly in order and saving a
+// checkpoint after each one, so an interrupted scan resumes where it stopped.
+package scanner
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/massigerardi/alchemy-api/ethereum"
+	"github.com/massigerardi/alchemy-api/internal/pipeline"
+	"github.com/massigerardi/alchemy-api/utils"
+)
+
+const (
+	DefaultWindowSize  uint64 = 1000
+	DefaultConcurrency        = 4
+)
+
+// Window is an inclusive range of block numbers.
+type Window struct {
+	From uint64
+	To   uint64
+}
+
+// FromBlock returns the first block of the window as a hex quantity.
+func (w Window) FromBlock() string {
+	return utils.EncodeQuantity(w.From)
+}
+
+// ToBlock returns the last block of the window as a hex quantity.
+func (w Window) ToBlock() string {
+	return utils.EncodeQuantity(w.To)
+}
+
+// Fetcher loads the data of a window. Fetchers of different windows run concurrently.
+type Fetcher[T any] func(ctx context.Context, window Window) (T, error)
+
+// Handler processes the data of a window. Handlers are called one at a time, in block order.
+type Handler[T any] func(ctx context.Context, window Window, data T) error
+
+type Config struct {
+	// Key identifies the scan in the Store.
+	Key string
+	// From and To are the inclusive bounds of the range to scan.
+	From uint64
+	To   uint64
+	// WindowSize is the number of blocks per window, DefaultWindowSize if zero.
+	WindowSize uint64
+	// Concurrency bounds the number of windows fetched at once, DefaultConcurrency if zero.
+	Concurrency int
+	// Store holds checkpoints, a new MemoryStore if nil.
+	Store Store
+}
+
+type Scanner[T any] struct {
+	config Config
+	fetch  Fetcher[T]
+	handle Handler[T]
+}
+
+func New[T any](config Config, fetch Fetcher[T], handle Handler[T]) *Scanner[T] {
+	if config.WindowSize == 0 {
+		config.WindowSize = DefaultWindowSize
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = DefaultConcurrency
+	}
+	if config.Store == nil {
+		config.Store = NewMemoryStore()
+	}
+	return &Scanner[T]{config: config, fetch: fetch, handle: handle}
+}
+
+// Run scans from the block after the saved checkpoint, or from Config.From
+// if there is none, up to Config.To. It stops at the first fetch, handler or
+// store error; the checkpoint then points at the last window fully handled.
+func (s *Scanner[T]) Run(ctx context.Context) error {
+	start, err := s.start()
+	if err != nil {
+		return err
+	}
+	if start > s.config.To {
+		return nil
+	}
+
+	fetch := func(ctx context.Context, window Window) (T, error) {
+		data, err := s.fetch(ctx, window)
+		if err != nil {
+			return data, fmt.Errorf("fetching blocks %v-%v: %w", window.From, window.To, err)
+		}
+		return data, nil
+	}
+	return pipeline.Ordered(ctx, s.config.Concurrency, s.windows(start), fetch, func(ctx context.Context, window Window, data T) error {
+		if err := s.handle(ctx, window, data); err != nil {
+			return fmt.Errorf("handling blocks %v-%v: %w", window.From, window.To, err)
+		}
+		if err := s.config.Store.Save(s.config.Key, window.To); err != nil {
+			return fmt.Errorf("saving checkpoint %v: %w", window.To, err)
+		}
+		return nil
+	})
+}
+
+// Checkpoint returns the last fully processed block, or false if the scan has not handled any window yet.
+func (s *Scanner[T]) Checkpoint() (uint64, bool, error) {
+	return s.config.Store.Load(s.config.Key)
+}
+
+func (s *Scanner[T]) start() (uint64, error) {
+	checkpoint, ok, err := s.config.Store.Load(s.config.Key)
+	if err != nil {
+		return 0, fmt.Errorf("loading checkpoint: %w", err)
+	}
+	if !ok || checkpoint < s.config.From {
+		return s.config.From, nil
+	}
+	if checkpoint >= s.config.To {
+		return s.config.To + 1, nil
+	}
+	return checkpoint + 1, nil
+}
+
+// windows returns the successive windows from start up to Config.To.
+func (s *Scanner[T]) windows(start uint64) func(ctx context.Context) (Window, bool) {
+	from, done := start, false
+	return func(context.Context) (Window, bool) {
+		if done {
+			return Window{}, false
+		}
+		window := Window{From: from, To: s.config.To}
+		if s.config.To-from >= s.config.WindowSize {
+			window.To = from + s.config.WindowSize - 1
+		}
+		done = window.To == s.config.To
+		from = window.To + 1
+		return window, true
+	}
+}
+
+// LogFilterer is the part of ethereum.EthClient used by LogsFetcher.
+type LogFilterer interface {
+	FilterLogs(request ethereum.LogRequest) (ethereum.LogsResponses, error)
+}
+
+// LogsFetcher returns a Fetcher loading the logs matching request within each window.
+// The block range of request is replaced by the window.
+func LogsFetcher(client LogFilterer, request ethereum.LogRequest) Fetcher[ethereum.LogsResponses] {
+	return func(_ context.Context, window Window) (ethereum.LogsResponses, error) {
+		windowRequest := request
+		windowRequest.BlockHash = nil
+		windowRequest.FromBlock = window.FromBlock()
+		windowRequest.ToBlock = window.ToBlock()
+		return client.FilterLogs(windowRequest)
+	}
+}
+
+// BatchClient is the part of ethereum.EthClient used by BlocksFetcher and ReceiptsFetcher.
+type BatchClient interface {
+	NewBatch() *ethereum.Batch
+}
+
+// BlocksFetcher returns a Fetcher loading the blocks of each window, without
+// their transactions, in batches of at most ethereum.MaxBatchCalls.
+func BlocksFetcher(client BatchClient) Fetcher[[]*ethereum.Block] {
+	return func(ctx context.Context, window Window) ([]*ethereum.Block, error) {
+		return getBlocks(ctx, client, window)
+	}
+}
+
+// ReceiptsFetcher returns a Fetcher loading the receipts of the transactions
+// of each window, in block and transaction order. The blocks are read first
+// to list the transactions, then the receipts in batches of at most
+// ethereum.MaxBatchCalls.
+func ReceiptsFetcher(client BatchClient) Fetcher[[]*ethereum.Receipt] {
+	return func(ctx context.Context, window Window) ([]*ethereum.Receipt, error) {
+		blocks, err := getBlocks(ctx, client, window)
+		if err != nil {
+			return nil, err
+		}
+		var hashes []utils.Hash
+		for _, block := range blocks {
+			for _, transaction := range block.Transactions {
+				hash, err := utils.ParseHash(transaction)
+				if err != nil {
+					return nil, fmt.Errorf("block %v: %w", block.Number, err)
+				}
+				hashes = append(hashes, hash)
+			}
+		}
+		return batched(ctx, client, hashes, (*ethereum.Batch).GetTransactionReceipt)
+	}
+}
+
+func getBlocks(ctx context.Context, client BatchClient, window Window) ([]*ethereum.Block, error) {
+	numbers := make([]string, 0, window.To-window.From+1)
+	for number := window.From; number <= window.To; number++ {
+		numbers = append(numbers, utils.EncodeQuantity(number))
+	}
+	return batched(ctx, client, numbers, (*ethereum.Batch).GetBlockByNumber)
+}
+
+// batched adds a call per key to batches of at most ethereum.MaxBatchCalls
+// and returns their results in the order of keys.
+func batched[K, T any](ctx context.Context, client BatchClient, keys []K, add func(b *ethereum.Batch, key K) *ethereum.Future[T]) ([]T, error) {
+	results := make([]T, 0, len(keys))
+	for start := 0; start < len(keys); start += ethereum.MaxBatchCalls {
+		end := min(start+ethereum.MaxBatchCalls, len(keys))
+		b := client.NewBatch()
+		futures := make([]*ethereum.Future[T], 0, end-start)
+		for _, key := range keys[start:end] {
+			futures = append(futures, add(b, key))
+		}
+		if err := b.ExecuteContext(ctx); err != nil {
+			return nil, err
+		}
+		for _, future := range futures {
+			result, err := future.Get()
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,241 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/massigerardi/alchemy-api/ethereum"
+	"github.com/massigerardi/alchemy-api/mocks"
+	"github.com/ybbus/jsonrpc/v3"
+)
+
+func TestScanner_Run(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		want   []Window
+	}{
+		{
+			name:   "Windows In Order",
+			config: Config{Key: "test", From: 10, To: 34, WindowSize: 10, Concurrency: 3},
+			want:   []Window{{10, 19}, {20, 29}, {30, 34}},
+		},
+		{
+			name:   "Single Block",
+			config: Config{Key: "test", From: 7, To: 7, WindowSize: 10},
+			want:   []Window{{7, 7}},
+		},
+		{
+			name:   "Sequential",
+			config: Config{Key: "test", From: 0, To: 5, WindowSize: 2, Concurrency: 1},
+			want:   []Window{{0, 1}, {2, 3}, {4, 5}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var running, maxRunning atomic.Int32
+			fetch := func(_ context.Context, w Window) (Window, error) {
+				n := running.Add(1)
+				defer running.Add(-1)
+				for {
+					m := maxRunning.Load()
+					if n <= m || maxRunning.CompareAndSwap(m, n) {
+						break
+					}
+				}
+				time.Sleep(time.Duration(rand.Intn(5)) * time.Millisecond)
+				return w, nil
+			}
+			var got []Window
+			handle := func(_ context.Context, w Window, data Window) error {
+				if w != data {
+					t.Errorf("handler got data %v for window %v", data, w)
+				}
+				got = append(got, w)
+				return nil
+			}
+			s := New[Window](tt.config, fetch, handle)
+			if err := s.Run(context.Background()); err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Run() handled %v, want %v", got, tt.want)
+			}
+			if m := int(maxRunning.Load()); m > s.config.Concurrency {
+				t.Errorf("Run() fetched %v windows at once, limit %v", m, s.config.Concurrency)
+			}
+			checkpoint, ok, _ := s.Checkpoint()
+			if !ok || checkpoint != tt.config.To {
+				t.Errorf("Checkpoint() = %v, %v, want %v", checkpoint, ok, tt.config.To)
+			}
+		})
+	}
+}
+
+func TestScanner_Resume(t *testing.T) {
+	store := NewMemoryStore()
+	config := Config{Key: "resume", From: 0, To: 49, WindowSize: 10, Concurrency: 2, Store: store}
+	fetch := func(_ context.Context, w Window) (Window, error) {
+		return w, nil
+	}
+
+	failure := errors.New("handler failure")
+	var first []Window
+	err := New[Window](config, fetch, func(_ context.Context, w Window, _ Window) error {
+		if w.From == 30 {
+			return failure
+		}
+		first = append(first, w)
+		return nil
+	}).Run(context.Background())
+	if !errors.Is(err, failure) {
+		t.Fatalf("Run() error = %v, want %v", err, failure)
+	}
+	if want := []Window{{0, 9}, {10, 19}, {20, 29}}; !reflect.DeepEqual(first, want) {
+		t.Errorf("first scan handled %v, want %v", first, want)
+	}
+	if checkpoint, _, _ := store.Load("resume"); checkpoint != 29 {
+		t.Errorf("checkpoint after failure = %v, want 29", checkpoint)
+	}
+
+	var second []Window
+	err = New[Window](config, fetch, func(_ context.Context, w Window, _ Window) error {
+		second = append(second, w)
+		return nil
+	}).Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if want := []Window{{30, 39}, {40, 49}}; !reflect.DeepEqual(second, want) {
+		t.Errorf("resumed scan handled %v, want %v", second, want)
+	}
+
+	err = New[Window](config, fetch, func(_ context.Context, w Window, _ Window) error {
+		t.Errorf("completed scan handled %v", w)
+		return nil
+	}).Run(context.Background())
+	if err != nil {
+		t.Errorf("Run() on completed scan error = %v", err)
+	}
+}
+
+func TestScanner_FetchError(t *testing.T) {
+	failure := errors.New("fetch failure")
+	var mu sync.Mutex
+	var handled []Window
+	s := New[Window](Config{Key: "fetch", From: 0, To: 99, WindowSize: 10, Concurrency: 4}, func(_ context.Context, w Window) (Window, error) {
+		if w.From == 20 {
+			return w, failure
+		}
+		return w, nil
+	}, func(_ context.Context, w Window, _ Window) error {
+		mu.Lock()
+		defer mu.Unlock()
+		handled = append(handled, w)
+		return nil
+	})
+	if err := s.Run(context.Background()); !errors.Is(err, failure) {
+		t.Fatalf("Run() error = %v, want %v", err, failure)
+	}
+	if want := []Window{{0, 9}, {10, 19}}; !reflect.DeepEqual(handled, want) {
+		t.Errorf("Run() handled %v, want %v", handled, want)
+	}
+}
+
+func TestScanner_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := New[Window](Config{Key: "cancel", From: 0, To: 1 << 40, WindowSize: 1}, func(ctx context.Context, w Window) (Window, error) {
+		return w, ctx.Err()
+	}, func(_ context.Context, w Window, _ Window) error {
+		if w.From == 5 {
+			cancel()
+		}
+		return nil
+	})
+	if err := s.Run(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+type filtererMock struct {
+	requests []ethereum.LogRequest
+}
+
+func (f *filtererMock) FilterLogs(request ethereum.LogRequest) (ethereum.LogsResponses, error) {
+	f.requests = append(f.requests, request)
+	return ethereum.LogsResponses{{BlockNumber: request.FromBlock}}, nil
+}
+
+func TestLogsFetcher(t *testing.T) {
+	client := &filtererMock{}
+	request := ethereum.NewLogRequest(nil, ethereum.Earliest, ethereum.Latest)
+	got, err := LogsFetcher(client, request)(context.Background(), Window{From: 16, To: 31})
+	if err != nil {
+		t.Fatalf("LogsFetcher() error = %v", err)
+	}
+	if len(got) != 1 || got[0].BlockNumber != "0x10" {
+		t.Errorf("LogsFetcher() got = %v", got)
+	}
+	if r := client.requests[0]; r.FromBlock != "0x10" || r.ToBlock != "0x1f" {
+		t.Errorf("LogsFetcher() requested %v-%v", r.FromBlock, r.ToBlock)
+	}
+}
+
+// batchCounter counts the batches sent to its client.
+type batchCounter struct {
+	jsonrpc.RPCClient
+	batches atomic.Int32
+}
+
+func (c *batchCounter) CallBatch(ctx context.Context, requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error) {
+	c.batches.Add(1)
+	return c.RPCClient.CallBatch(ctx, requests)
+}
+
+func TestBlocksFetcher(t *testing.T) {
+	client := &batchCounter{RPCClient: mocks.New().
+		On(ethereum.EthGetBlockByNumber).ReturnJSON(`{"number": "0x10", "transactions": []}`)}
+	got, err := BlocksFetcher(ethereum.NewFromRPCClient(client))(context.Background(), Window{From: 0, To: 149})
+	if err != nil {
+		t.Fatalf("BlocksFetcher() error = %v", err)
+	}
+	if len(got) != 150 || got[0].Number != "0x10" {
+		t.Errorf("BlocksFetcher() got %v blocks", len(got))
+	}
+	if got := client.batches.Load(); got != 2 {
+		t.Errorf("BlocksFetcher() sent %v batches, want 2", got)
+	}
+}
+
+func TestReceiptsFetcher(t *testing.T) {
+	hashes := []string{
+		"0x8243343df08b9751f5ca0c5f8c9c0460d8a9b6351066fae0acbd4d3e776de8bb",
+		"0x1d7ad4ab26a7fe4e5a2ab5e8d41e3dc07d2cbd8bd7c00b3d35f67e1b6c22a5b1",
+		"0x5e2e5d0b2b3f7d3c1fbd07b3c6f2c6c2f1f1d3b8a2e5a0e2c2d7f3b0c1a2b3c4",
+	}
+	receipt := func(hash string) string { return `{"transactionHash": "` + hash + `"}` }
+	client := mocks.New().
+		On(ethereum.EthGetBlockByNumber, "0x10", false).ReturnJSON(`{"number": "0x10", "transactions": ["`+hashes[0]+`", "`+hashes[1]+`"]}`).
+		On(ethereum.EthGetBlockByNumber, "0x11", false).ReturnJSON(`{"number": "0x11", "transactions": ["`+hashes[2]+`"]}`).
+		On(ethereum.EthGetReceipt, hashes[0]).ReturnJSON(receipt(hashes[0])).
+		On(ethereum.EthGetReceipt, hashes[1]).ReturnJSON(receipt(hashes[1])).
+		On(ethereum.EthGetReceipt, hashes[2]).ReturnJSON(receipt(hashes[2]))
+	got, err := ReceiptsFetcher(ethereum.NewFromRPCClient(client))(context.Background(), Window{From: 16, To: 17})
+	if err != nil {
+		t.Fatalf("ReceiptsFetcher() error = %v", err)
+	}
+	if len(got) != len(hashes) {
+		t.Fatalf("ReceiptsFetcher() got %v receipts, want %v", len(got), len(hashes))
+	}
+	for i, r := range got {
+		if r.TransactionHash != hashes[i] {
+			t.Errorf("ReceiptsFetcher() got[%v] = %v, want %v", i, r.TransactionHash, hashes[i])
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package scanner
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Store persists the last fully processed block of a scan, keyed by scan name.
+type Store interface {
+	// Load returns the checkpoint saved for key, or false if there is none.
+	Load(key string) (uint64, bool, error)
+	// Save records block as the last fully processed block for key.
+	Save(key string, block uint64) error
+}
+
+// MemoryStore keeps checkpoints in memory. It is safe for concurrent use.
+type MemoryStore struct {
+	mu          sync.Mutex
+	checkpoints map[string]uint64
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{checkpoints: make(map[string]uint64)}
+}
+
+func (s *MemoryStore) Load(key string) (uint64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	block, ok := s.checkpoints[key]
+	return block, ok, nil
+}
+
+func (s *MemoryStore) Save(key string, block uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checkpoints[key] = block
+	return nil
+}
+
+// FileStore keeps checkpoints in a JSON file. Every Save rewrites the file
+// atomically, so a crash never leaves a truncated checkpoint behind.
+type FileStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+func (s *FileStore) Load(key string) (uint64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	checkpoints, err := s.read()
+	if err != nil {
+		return 0, false, err
+	}
+	block, ok := checkpoints[key]
+	return block, ok, nil
+}
+
+func (s *FileStore) Save(key string, block uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	checkpoints, err := s.read()
+	if err != nil {
+		return err
+	}
+	checkpoints[key] = block
+	js, err := json.MarshalIndent(checkpoints, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(js); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+func (s *FileStore) read() (map[string]uint64, error) {
+	checkpoints := make(map[string]uint64)
+	js, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return checkpoints, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(js, &checkpoints); err != nil {
+		return nil, err
+	}
+	return checkpoints, nil
+}
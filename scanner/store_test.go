@@ -0,0 +1,54 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoints.json")
+	store := NewFileStore(path)
+
+	if _, ok, err := store.Load("transfers"); ok || err != nil {
+		t.Fatalf("Load() on missing file = %v, %v", ok, err)
+	}
+	if err := store.Save("transfers", 100); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := store.Save("approvals", 7); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	reopened := NewFileStore(path)
+	tests := []struct {
+		key  string
+		want uint64
+	}{
+		{key: "transfers", want: 100},
+		{key: "approvals", want: 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			got, ok, err := reopened.Load(tt.key)
+			if err != nil || !ok || got != tt.want {
+				t.Errorf("Load() = %v, %v, %v, want %v", got, ok, err, tt.want)
+			}
+		})
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("Save() left temporary files behind: %v", entries)
+	}
+}
+
+func TestFileStore_Corrupted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoints.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := NewFileStore(path).Load("transfers"); err == nil {
+		t.Errorf("Load() accepted a corrupted file")
+	}
+}
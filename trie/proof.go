@@ -0,0 +1,177 @@
+// Package trie verifies Merkle-Patricia trie proofs, such as the account and
+// storage proofs returned by eth_getProof, against a trusted root hash so
+// that values read from a node do not need to be trusted.
+package trie
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/massigerardi/alchemy-api/utils"
+)
+
+var (
+	// EmptyRoot is the root hash of an empty trie, e.g. the storage root of
+	// an account without storage.
+	EmptyRoot = utils.MustParseHash("0x56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421")
+	// EmptyCodeHash is the code hash of an account without code.
+	EmptyCodeHash = utils.Keccak256Hash(nil)
+)
+
+// ErrInvalidProof is returned when a proof proves neither a value nor its
+// absence for a key.
+var ErrInvalidProof = errors.New("invalid proof")
+
+// Account is the state of an account as stored in the state trie.
+type Account struct {
+	Nonce       uint64
+	Balance     *big.Int
+	StorageRoot utils.Hash
+	CodeHash    utils.Hash
+}
+
+// VerifyProof returns the value stored for key in the trie with the given
+// root, proof being the encoded nodes on the path from the root to the key.
+// A nil value with a nil error proves that the trie holds no value for key.
+func VerifyProof(root utils.Hash, key []byte, proof [][]byte) ([]byte, error) {
+	path := keyNibbles(key)
+	if root == EmptyRoot && len(proof) == 0 {
+		return nil, nil
+	}
+	hash, embedded := root[:], []byte(nil)
+	for i := 0; ; {
+		var encoded []byte
+		if embedded != nil {
+			encoded = embedded
+		} else {
+			if i == len(proof) {
+				return nil, fmt.Errorf("%w: missing node %v", ErrInvalidProof, i)
+			}
+			if !bytes.Equal(utils.Keccak256(proof[i]), hash) {
+				return nil, fmt.Errorf("%w: node %v does not match its hash", ErrInvalidProof, i)
+			}
+			encoded = proof[i]
+			i++
+		}
+		node, err := decodeList(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidProof, err)
+		}
+		var child item
+		switch len(node) {
+		case 17:
+			if len(path) == 0 {
+				return nonEmpty(node[16])
+			}
+			child, path = node[path[0]], path[1:]
+		case 2:
+			if node[0].list {
+				return nil, fmt.Errorf("%w: invalid node path", ErrInvalidProof)
+			}
+			prefix, leaf, err := compactNibbles(node[0].data)
+			if err != nil {
+				return nil, err
+			}
+			if leaf {
+				if !bytes.Equal(prefix, path) {
+					return nil, nil
+				}
+				return nonEmpty(node[1])
+			}
+			if !bytes.HasPrefix(path, prefix) {
+				return nil, nil
+			}
+			child, path = node[1], path[len(prefix):]
+		default:
+			return nil, fmt.Errorf("%w: node of %v items", ErrInvalidProof, len(node))
+		}
+		switch {
+		case child.list:
+			hash, embedded = nil, child.raw
+		case len(child.data) == 0:
+			return nil, nil
+		case len(child.data) == utils.HashLength:
+			hash, embedded = child.data, nil
+		default:
+			return nil, fmt.Errorf("%w: invalid child reference", ErrInvalidProof)
+		}
+	}
+}
+
+func nonEmpty(value item) ([]byte, error) {
+	if value.list {
+		return nil, fmt.Errorf("%w: invalid value", ErrInvalidProof)
+	}
+	if len(value.data) == 0 {
+		return nil, nil
+	}
+	return value.data, nil
+}
+
+// VerifyAccount returns the state of address in the state trie with the
+// given root. Accounts proven absent are returned empty.
+func VerifyAccount(stateRoot utils.Hash, address utils.Address, proof [][]byte) (*Account, error) {
+	value, err := VerifyProof(stateRoot, utils.Keccak256(address[:]), proof)
+	if err != nil || value == nil {
+		return &Account{Balance: new(big.Int), StorageRoot: EmptyRoot, CodeHash: EmptyCodeHash}, err
+	}
+	fields, err := decodeList(value)
+	if err != nil || len(fields) != 4 {
+		return nil, fmt.Errorf("%w: invalid account", ErrInvalidProof)
+	}
+	for _, field := range fields {
+		if field.list {
+			return nil, fmt.Errorf("%w: invalid account", ErrInvalidProof)
+		}
+	}
+	if len(fields[0].data) > 8 || len(fields[2].data) != utils.HashLength || len(fields[3].data) != utils.HashLength {
+		return nil, fmt.Errorf("%w: invalid account", ErrInvalidProof)
+	}
+	account := &Account{
+		Nonce:       new(big.Int).SetBytes(fields[0].data).Uint64(),
+		Balance:     new(big.Int).SetBytes(fields[1].data),
+		StorageRoot: utils.BytesToHash(fields[2].data),
+		CodeHash:    utils.BytesToHash(fields[3].data),
+	}
+	return account, nil
+}
+
+// VerifyStorage returns the word stored at slot in the storage trie with the
+// given root, the zero word for slots proven empty.
+func VerifyStorage(storageRoot utils.Hash, slot utils.Hash, proof [][]byte) (utils.Hash, error) {
+	value, err := VerifyProof(storageRoot, utils.Keccak256(slot[:]), proof)
+	if err != nil || value == nil {
+		return utils.Hash{}, err
+	}
+	word, err := decodeString(value)
+	if err != nil || len(word) > utils.HashLength {
+		return utils.Hash{}, fmt.Errorf("%w: invalid storage value", ErrInvalidProof)
+	}
+	return utils.BytesToHash(word), nil
+}
+
+func keyNibbles(key []byte) []byte {
+	nibbles := make([]byte, 2*len(key))
+	for i, b := range key {
+		nibbles[2*i], nibbles[2*i+1] = b>>4, b&0x0f
+	}
+	return nibbles
+}
+
+// compactNibbles decodes the hex prefix encoded path of a leaf or extension node.
+func compactNibbles(compact []byte) ([]byte, bool, error) {
+	if len(compact) == 0 {
+		return nil, false, fmt.Errorf("%w: empty node path", ErrInvalidProof)
+	}
+	flag := compact[0] >> 4
+	if flag > 3 || (flag&1 == 0 && compact[0]&0x0f != 0) {
+		return nil, false, fmt.Errorf("%w: invalid node path", ErrInvalidProof)
+	}
+	nibbles := keyNibbles(compact)[2:]
+	if flag&1 == 1 {
+		nibbles = append([]byte{compact[0] & 0x0f}, nibbles...)
+	}
+	return nibbles, flag >= 2, nil
+}
@@ -0,0 +1,335 @@
+package trie
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/massigerardi/alchemy-api/utils"
+)
+
+func encodeString(b []byte) []byte {
+	if len(b) == 1 && b[0] < 0x80 {
+		return b
+	}
+	return append(encodeLength(len(b), 0x80), b...)
+}
+
+func encodeList(items ...[]byte) []byte {
+	payload := bytes.Join(items, nil)
+	return append(encodeLength(len(payload), 0xc0), payload...)
+}
+
+func encodeLength(n int, offset byte) []byte {
+	if n < 56 {
+		return []byte{offset + byte(n)}
+	}
+	size := new(big.Int).SetInt64(int64(n)).Bytes()
+	return append([]byte{offset + 55 + byte(len(size))}, size...)
+}
+
+func compact(nibbles []byte, leaf bool) []byte {
+	flag := byte(0)
+	if leaf {
+		flag = 2
+	}
+	if len(nibbles)%2 == 1 {
+		nibbles = append([]byte{flag + 1}, nibbles...)
+	} else {
+		nibbles = append([]byte{flag, 0}, nibbles...)
+	}
+	b := make([]byte, len(nibbles)/2)
+	for i := range b {
+		b[i] = nibbles[2*i]<<4 | nibbles[2*i+1]
+	}
+	return b
+}
+
+// testTrie builds a Merkle-Patricia trie in memory to prove its keys.
+type testTrie struct {
+	nodes map[utils.Hash][]byte
+	root  utils.Hash
+}
+
+type entry struct {
+	path  []byte
+	value []byte
+}
+
+func newTestTrie(values map[string][]byte) *testTrie {
+	t := &testTrie{nodes: map[utils.Hash][]byte{}}
+	var entries []entry
+	for key, value := range values {
+		entries = append(entries, entry{path: keyNibbles([]byte(key)), value: value})
+	}
+	root := t.build(entries)
+	t.root = utils.Keccak256Hash(root)
+	t.nodes[t.root] = root
+	return t
+}
+
+func (t *testTrie) build(entries []entry) []byte {
+	if len(entries) == 1 {
+		return encodeList(encodeString(compact(entries[0].path, true)), encodeString(entries[0].value))
+	}
+	prefix := entries[0].path
+	for _, e := range entries[1:] {
+		n := 0
+		for n < len(prefix) && n < len(e.path) && prefix[n] == e.path[n] {
+			n++
+		}
+		prefix = prefix[:n]
+	}
+	if len(prefix) > 0 {
+		stripped := make([]entry, len(entries))
+		for i, e := range entries {
+			stripped[i] = entry{path: e.path[len(prefix):], value: e.value}
+		}
+		return encodeList(encodeString(compact(prefix, false)), t.ref(t.build(stripped)))
+	}
+	children := make([][]entry, 16)
+	value := []byte{}
+	for _, e := range entries {
+		if len(e.path) == 0 {
+			value = e.value
+			continue
+		}
+		children[e.path[0]] = append(children[e.path[0]], entry{path: e.path[1:], value: e.value})
+	}
+	items := make([][]byte, 17)
+	for i, child := range children {
+		items[i] = encodeString(nil)
+		if len(child) > 0 {
+			items[i] = t.ref(t.build(child))
+		}
+	}
+	items[16] = encodeString(value)
+	return encodeList(items...)
+}
+
+// ref embeds nodes shorter than a hash and references the others by hash.
+func (t *testTrie) ref(node []byte) []byte {
+	if len(node) < utils.HashLength {
+		return node
+	}
+	hash := utils.Keccak256Hash(node)
+	t.nodes[hash] = node
+	return encodeString(hash[:])
+}
+
+// prove returns the hashed nodes on the path of key.
+func (t *testTrie) prove(key []byte) [][]byte {
+	path := keyNibbles(key)
+	node := t.nodes[t.root]
+	proof := [][]byte{node}
+	for {
+		items, _ := decodeList(node)
+		var child item
+		if len(items) == 17 {
+			if len(path) == 0 {
+				return proof
+			}
+			child, path = items[path[0]], path[1:]
+		} else {
+			prefix, leaf, _ := compactNibbles(items[0].data)
+			if leaf || !bytes.HasPrefix(path, prefix) {
+				return proof
+			}
+			child, path = items[1], path[len(prefix):]
+		}
+		switch {
+		case child.list:
+			node = child.raw
+		case len(child.data) == 0:
+			return proof
+		default:
+			node = t.nodes[utils.BytesToHash(child.data)]
+			proof = append(proof, node)
+		}
+	}
+}
+
+func TestVerifyProof(t *testing.T) {
+	values := map[string][]byte{"do": []byte("verb"), "dog": []byte("puppy"), "doge": []byte("coin"), "horse": []byte("stallion")}
+	trie := newTestTrie(values)
+	if want := utils.MustParseHash("0x5991bb8c6514148a29db676a14ac506cd2cd5775ace63c30a4fe457715e9ac84"); trie.root != want {
+		t.Fatalf("root got = %v, want %v", trie.root, want)
+	}
+	for key, want := range values {
+		got, err := VerifyProof(trie.root, []byte(key), trie.prove([]byte(key)))
+		if err != nil || !bytes.Equal(got, want) {
+			t.Errorf("VerifyProof(%v) got = %s, %v, want %s", key, got, err, want)
+		}
+	}
+	for _, key := range []string{"d", "dogs", "cat", "horses", ""} {
+		got, err := VerifyProof(trie.root, []byte(key), trie.prove([]byte(key)))
+		if err != nil || got != nil {
+			t.Errorf("VerifyProof(%v) got = %s, %v, want an absent key", key, got, err)
+		}
+	}
+	if got, err := VerifyProof(EmptyRoot, []byte("dog"), nil); err != nil || got != nil {
+		t.Errorf("VerifyProof() got = %s, %v for an empty trie", got, err)
+	}
+}
+
+// TestVerifyProof_Fixture verifies fixed proofs of the trie holding doe,
+// dog and dogglesworth, whose root is the one published in the Ethereum
+// wiki, without the encoder of testTrie. The cat leaf is embedded in its
+// branch, as nodes shorter than a hash are.
+func TestVerifyProof_Fixture(t *testing.T) {
+	data, err := os.ReadFile("testdata/dogs.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fixture struct {
+		Root   utils.Hash `json:"root"`
+		Proofs []struct {
+			Key   string   `json:"key"`
+			Value *string  `json:"value"`
+			Proof []string `json:"proof"`
+		} `json:"proofs"`
+	}
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		t.Fatal(err)
+	}
+	if want := utils.MustParseHash("0x8aad789dff2f538bca5d8ea56e8abe10f4c7ba3a5dea95fea4cd6e7c3a1168d3"); fixture.Root != want {
+		t.Fatalf("root got = %v, want %v", fixture.Root, want)
+	}
+	for _, p := range fixture.Proofs {
+		proof := make([][]byte, len(p.Proof))
+		for i, node := range p.Proof {
+			if proof[i], err = hex.DecodeString(strings.TrimPrefix(node, "0x")); err != nil {
+				t.Fatal(err)
+			}
+		}
+		got, err := VerifyProof(fixture.Root, []byte(p.Key), proof)
+		if p.Value == nil {
+			if err != nil || got != nil {
+				t.Errorf("VerifyProof(%v) got = %s, %v, want an absent key", p.Key, got, err)
+			}
+		} else if err != nil || string(got) != *p.Value {
+			t.Errorf("VerifyProof(%v) got = %s, %v, want %s", p.Key, got, err, *p.Value)
+		}
+	}
+}
+
+func TestVerifyProof_Invalid(t *testing.T) {
+	values := map[string][]byte{}
+	for i := 0; i < 64; i++ {
+		key := utils.Keccak256([]byte{byte(i)})
+		values[string(key)] = bytes.Repeat([]byte{byte(i + 1)}, i%40+1)
+	}
+	trie := newTestTrie(values)
+	key := utils.Keccak256([]byte{7})
+	proof := trie.prove(key)
+	if got, err := VerifyProof(trie.root, key, proof); err != nil || !bytes.Equal(got, values[string(key)]) {
+		t.Fatalf("VerifyProof() got = %x, %v", got, err)
+	}
+
+	last := append([]byte(nil), proof[len(proof)-1]...)
+	last[len(last)-1] ^= 1
+	tampered := append(append([][]byte(nil), proof[:len(proof)-1]...), last)
+	tests := []struct {
+		name  string
+		root  utils.Hash
+		proof [][]byte
+	}{
+		{name: "Tampered", root: trie.root, proof: tampered},
+		{name: "Truncated", root: trie.root, proof: proof[:len(proof)-1]},
+		{name: "Wrong Root", root: utils.Keccak256Hash([]byte("root")), proof: proof},
+		{name: "Empty Proof", root: trie.root},
+		{name: "Not RLP", root: utils.Keccak256Hash([]byte{0xc5, 1}), proof: [][]byte{{0xc5, 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := VerifyProof(tt.root, key, tt.proof); !errors.Is(err, ErrInvalidProof) {
+				t.Errorf("VerifyProof() got = %x, %v, want %v", got, err, ErrInvalidProof)
+			}
+		})
+	}
+}
+
+func TestVerifyAccount(t *testing.T) {
+	holder := utils.MustParseAddress("0x549c660ce2b988f588769d6ad87be801695b2be3")
+	usdc := utils.MustParseAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+	slot := utils.Slot(1)
+	storage := newTestTrie(map[string][]byte{
+		string(utils.Keccak256(slot[:])):               encodeString([]byte{0x2a}),
+		string(utils.Keccak256(utils.Slot(2).Bytes())): encodeString([]byte{1, 0}),
+	})
+	codeHash := utils.Keccak256Hash([]byte{0x60, 0x80})
+	balance, _ := new(big.Int).SetString("1500000000000000000", 10)
+	state := newTestTrie(map[string][]byte{
+		string(utils.Keccak256(holder[:])): encodeList(encodeString([]byte{7}), encodeString(balance.Bytes()), encodeString(EmptyRoot[:]), encodeString(EmptyCodeHash[:])),
+		string(utils.Keccak256(usdc[:])):   encodeList(encodeString(nil), encodeString(nil), encodeString(storage.root[:]), encodeString(codeHash[:])),
+	})
+
+	account, err := VerifyAccount(state.root, holder, state.prove(utils.Keccak256(holder[:])))
+	want := &Account{Nonce: 7, Balance: balance, StorageRoot: EmptyRoot, CodeHash: EmptyCodeHash}
+	if err != nil || !reflect.DeepEqual(account, want) {
+		t.Errorf("VerifyAccount() got = %+v, %v, want %+v", account, err, want)
+	}
+	account, err = VerifyAccount(state.root, usdc, state.prove(utils.Keccak256(usdc[:])))
+	if err != nil || account.StorageRoot != storage.root || account.CodeHash != codeHash || account.Balance.Sign() != 0 {
+		t.Fatalf("VerifyAccount() got = %+v, %v", account, err)
+	}
+	absent := utils.MustParseAddress("0x558FA75074cc7cF045C764aEd47D37776Ea697d2")
+	account, err = VerifyAccount(state.root, absent, state.prove(utils.Keccak256(absent[:])))
+	if err != nil || account.Nonce != 0 || account.Balance.Sign() != 0 || account.CodeHash != EmptyCodeHash || account.StorageRoot != EmptyRoot {
+		t.Errorf("VerifyAccount() got = %+v, %v for an absent account", account, err)
+	}
+
+	tests := []struct {
+		slot utils.Hash
+		want utils.Hash
+	}{
+		{slot: slot, want: utils.Slot(42)},
+		{slot: utils.Slot(2), want: utils.Slot(256)},
+		{slot: utils.Slot(3)},
+	}
+	for _, tt := range tests {
+		got, err := VerifyStorage(account.StorageRoot, tt.slot, nil)
+		if err != nil || !got.IsZero() {
+			t.Errorf("VerifyStorage() got = %v, %v for an empty storage", got, err)
+		}
+		got, err = VerifyStorage(storage.root, tt.slot, storage.prove(utils.Keccak256(tt.slot[:])))
+		if err != nil || got != tt.want {
+			t.Errorf("VerifyStorage(%v) got = %v, %v, want %v", tt.slot, got, err, tt.want)
+		}
+	}
+}
+
+func TestDecodeItem(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   []byte
+		wantErr bool
+	}{
+		{name: "Byte", input: []byte{0x7f}},
+		{name: "String", input: []byte{0x83, 'd', 'o', 'g'}},
+		{name: "Long String", input: append([]byte{0xb8, 56}, make([]byte, 56)...)},
+		{name: "List", input: []byte{0xc2, 0x01, 0x02}},
+		{name: "Empty", input: nil, wantErr: true},
+		{name: "Truncated", input: []byte{0x83, 'd', 'o'}, wantErr: true},
+		{name: "Non Canonical Byte", input: []byte{0x81, 0x01}, wantErr: true},
+		{name: "Non Canonical Length", input: append([]byte{0xb8, 3}, 'd', 'o', 'g'), wantErr: true},
+		{name: "Leading Zero Length", input: append([]byte{0xb9, 0, 56}, make([]byte, 56)...), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, rest, err := decodeItem(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeItem() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (!bytes.Equal(got.raw, tt.input) || len(rest) != 0) {
+				t.Errorf("decodeItem() got = %x, rest %x", got.raw, rest)
+			}
+		})
+	}
+}
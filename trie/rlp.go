@@ -0,0 +1,107 @@
+package trie
+
+import (
+	"errors"
+	"fmt"
+)
+
+// item is a decoded RLP item: the content of a string, or the payload of a
+// list, along with its full encoding.
+type item struct {
+	list bool
+	data []byte
+	raw  []byte
+}
+
+var errRLPTruncated = errors.New("rlp: value exceeds input")
+
+// decodeItem decodes the first RLP item of b and returns it with the bytes
+// following it.
+func decodeItem(b []byte) (item, []byte, error) {
+	if len(b) == 0 {
+		return item{}, nil, errRLPTruncated
+	}
+	prefix := b[0]
+	var list bool
+	var offset, size uint64
+	switch {
+	case prefix < 0x80:
+		offset, size = 0, 1
+	case prefix < 0xb8:
+		offset, size = 1, uint64(prefix-0x80)
+	case prefix < 0xc0:
+		n, err := decodeLength(b, uint64(prefix-0xb7))
+		if err != nil {
+			return item{}, nil, err
+		}
+		offset, size = 1+uint64(prefix-0xb7), n
+	case prefix < 0xf8:
+		list, offset, size = true, 1, uint64(prefix-0xc0)
+	default:
+		n, err := decodeLength(b, uint64(prefix-0xf7))
+		if err != nil {
+			return item{}, nil, err
+		}
+		list, offset, size = true, 1+uint64(prefix-0xf7), n
+	}
+	end := offset + size
+	if end < offset || end > uint64(len(b)) {
+		return item{}, nil, errRLPTruncated
+	}
+	if !list && size == 1 && offset == 1 && b[1] < 0x80 {
+		return item{}, nil, fmt.Errorf("rlp: non-canonical single byte %#x", b[1])
+	}
+	return item{list: list, data: b[offset:end], raw: b[:end]}, b[end:], nil
+}
+
+func decodeLength(b []byte, lengthSize uint64) (uint64, error) {
+	if uint64(len(b)) < 1+lengthSize {
+		return 0, errRLPTruncated
+	}
+	if lengthSize > 8 || b[1] == 0 {
+		return 0, errors.New("rlp: non-canonical length")
+	}
+	var n uint64
+	for _, c := range b[1 : 1+lengthSize] {
+		n = n<<8 | uint64(c)
+	}
+	if n < 56 {
+		return 0, errors.New("rlp: non-canonical length")
+	}
+	return n, nil
+}
+
+// decodeList decodes b, which must be exactly one RLP list, into its items.
+func decodeList(b []byte) ([]item, error) {
+	list, rest, err := decodeItem(b)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 {
+		return nil, errors.New("rlp: trailing bytes after list")
+	}
+	if !list.list {
+		return nil, errors.New("rlp: expected a list")
+	}
+	var items []item
+	for data := list.data; len(data) > 0; {
+		var it item
+		if it, data, err = decodeItem(data); err != nil {
+			return nil, err
+		}
+		items = append(items, it)
+	}
+	return items, nil
+}
+
+// decodeString decodes b, which must be exactly one RLP string, into its content.
+func decodeString(b []byte) ([]byte, error) {
+	it, rest, err := decodeItem(b)
+	if err != nil {
+		return nil, err
+	}
+	if it.list || len(rest) > 0 {
+		return nil, errors.New("rlp: expected a string")
+	}
+	return it.data, nil
+}
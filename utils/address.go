@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// AddressLength is the length in bytes of an Ethereum address.
+const AddressLength = 20
+
+// AddressMode selects how strictly hex addresses are validated when parsed.
+type AddressMode int
+
+const (
+	// StrictAddress accepts all-lowercase and all-uppercase addresses, and
+	// mixed-case addresses only when they carry a valid EIP-55 checksum.
+	StrictAddress AddressMode = iota
+	// LenientAddress accepts any well-formed hex address regardless of case.
+	LenientAddress
+)
+
+// Address is a 20 byte Ethereum account address.
+type Address [AddressLength]byte
+
+// ParseAddress parses a 0x prefixed hex address using StrictAddress validation.
+func ParseAddress(address string) (Address, error) {
+	return ParseAddressMode(address, StrictAddress)
+}
+
+// ParseAddressMode parses a 0x prefixed hex address using the given validation mode.
+func ParseAddressMode(address string, mode AddressMode) (Address, error) {
+	var a Address
+	if len(address) != 2+2*AddressLength || (address[:2] != "0x" && address[:2] != "0X") {
+		return a, fmt.Errorf("invalid address %v", address)
+	}
+	digits := address[2:]
+	if _, err := hex.Decode(a[:], []byte(digits)); err != nil {
+		return Address{}, fmt.Errorf("invalid address %v", address)
+	}
+	if mode == StrictAddress && isMixedCase(digits) && a.Hex() != "0x"+digits {
+		return Address{}, fmt.Errorf("invalid checksum for address %v", address)
+	}
+	return a, nil
+}
+
+// MustParseAddress is like ParseAddress but panics if the address is invalid.
+func MustParseAddress(address string) Address {
+	a, err := ParseAddress(address)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+// CheckAddress reports whether address is a valid hex address with a correct
+// checksum when written in mixed case.
+func CheckAddress(address string) bool {
+	_, err := ParseAddress(address)
+	return err == nil
+}
+
+// IsChecksumAddress reports whether address is written exactly in its EIP-55 checksummed form.
+func IsChecksumAddress(address string) bool {
+	a, err := ParseAddressMode(address, LenientAddress)
+	if err != nil {
+		return false
+	}
+	return a.Hex() == address
+}
+
+// ToChecksumAddress returns the EIP-55 checksummed form of address, which may be written in any case.
+func ToChecksumAddress(address string) (string, error) {
+	a, err := ParseAddressMode(address, LenientAddress)
+	if err != nil {
+		return "", err
+	}
+	return a.Hex(), nil
+}
+
+// Hex returns the EIP-55 checksummed hex representation of the address.
+func (a Address) Hex() string {
+	lower := hex.EncodeToString(a[:])
+	hash := Keccak256([]byte(lower))
+	result := []byte(lower)
+	for i, c := range result {
+		if c < 'a' {
+			continue
+		}
+		nibble := hash[i/2]
+		if i%2 == 0 {
+			nibble >>= 4
+		}
+		if nibble&0x0f >= 8 {
+			result[i] = c - 'a' + 'A'
+		}
+	}
+	return "0x" + string(result)
+}
+
+func (a Address) String() string {
+	return a.Hex()
+}
+
+// Bytes returns a copy of the address as a byte slice.
+func (a Address) Bytes() []byte {
+	return append([]byte(nil), a[:]...)
+}
+
+// IsZero reports whether a is the zero address.
+func (a Address) IsZero() bool {
+	return a == Address{}
+}
+
+// MarshalText encodes the address in its checksummed hex form.
+func (a Address) MarshalText() ([]byte, error) {
+	return []byte(a.Hex()), nil
+}
+
+// UnmarshalText decodes a hex address using StrictAddress validation.
+func (a *Address) UnmarshalText(text []byte) error {
+	parsed, err := ParseAddress(string(text))
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
+
+func isMixedCase(digits string) bool {
+	return strings.ToLower(digits) != digits && strings.ToUpper(digits) != digits
+}
@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseAddressMode(t *testing.T) {
+	type args struct {
+		address string
+		mode    AddressMode
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{name: "Checksummed", args: args{address: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"}, want: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"},
+		{name: "Lowercase", args: args{address: "0x549c660ce2b988f588769d6ad87be801695b2be3"}, want: "0x549c660ce2B988F588769d6AD87BE801695b2be3"},
+		{name: "Uppercase", args: args{address: "0x549C660CE2B988F588769D6AD87BE801695B2BE3"}, want: "0x549c660ce2B988F588769d6AD87BE801695b2be3"},
+		{name: "Bad Checksum", args: args{address: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB49"}, wantErr: true},
+		{name: "Bad Checksum Lenient", args: args{address: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB49", mode: LenientAddress}, want: "0xa0B86991C6218b36c1d19d4A2E9eB0CE3606eb49"},
+		{name: "Too Short", args: args{address: "0x549c660ce2b988f588769d6"}, wantErr: true},
+		{name: "Missing Prefix", args: args{address: "549c660ce2b988f588769d6ad87be801695b2be3aa"}, wantErr: true},
+		{name: "Not Hex", args: args{address: "0x549c660ce2b988f588769d6ad87be801695b2bzz"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAddressMode(tt.args.address, tt.args.mode)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseAddressMode() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got.Hex() != tt.want {
+				t.Errorf("ParseAddressMode() got = %v, want %v", got.Hex(), tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		address string
+		want    bool
+	}{
+		{name: "Valid", address: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", want: true},
+		{name: "Bad Checksum", address: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB47", want: false},
+		{name: "Wrong Length", address: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE360", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CheckAddress(tt.address); got != tt.want {
+				t.Errorf("CheckAddress() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToChecksumAddress(t *testing.T) {
+	got, err := ToChecksumAddress("0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48")
+	if err != nil {
+		t.Fatalf("ToChecksumAddress() error = %v", err)
+	}
+	if got != "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48" {
+		t.Errorf("ToChecksumAddress() = %v", got)
+	}
+	if !IsChecksumAddress(got) {
+		t.Errorf("IsChecksumAddress(%v) = false", got)
+	}
+	if IsChecksumAddress("0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48") {
+		t.Errorf("IsChecksumAddress() = true for lowercase address")
+	}
+}
+
+func TestAddress_JSON(t *testing.T) {
+	type holder struct {
+		Address Address `json:"address"`
+	}
+	in := holder{Address: MustParseAddress("0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48")}
+	js, err := json.Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if string(js) != `{"address":"0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"}` {
+		t.Errorf("Marshal() = %s", js)
+	}
+	var out holder
+	if err := json.Unmarshal(js, &out); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if out != in {
+		t.Errorf("Unmarshal() got = %v, want %v", out, in)
+	}
+	if err := json.Unmarshal([]byte(`{"address":"0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB47"}`), &out); err == nil {
+		t.Errorf("Unmarshal() accepted a bad checksum")
+	}
+}
@@ -3,16 +3,10 @@ package utils
 import (
 	"fmt"
 	"math/big"
-	"regexp"
 
 	"github.com/ybbus/jsonrpc/v3"
 )
 
-func CheckAddress(address string) bool {
-	re := regexp.MustCompile("^0x[0-9a-fA-F]{40}$")
-	return re.MatchString(address)
-}
-
 func GetString(response *jsonrpc.RPCResponse) (string, error) {
 	responseError := response.Error
 	if responseError == nil {
@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// HashLength is the length in bytes of a Keccak-256 hash.
+const HashLength = 32
+
+// Hash is a 32 byte Keccak-256 hash, as used for block and transaction hashes and log topics.
+type Hash [HashLength]byte
+
+// ParseHash parses a 0x prefixed, 64 digit hex string into a Hash.
+func ParseHash(hash string) (Hash, error) {
+	var h Hash
+	if len(hash) != 2+2*HashLength || (hash[:2] != "0x" && hash[:2] != "0X") {
+		return h, fmt.Errorf("invalid hash %v", hash)
+	}
+	if _, err := hex.Decode(h[:], []byte(hash[2:])); err != nil {
+		return h, fmt.Errorf("invalid hash %v", hash)
+	}
+	return h, nil
+}
+
+// MustParseHash is like ParseHash but panics if the hash is invalid.
+func MustParseHash(hash string) Hash {
+	h, err := ParseHash(hash)
+	if err != nil {
+		panic(err)
+	}
+	return h
+}
+
+// BytesToHash converts b to a Hash. If b is longer than 32 bytes only the
+// last 32 are kept, if it is shorter it is left padded with zeros.
+func BytesToHash(b []byte) Hash {
+	var h Hash
+	if len(b) > HashLength {
+		b = b[len(b)-HashLength:]
+	}
+	copy(h[HashLength-len(b):], b)
+	return h
+}
+
+// Hex returns the lowercase 0x prefixed hex representation of the hash.
+func (h Hash) Hex() string {
+	return "0x" + hex.EncodeToString(h[:])
+}
+
+func (h Hash) String() string {
+	return h.Hex()
+}
+
+// Bytes returns a copy of the hash as a byte slice.
+func (h Hash) Bytes() []byte {
+	return append([]byte(nil), h[:]...)
+}
+
+// IsZero reports whether h is the zero hash.
+func (h Hash) IsZero() bool {
+	return h == Hash{}
+}
+
+// MarshalText encodes the hash as 0x prefixed hex.
+func (h Hash) MarshalText() ([]byte, error) {
+	return []byte(h.Hex()), nil
+}
+
+// UnmarshalText decodes a 0x prefixed hex hash.
+func (h *Hash) UnmarshalText(text []byte) error {
+	parsed, err := ParseHash(string(text))
+	if err != nil {
+		return err
+	}
+	*h = parsed
+	return nil
+}
+
+// EventTopic returns the log topic of an event signature such as
+// "Transfer(address,address,uint256)", i.e. the Keccak-256 hash of the
+// canonical signature.
+func EventTopic(signature string) Hash {
+	return Keccak256Hash([]byte(canonicalSignature(signature)))
+}
+
+// Selector returns the 4 byte function selector of a function signature such as "balanceOf(address)".
+func Selector(signature string) [4]byte {
+	var selector [4]byte
+	copy(selector[:], Keccak256([]byte(canonicalSignature(signature))))
+	return selector
+}
+
+// SelectorHex returns the function selector of signature as 0x prefixed hex, ready to be used as call data.
+func SelectorHex(signature string) string {
+	selector := Selector(signature)
+	return "0x" + hex.EncodeToString(selector[:])
+}
+
+// canonicalSignature strips the whitespace that is commonly added to signatures but is not part of the hashed form.
+func canonicalSignature(signature string) string {
+	return strings.Join(strings.Fields(signature), "")
+}
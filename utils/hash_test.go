@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/massigerardi/alchemy-api/mocks"
+)
+
+func TestKeccak256(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []byte
+		want  string
+	}{
+		{name: "Empty", input: []byte(""), want: "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470"},
+		{name: "Abc", input: []byte("abc"), want: "4e03657aea45a94fc7d47ba826c8d667c0d1e6e33a64a036ec44f58fa12d6c45"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hex.EncodeToString(Keccak256(tt.input)); got != tt.want {
+				t.Errorf("Keccak256() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeccak256_Concatenation(t *testing.T) {
+	data := []byte(strings.Repeat("a", 300))
+	want := hex.EncodeToString(Keccak256(data))
+	for _, split := range []int{0, 1, 135, 136, 137, 272, 300} {
+		if got := hex.EncodeToString(Keccak256(data[:split], data[split:])); got != want {
+			t.Errorf("Keccak256() split at %v = %v, want %v", split, got, want)
+		}
+	}
+}
+
+func TestEventTopic(t *testing.T) {
+	var logs []struct {
+		Topics []string `json:"topics"`
+	}
+	if err := json.Unmarshal([]byte(mocks.JS), &logs); err != nil {
+		t.Fatal(err)
+	}
+	want := logs[0].Topics[0]
+	if got := EventTopic("Transfer(address,address,uint256)").Hex(); got != want {
+		t.Errorf("EventTopic() = %v, want %v", got, want)
+	}
+	if got := EventTopic("Transfer(address, address, uint256)").Hex(); got != want {
+		t.Errorf("EventTopic() with spaces = %v, want %v", got, want)
+	}
+}
+
+func TestSelector(t *testing.T) {
+	tests := []struct {
+		signature string
+		want      string
+	}{
+		{signature: "balanceOf(address)", want: "0x70a08231"},
+		{signature: "transfer(address,uint256)", want: "0xa9059cbb"},
+		{signature: "totalSupply()", want: "0x18160ddd"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.signature, func(t *testing.T) {
+			if got := SelectorHex(tt.signature); got != tt.want {
+				t.Errorf("SelectorHex() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHash_JSON(t *testing.T) {
+	in := MustParseHash("0x8243343df08b9751f5ca0c5f8c9c0460d8a9b6351066fae0acbd4d3e776de8bb")
+	js, err := json.Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if string(js) != `"0x8243343df08b9751f5ca0c5f8c9c0460d8a9b6351066fae0acbd4d3e776de8bb"` {
+		t.Errorf("Marshal() = %s", js)
+	}
+	var out Hash
+	if err := json.Unmarshal(js, &out); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if out != in {
+		t.Errorf("Unmarshal() got = %v, want %v", out, in)
+	}
+	if err := json.Unmarshal([]byte(`"0x1234"`), &out); err == nil {
+		t.Errorf("Unmarshal() accepted a short hash")
+	}
+}
+
+func TestBytesToHash(t *testing.T) {
+	got := BytesToHash([]byte{0x01, 0x02})
+	if got.Hex() != "0x"+strings.Repeat("0", 60)+"0102" {
+		t.Errorf("BytesToHash() = %v", got)
+	}
+}
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// DecodeQuantity parses a 0x prefixed hex quantity such as a block number.
+func DecodeQuantity(quantity string) (uint64, error) {
+	if !strings.HasPrefix(quantity, "0x") || len(quantity) == 2 {
+		return 0, fmt.Errorf("invalid quantity %v", quantity)
+	}
+	n, err := strconv.ParseUint(quantity[2:], 16, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid quantity %v", quantity)
+	}
+	return n, nil
+}
+
+// DecodeBigQuantity parses a 0x prefixed hex quantity such as a balance,
+// which may not fit in a uint64.
+func DecodeBigQuantity(quantity string) (*big.Int, error) {
+	if !strings.HasPrefix(quantity, "0x") || len(quantity) == 2 {
+		return nil, fmt.Errorf("invalid quantity %v", quantity)
+	}
+	n, ok := new(big.Int).SetString(quantity[2:], 16)
+	if !ok || n.Sign() < 0 {
+		return nil, fmt.Errorf("invalid quantity %v", quantity)
+	}
+	return n, nil
+}
+
+// EncodeQuantity formats n as a 0x prefixed hex quantity.
+func EncodeQuantity(n uint64) string {
+	return "0x" + strconv.FormatUint(n, 16)
+}
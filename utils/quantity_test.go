@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestDecodeQuantity(t *testing.T) {
+	tests := []struct {
+		quantity string
+		want     uint64
+		wantErr  bool
+	}{
+		{quantity: "0x0", want: 0},
+		{quantity: "0x429d3b", want: 4365627},
+		{quantity: "0x", wantErr: true},
+		{quantity: "429d3b", wantErr: true},
+		{quantity: "0xzz", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.quantity, func(t *testing.T) {
+			got, err := DecodeQuantity(tt.quantity)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DecodeQuantity() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("DecodeQuantity() = %v, want %v", got, tt.want)
+			}
+			if !tt.wantErr && EncodeQuantity(got) != tt.quantity {
+				t.Errorf("EncodeQuantity() = %v, want %v", EncodeQuantity(got), tt.quantity)
+			}
+		})
+	}
+}
+
+func TestDecodeBigQuantity(t *testing.T) {
+	balance, _ := new(big.Int).SetString("1000000000000000000000000", 10)
+	tests := []struct {
+		quantity string
+		want     *big.Int
+		wantErr  bool
+	}{
+		{quantity: "0x0", want: new(big.Int)},
+		{quantity: "0xd3c21bcecceda1000000", want: balance},
+		{quantity: "0x", wantErr: true},
+		{quantity: "0x-1", wantErr: true},
+		{quantity: "d3c21bcecceda1000000", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.quantity, func(t *testing.T) {
+			got, err := DecodeBigQuantity(tt.quantity)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DecodeBigQuantity() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got.Cmp(tt.want) != 0 {
+				t.Errorf("DecodeBigQuantity() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package utils
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Well-known proxy slots. The EIP-1967 slots are the Keccak-256 hash of
+// their label minus one, the EIP-1822 slot is the hash of "PROXIABLE".
+var (
+	EIP1967ImplementationSlot = eip1967Slot("eip1967.proxy.implementation")
+	EIP1967AdminSlot          = eip1967Slot("eip1967.proxy.admin")
+	EIP1967BeaconSlot         = eip1967Slot("eip1967.proxy.beacon")
+	EIP1822ProxiableSlot      = Keccak256Hash([]byte("PROXIABLE"))
+)
+
+func eip1967Slot(label string) Hash {
+	return SlotAdd(Keccak256Hash([]byte(label)), -1)
+}
+
+// Slot returns the storage slot n, e.g. the slot of the nth state variable
+// of a contract without packing.
+func Slot(n uint64) Hash {
+	return BigToHash(new(big.Int).SetUint64(n))
+}
+
+// SlotAdd returns slot + n modulo 2^256, e.g. the slot of a struct member
+// or of an element of a fixed size array.
+func SlotAdd(slot Hash, n int64) Hash {
+	sum := new(big.Int).Add(new(big.Int).SetBytes(slot[:]), big.NewInt(n))
+	return BigToHash(sum.Mod(sum, slotModulus))
+}
+
+var slotModulus = new(big.Int).Lsh(big.NewInt(1), 256)
+
+// BigToHash returns n as a 32 byte big endian word, n being in [0, 2^256).
+func BigToHash(n *big.Int) Hash {
+	var h Hash
+	n.FillBytes(h[:])
+	return h
+}
+
+// MappingSlot returns the slot of the value of a mapping declared at slot
+// for keys, several keys addressing nested mappings from the outermost.
+// Keys are encoded with AddressKey, UintKey, Bytes32Key or StringKey.
+func MappingSlot(slot Hash, keys ...[]byte) Hash {
+	for _, key := range keys {
+		slot = Keccak256Hash(key, slot[:])
+	}
+	return slot
+}
+
+// AddressKey encodes an address mapping key.
+func AddressKey(address Address) []byte {
+	return BytesToHash(address[:]).Bytes()
+}
+
+// UintKey encodes an unsigned integer mapping key.
+func UintKey(n *big.Int) []byte {
+	return BigToHash(n).Bytes()
+}
+
+// Bytes32Key encodes a bytes32 mapping key.
+func Bytes32Key(key Hash) []byte {
+	return key.Bytes()
+}
+
+// StringKey encodes a string or bytes mapping key, which unlike value type
+// keys is hashed without padding.
+func StringKey(key string) []byte {
+	return []byte(key)
+}
+
+// DataSlot returns the first slot of the elements of a dynamic array, or of
+// the data of a long string or bytes, declared at slot.
+func DataSlot(slot Hash) Hash {
+	return Keccak256Hash(slot[:])
+}
+
+// ArraySlot returns the slot and the offset in it of element index of a
+// dynamic array declared at slot. elementSize is the size of an element in
+// bytes: elements of up to 16 bytes are packed several per slot, larger
+// ones such as structs take a whole number of slots.
+func ArraySlot(slot Hash, index uint64, elementSize int) (Hash, int) {
+	if elementSize <= 0 {
+		panic(fmt.Sprintf("invalid array element size %v", elementSize))
+	}
+	data := DataSlot(slot)
+	if elementSize > HashLength {
+		slots := uint64((elementSize + HashLength - 1) / HashLength)
+		return slotAddUint(data, index*slots), 0
+	}
+	perSlot := uint64(HashLength / elementSize)
+	return slotAddUint(data, index/perSlot), int(index%perSlot) * elementSize
+}
+
+func slotAddUint(slot Hash, n uint64) Hash {
+	sum := new(big.Int).Add(new(big.Int).SetBytes(slot[:]), new(big.Int).SetUint64(n))
+	return BigToHash(sum.Mod(sum, slotModulus))
+}
+
+// Packed values are located by their offset in bytes from the low order end
+// of the word and their size, as in the storage layout output of solc.
+
+// UnpackUint returns the unsigned integer of size bytes at offset in word.
+func UnpackUint(word Hash, offset int, size int) *big.Int {
+	return new(big.Int).SetBytes(packed(word, offset, size))
+}
+
+// UnpackInt returns the two's complement signed integer of size bytes at offset in word.
+func UnpackInt(word Hash, offset int, size int) *big.Int {
+	n := UnpackUint(word, offset, size)
+	if n.Bit(8*size-1) == 1 {
+		n.Sub(n, new(big.Int).Lsh(big.NewInt(1), uint(8*size)))
+	}
+	return n
+}
+
+// UnpackAddress returns the address at offset in word.
+func UnpackAddress(word Hash, offset int) Address {
+	var address Address
+	copy(address[:], packed(word, offset, AddressLength))
+	return address
+}
+
+// UnpackBool returns the bool at offset in word.
+func UnpackBool(word Hash, offset int) bool {
+	return packed(word, offset, 1)[0] != 0
+}
+
+func packed(word Hash, offset int, size int) []byte {
+	if offset < 0 || size <= 0 || offset+size > HashLength {
+		panic(fmt.Sprintf("invalid packed value of %v bytes at offset %v", size, offset))
+	}
+	end := HashLength - offset
+	return word[end-size : end]
+}
+
+// DecodeStringWord decodes the word of a string or bytes state variable. Values
+// of up to 31 bytes are stored in the word itself and returned with true.
+// Otherwise DecodeStringWord returns the length of the value, whose data is
+// stored from DataSlot of the variable, and false; the length is zero if it
+// does not fit in an uint64.
+func DecodeStringWord(word Hash) ([]byte, uint64, bool) {
+	if word[HashLength-1]&1 == 0 {
+		length := int(word[HashLength-1] / 2)
+		if length < HashLength {
+			return append([]byte(nil), word[:length]...), uint64(length), true
+		}
+	}
+	length := new(big.Int).SetBytes(word[:])
+	length.Rsh(length, 1)
+	if !length.IsUint64() {
+		return nil, 0, false
+	}
+	return nil, length.Uint64(), false
+}
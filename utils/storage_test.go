@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+)
+
+func TestWellKnownSlots(t *testing.T) {
+	tests := []struct {
+		name string
+		slot Hash
+		want string
+	}{
+		{name: "EIP-1967 Implementation", slot: EIP1967ImplementationSlot, want: "0x360894a13ba1a3210667c828492db98dca3e2076cc3735a920a3ca505d382bbc"},
+		{name: "EIP-1967 Admin", slot: EIP1967AdminSlot, want: "0xb53127684a568b3173ae13b9f8a6016e243e63b6e8ee1178d6a717850b5d6103"},
+		{name: "EIP-1967 Beacon", slot: EIP1967BeaconSlot, want: "0xa3f0ad74e5423aebfd80d3ef4346578335a9a72aeaee59ff6cb3582b35133d50"},
+		{name: "EIP-1822 Proxiable", slot: EIP1822ProxiableSlot, want: "0xc5f16f0fcc639fa48a6947836d9850f504798523bf8c9a3a87d5876cf622bcf7"},
+		{name: "Data Slot 0", slot: DataSlot(Slot(0)), want: "0x290decd9548b62a8d60345a988386fc84ba6bc95484008f6362f93160ef3e563"},
+		{name: "Data Slot 1", slot: DataSlot(Slot(1)), want: "0xb10e2d527612073b26eecdfd717e6a320cf44b4afac2b0732d9fcbe2b7fa0cf6"},
+		{name: "Wrap Around", slot: SlotAdd(Slot(0), -1), want: "0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.slot.Hex(); got != tt.want {
+				t.Errorf("slot got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMappingSlot(t *testing.T) {
+	holder := MustParseAddress("0x549c660ce2b988f588769d6ad87be801695b2be3")
+	spender := MustParseAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+	padded := func(b []byte) []byte { return append(make([]byte, 32-len(b)), b...) }
+
+	balance := Keccak256Hash(padded(holder[:]), padded([]byte{9}))
+	if got := MappingSlot(Slot(9), AddressKey(holder)); got != balance {
+		t.Errorf("MappingSlot() got = %v, want %v", got, balance)
+	}
+	allowance := Keccak256Hash(padded(spender[:]), Keccak256Hash(padded(holder[:]), padded([]byte{10})).Bytes())
+	if got := MappingSlot(Slot(10), AddressKey(holder), AddressKey(spender)); got != allowance {
+		t.Errorf("MappingSlot() got = %v, want %v for a nested mapping", got, allowance)
+	}
+	if got, want := MappingSlot(Slot(1), StringKey("abc")), Keccak256Hash([]byte("abc"), padded([]byte{1})); got != want {
+		t.Errorf("MappingSlot() got = %v, want %v for a string key", got, want)
+	}
+	if got, want := MappingSlot(Slot(2), UintKey(big.NewInt(258))), Keccak256Hash(padded([]byte{1, 2}), padded([]byte{2})); got != want {
+		t.Errorf("MappingSlot() got = %v, want %v for a uint key", got, want)
+	}
+	if got, want := MappingSlot(Slot(3), Bytes32Key(balance)), Keccak256Hash(balance[:], padded([]byte{3})); got != want {
+		t.Errorf("MappingSlot() got = %v, want %v for a bytes32 key", got, want)
+	}
+}
+
+func TestArraySlot(t *testing.T) {
+	data := DataSlot(Slot(2))
+	tests := []struct {
+		name        string
+		index       uint64
+		elementSize int
+		want        Hash
+		wantOffset  int
+	}{
+		{name: "uint256", index: 3, elementSize: 32, want: SlotAdd(data, 3)},
+		{name: "uint64 First", index: 4, elementSize: 8, want: SlotAdd(data, 1)},
+		{name: "uint64 Packed", index: 5, elementSize: 8, want: SlotAdd(data, 1), wantOffset: 8},
+		{name: "address", index: 3, elementSize: 20, want: SlotAdd(data, 3)},
+		{name: "uint96", index: 1, elementSize: 12, want: data, wantOffset: 12},
+		{name: "Struct", index: 3, elementSize: 64, want: SlotAdd(data, 6)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, offset := ArraySlot(Slot(2), tt.index, tt.elementSize)
+			if got != tt.want || offset != tt.wantOffset {
+				t.Errorf("ArraySlot() got = %v, %v, want %v, %v", got, offset, tt.want, tt.wantOffset)
+			}
+		})
+	}
+}
+
+func TestUnpack(t *testing.T) {
+	// struct { address owner; bool paused; uint64 nonce; int8 delta } packed in one slot
+	owner := MustParseAddress("0x549c660ce2b988f588769d6ad87be801695b2be3")
+	var word Hash
+	word[2] = 0xff
+	copy(word[3:11], []byte{0, 0, 0, 0, 0, 0, 1, 2})
+	word[11] = 1
+	copy(word[12:], owner[:])
+
+	if got := UnpackAddress(word, 0); got != owner {
+		t.Errorf("UnpackAddress() got = %v, want %v", got, owner)
+	}
+	if !UnpackBool(word, 20) {
+		t.Errorf("UnpackBool() got = false, want true")
+	}
+	if got := UnpackUint(word, 21, 8); got.Int64() != 258 {
+		t.Errorf("UnpackUint() got = %v, want 258", got)
+	}
+	if got := UnpackInt(word, 29, 1); got.Int64() != -1 {
+		t.Errorf("UnpackInt() got = %v, want -1", got)
+	}
+	if got := UnpackInt(word, 21, 8); got.Int64() != 258 {
+		t.Errorf("UnpackInt() got = %v, want 258", got)
+	}
+}
+
+func TestDecodeStringWord(t *testing.T) {
+	var short Hash
+	copy(short[:], "USD Coin")
+	short[31] = 16
+	value, length, ok := DecodeStringWord(short)
+	if !ok || length != 8 || !bytes.Equal(value, []byte("USD Coin")) {
+		t.Errorf("DecodeStringWord() got = %q, %v, %v, want USD Coin", value, length, ok)
+	}
+
+	value, length, ok = DecodeStringWord(Slot(2*40 + 1))
+	if ok || length != 40 || value != nil {
+		t.Errorf("DecodeStringWord() got = %q, %v, %v, want a long value of 40 bytes", value, length, ok)
+	}
+}
@@ -0,0 +1,180 @@
+package utils
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Decimals of the common ether units, wei being the base unit amounts are
+// expressed in. Tokens declare their own decimals, e.g. 6 for USDC.
+const (
+	WeiDecimals   = 0
+	GweiDecimals  = 9
+	EtherDecimals = 18
+)
+
+// Rounding selects how amounts that do not fit the requested precision are
+// rounded.
+type Rounding int
+
+const (
+	// RoundDown truncates toward zero.
+	RoundDown Rounding = iota
+	// RoundUp rounds away from zero.
+	RoundUp
+	// RoundHalfUp rounds to the nearest value, ties away from zero.
+	RoundHalfUp
+	// RoundHalfEven rounds to the nearest value, ties to the even neighbour.
+	RoundHalfEven
+	// RoundFloor rounds toward negative infinity.
+	RoundFloor
+	// RoundCeiling rounds toward positive infinity.
+	RoundCeiling
+)
+
+// Pow10 returns 10^n, the number of base units in a unit with n decimals.
+func Pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// ParseUnits parses a decimal string such as "1.5" into base units of a unit
+// with the given decimals, e.g. ParseUnits("1.5", EtherDecimals) is 1.5e18
+// wei. The conversion is exact: more significant fraction digits than
+// decimals is an error.
+func ParseUnits(s string, decimals int) (*big.Int, error) {
+	text := strings.TrimSpace(s)
+	negative := strings.HasPrefix(text, "-")
+	if negative || strings.HasPrefix(text, "+") {
+		text = text[1:]
+	}
+	whole, fraction, _ := strings.Cut(text, ".")
+	if whole == "" && fraction == "" || !isDigits(whole) || !isDigits(fraction) {
+		return nil, fmt.Errorf("invalid amount %q", s)
+	}
+	trimmed := strings.TrimRight(fraction, "0")
+	if len(trimmed) > decimals {
+		return nil, fmt.Errorf("amount %q has more than %v decimals", s, decimals)
+	}
+	amount, _ := new(big.Int).SetString("0"+whole+trimmed+strings.Repeat("0", decimals-len(trimmed)), 10)
+	if negative {
+		amount.Neg(amount)
+	}
+	return amount, nil
+}
+
+// ParseEther parses an amount of ether into wei.
+func ParseEther(s string) (*big.Int, error) {
+	return ParseUnits(s, EtherDecimals)
+}
+
+// ParseGwei parses an amount of gwei into wei.
+func ParseGwei(s string) (*big.Int, error) {
+	return ParseUnits(s, GweiDecimals)
+}
+
+// ToUnits returns amount base units as an exact number of units with the
+// given decimals.
+func ToUnits(amount *big.Int, decimals int) *big.Rat {
+	return new(big.Rat).SetFrac(amount, Pow10(decimals))
+}
+
+// FromUnits converts a number of units with the given decimals to base units,
+// rounding the fraction of a base unit.
+func FromUnits(units *big.Rat, decimals int, rounding Rounding) *big.Int {
+	n := new(big.Int).Mul(units.Num(), Pow10(decimals))
+	return quo(n, units.Denom(), rounding)
+}
+
+// ConvertUnits rescales amount from a unit with from decimals to one with to
+// decimals, rounding when precision is lost, e.g. wei to gwei or between
+// tokens of different decimals.
+func ConvertUnits(amount *big.Int, from int, to int, rounding Rounding) *big.Int {
+	if to >= from {
+		return new(big.Int).Mul(amount, Pow10(to-from))
+	}
+	return quo(amount, Pow10(from-to), rounding)
+}
+
+// FormatUnits formats amount base units as an exact decimal number of units
+// with the given decimals, without trailing zeros.
+func FormatUnits(amount *big.Int, decimals int) string {
+	return trimZeros(RoundUnits(amount, decimals, decimals, RoundDown))
+}
+
+// RoundUnits formats amount base units as a decimal number of units with
+// the given decimals, rounded to exactly precision fraction digits.
+func RoundUnits(amount *big.Int, decimals int, precision int, rounding Rounding) string {
+	if precision < 0 {
+		precision = 0
+	}
+	scaled := ConvertUnits(amount, decimals, precision, rounding)
+	digits := new(big.Int).Abs(scaled).String()
+	if len(digits) <= precision {
+		digits = strings.Repeat("0", precision-len(digits)+1) + digits
+	}
+	sign := ""
+	if scaled.Sign() < 0 {
+		sign = "-"
+	}
+	if precision == 0 {
+		return sign + digits
+	}
+	point := len(digits) - precision
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+// FormatEther formats wei as an exact amount of ether.
+func FormatEther(wei *big.Int) string {
+	return FormatUnits(wei, EtherDecimals)
+}
+
+// FormatGwei formats wei as an exact amount of gwei.
+func FormatGwei(wei *big.Int) string {
+	return FormatUnits(wei, GweiDecimals)
+}
+
+// quo returns n/d rounded to an integer, d being positive.
+func quo(n, d *big.Int, rounding Rounding) *big.Int {
+	q, r := new(big.Int).QuoRem(n, d, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+	away := false
+	switch rounding {
+	case RoundUp:
+		away = true
+	case RoundFloor:
+		away = n.Sign() < 0
+	case RoundCeiling:
+		away = n.Sign() > 0
+	case RoundHalfUp, RoundHalfEven:
+		half := new(big.Int).Abs(r)
+		switch half.Lsh(half, 1).Cmp(d) {
+		case 1:
+			away = true
+		case 0:
+			away = rounding == RoundHalfUp || q.Bit(0) == 1
+		}
+	}
+	if away {
+		q.Add(q, big.NewInt(int64(n.Sign())))
+	}
+	return q
+}
+
+func trimZeros(s string) string {
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return s
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,137 @@
+package utils
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestParseUnits(t *testing.T) {
+	tests := []struct {
+		s        string
+		decimals int
+		want     string
+		wantErr  bool
+	}{
+		{s: "1.5", decimals: EtherDecimals, want: "1500000000000000000"},
+		{s: "0.000000000000000001", decimals: EtherDecimals, want: "1"},
+		{s: "-2", decimals: GweiDecimals, want: "-2000000000"},
+		{s: "+.25", decimals: 6, want: "250000"},
+		{s: "3.", decimals: 6, want: "3000000"},
+		{s: "1.2300", decimals: 2, want: "123"},
+		{s: "42", decimals: WeiDecimals, want: "42"},
+		{s: "1.234", decimals: 2, wantErr: true},
+		{s: "0.5", decimals: WeiDecimals, wantErr: true},
+		{s: "1e18", decimals: EtherDecimals, wantErr: true},
+		{s: "1,5", decimals: EtherDecimals, wantErr: true},
+		{s: ".", decimals: EtherDecimals, wantErr: true},
+		{s: "", decimals: EtherDecimals, wantErr: true},
+		{s: "--1", decimals: EtherDecimals, wantErr: true},
+		{s: "-+1", decimals: EtherDecimals, wantErr: true},
+		{s: "+-1", decimals: EtherDecimals, wantErr: true},
+		{s: "++1", decimals: EtherDecimals, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := ParseUnits(tt.s, tt.decimals)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseUnits() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.String() != tt.want {
+				t.Errorf("ParseUnits() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatUnits(t *testing.T) {
+	tests := []struct {
+		amount   string
+		decimals int
+		want     string
+	}{
+		{amount: "1500000000000000000", decimals: EtherDecimals, want: "1.5"},
+		{amount: "1", decimals: EtherDecimals, want: "0.000000000000000001"},
+		{amount: "-20000000000", decimals: GweiDecimals, want: "-20"},
+		{amount: "0", decimals: EtherDecimals, want: "0"},
+		{amount: "123456789", decimals: 6, want: "123.456789"},
+		{amount: "42", decimals: WeiDecimals, want: "42"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.amount, func(t *testing.T) {
+			amount, _ := new(big.Int).SetString(tt.amount, 10)
+			if got := FormatUnits(amount, tt.decimals); got != tt.want {
+				t.Errorf("FormatUnits() got = %v, want %v", got, tt.want)
+			}
+			parsed, err := ParseUnits(tt.want, tt.decimals)
+			if err != nil || parsed.Cmp(amount) != 0 {
+				t.Errorf("ParseUnits() got = %v, %v, want %v", parsed, err, amount)
+			}
+		})
+	}
+}
+
+func TestRoundUnits(t *testing.T) {
+	tests := []struct {
+		name      string
+		amount    int64
+		precision int
+		rounding  Rounding
+		want      string
+	}{
+		{name: "Down", amount: 1_256, precision: 2, rounding: RoundDown, want: "1.25"},
+		{name: "Down Negative", amount: -1_256, precision: 2, rounding: RoundDown, want: "-1.25"},
+		{name: "Up", amount: 1_251, precision: 2, rounding: RoundUp, want: "1.26"},
+		{name: "Up Negative", amount: -1_251, precision: 2, rounding: RoundUp, want: "-1.26"},
+		{name: "Half Up Tie", amount: 1_255, precision: 2, rounding: RoundHalfUp, want: "1.26"},
+		{name: "Half Up Below", amount: 1_254, precision: 2, rounding: RoundHalfUp, want: "1.25"},
+		{name: "Half Up Negative Tie", amount: -1_255, precision: 2, rounding: RoundHalfUp, want: "-1.26"},
+		{name: "Half Even Tie Down", amount: 1_245, precision: 2, rounding: RoundHalfEven, want: "1.24"},
+		{name: "Half Even Tie Up", amount: 1_255, precision: 2, rounding: RoundHalfEven, want: "1.26"},
+		{name: "Half Even Above", amount: 1_246, precision: 2, rounding: RoundHalfEven, want: "1.25"},
+		{name: "Floor Negative", amount: -1_251, precision: 2, rounding: RoundFloor, want: "-1.26"},
+		{name: "Floor Positive", amount: 1_259, precision: 2, rounding: RoundFloor, want: "1.25"},
+		{name: "Ceiling Negative", amount: -1_259, precision: 2, rounding: RoundCeiling, want: "-1.25"},
+		{name: "Ceiling Positive", amount: 1_251, precision: 2, rounding: RoundCeiling, want: "1.26"},
+		{name: "Padding", amount: 5, precision: 2, rounding: RoundDown, want: "0.00"},
+		{name: "Extra Precision", amount: 5, precision: 5, rounding: RoundDown, want: "0.00500"},
+		{name: "Whole", amount: 1_500, precision: 0, rounding: RoundHalfEven, want: "2"},
+		{name: "Negative Small", amount: -5, precision: 2, rounding: RoundUp, want: "-0.01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RoundUnits(big.NewInt(tt.amount), 3, tt.precision, tt.rounding); got != tt.want {
+				t.Errorf("RoundUnits() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertUnits(t *testing.T) {
+	wei, _ := ParseEther("1.5")
+	if got := ConvertUnits(wei, EtherDecimals, GweiDecimals, RoundDown); got.String() != "1500000000" {
+		t.Errorf("ConvertUnits() got = %v, want 1500000000", got)
+	}
+	if got := ConvertUnits(big.NewInt(1_500_000), 6, EtherDecimals, RoundDown); got.String() != "1500000000000000000" {
+		t.Errorf("ConvertUnits() got = %v, want 1500000000000000000", got)
+	}
+	if got := ConvertUnits(big.NewInt(1_999_999_999), GweiDecimals, 0, RoundHalfUp); got.Int64() != 2 {
+		t.Errorf("ConvertUnits() got = %v, want 2", got)
+	}
+
+	units := ToUnits(wei, EtherDecimals)
+	if units.Cmp(big.NewRat(3, 2)) != 0 {
+		t.Errorf("ToUnits() got = %v, want 3/2", units)
+	}
+	if got := FromUnits(big.NewRat(1, 3), 6, RoundHalfUp); got.Int64() != 333_333 {
+		t.Errorf("FromUnits() got = %v, want 333333", got)
+	}
+	if got := FromUnits(big.NewRat(-2, 3), 6, RoundHalfUp); got.Int64() != -666_667 {
+		t.Errorf("FromUnits() got = %v, want -666667", got)
+	}
+	if got := FormatGwei(big.NewInt(20_000_000_001)); got != "20.000000001" {
+		t.Errorf("FormatGwei() got = %v, want 20.000000001", got)
+	}
+	if got, err := ParseGwei("20.5"); err != nil || FormatEther(got) != "0.0000000205" {
+		t.Errorf("ParseGwei() got = %v, %v", got, err)
+	}
+}